@@ -3,6 +3,7 @@ package apis
 import (
 	appsv3 "github.com/datasance/iofog-operator/v3/apis/apps/v3"
 	cpv3 "github.com/datasance/iofog-operator/v3/apis/controlplanes/v3"
+	cpv4 "github.com/datasance/iofog-operator/v3/apis/controlplanes/v4"
 	extsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -10,14 +11,18 @@ import (
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 )
 
+// NewControlPlaneCustomResource returns the ControlPlane CRD. v3 is the storage version,
+// v4 is not served because the API server can only convert it through the operator's conversion webhook.
+// Only the kustomize install in config/default serves v4, when its webhooks are enabled.
 func NewControlPlaneCustomResource() *extsv1.CustomResourceDefinition {
-	apiVersions := []string{"v3"}
+	apiVersions := []string{"v3", "v4"}
 	versions := make([]extsv1.CustomResourceDefinitionVersion, len(apiVersions))
 	preserveUnknownFields := true
 
 	for i, version := range apiVersions {
 		versions[i].Name = version
-		versions[i].Served = true
+		// Versions other than the storage version need the conversion webhook
+		versions[i].Served = i == 0
 
 		if i == 0 {
 			versions[i].Storage = true
@@ -134,6 +139,7 @@ func InitClientScheme() *runtime.Scheme {
 
 	utilruntime.Must(appsv3.AddToScheme(scheme))
 	utilruntime.Must(cpv3.AddToScheme(scheme))
+	utilruntime.Must(cpv4.AddToScheme(scheme))
 
 	return scheme
 }
@@ -0,0 +1,274 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v3
+
+import (
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Defaults applied to ControlPlane specs by the defaulting webhook and by the reconciler.
+const (
+	DefaultControllerReplicas  = 1
+	DefaultNatsReplicas        = 2
+	DefaultEcnViewerPort       = 8008
+	DefaultPidBaseDir          = "/home/runner"
+	DefaultLogLevel            = "info"
+	DefaultServiceType         = string(corev1.ServiceTypeLoadBalancer)
+	DefaultNatsStorageSize     = "10Gi"
+	DefaultNatsMemoryStoreSize = "1Gi"
+	DefaultNatsServerPort      = 4222
+	DefaultNatsClusterPort     = 6222
+	DefaultNatsLeafPort        = 7422
+	DefaultNatsMqttPort        = 8883
+	DefaultNatsHTTPPort        = 8222
+	DefaultRouterMessagePort   = 5671
+	DefaultRouterInteriorPort  = 55671
+	DefaultRouterEdgePort      = 45671
+	DefaultRouterHTTPPort      = 9090
+
+	// Kinds of the routes attached to the Gateway for the Router and NATS
+	GatewayTCPRoute = "TCPRoute"
+	GatewayTLSRoute = "TLSRoute"
+
+	DefaultIssuerKind  = "Issuer"
+	DefaultIssuerGroup = "cert-manager.io"
+	// DefaultCertificateRenewBefore renews server certificates 30 days before they expire
+	DefaultCertificateRenewBefore = 30 * 24 * time.Hour
+	// OperatorCertificateValidity is how long the certificates the operator issues are valid, the default validity of the certs package
+	OperatorCertificateValidity = 5 * 365 * 24 * time.Hour
+	// IssuerCertificateDuration is the duration of the server certificates cert-manager issues
+	IssuerCertificateDuration = 90 * 24 * time.Hour
+	// DefaultCARotationGracePeriod keeps the previous CAs trusted for 7 days after a rotation
+	DefaultCARotationGracePeriod = 7 * 24 * time.Hour
+
+	// DefaultProgressDeadline fails a ControlPlane that is not ready 10 minutes after deploying or updating it started
+	DefaultProgressDeadline = 10 * time.Minute
+
+	// Deletion policies of the volumes and external state of a ControlPlane
+	DeletionPolicyRetain = "Retain"
+	DeletionPolicyDelete = "Delete"
+
+	DefaultControllerCPURequest    = "200m"
+	DefaultControllerMemoryRequest = "512Mi"
+	DefaultControllerMemoryLimit   = "2Gi"
+	DefaultRouterCPURequest        = "100m"
+	DefaultRouterMemoryRequest     = "128Mi"
+	DefaultRouterMemoryLimit       = "512Mi"
+	DefaultNatsCPURequest          = "100m"
+	DefaultNatsMemoryRequest       = "256Mi"
+	// DefaultNatsMemoryHeadroom is added to the JetStream memory store to size the NATS memory limit
+	DefaultNatsMemoryHeadroom = "1Gi"
+)
+
+// Default fills every unset field of the spec with the value the operator deploys.
+// Images are deliberately left empty so that upgrading the operator also upgrades the ControlPlane.
+func (cp *ControlPlane) Default() {
+	spec := &cp.Spec
+
+	if spec.Replicas.Controller == 0 {
+		spec.Replicas.Controller = DefaultControllerReplicas
+	}
+
+	defaultService(&spec.Services.Controller)
+	defaultService(&spec.Services.Router)
+	defaultService(&spec.Services.Nats)
+	defaultService(&spec.Services.NatsServer)
+
+	if spec.Controller.EcnViewerPort == 0 {
+		spec.Controller.EcnViewerPort = DefaultEcnViewerPort
+	}
+
+	if spec.Controller.PidBaseDir == "" {
+		spec.Controller.PidBaseDir = DefaultPidBaseDir
+	}
+
+	if spec.Controller.LogLevel == "" {
+		spec.Controller.LogLevel = DefaultLogLevel
+	}
+
+	if spec.Controller.Https == nil {
+		spec.Controller.Https = newBool(false)
+	}
+
+	if spec.Router.HA == nil {
+		spec.Router.HA = newBool(false)
+	}
+
+	defaultRouterPorts(&spec.Router.Ports)
+
+	defaultResources(&spec.Resources.Controller, DefaultControllerCPURequest, DefaultControllerMemoryRequest, DefaultControllerMemoryLimit)
+	defaultResources(&spec.Resources.Router, DefaultRouterCPURequest, DefaultRouterMemoryRequest, DefaultRouterMemoryLimit)
+
+	if spec.Nats == nil {
+		spec.Nats = &Nats{}
+	}
+
+	if spec.Nats.Enabled == nil {
+		spec.Nats.Enabled = newBool(true)
+	}
+
+	if *spec.Nats.Enabled {
+		defaultNats(spec)
+	}
+
+	if spec.Gateway != nil {
+		defaultGateway(cp.Namespace, spec.Gateway)
+	}
+
+	if spec.Certificates == nil {
+		spec.Certificates = &Certificates{}
+	}
+
+	if spec.Certificates.IssuerRef != nil {
+		defaultIssuerRef(spec.Certificates.IssuerRef)
+	}
+
+	if spec.Certificates.RenewBefore == nil {
+		spec.Certificates.RenewBefore = &metav1.Duration{Duration: DefaultCertificateRenewBefore}
+	}
+
+	if spec.Certificates.CARotation != nil && spec.Certificates.CARotation.GracePeriod == nil {
+		spec.Certificates.CARotation.GracePeriod = &metav1.Duration{Duration: DefaultCARotationGracePeriod}
+	}
+
+	if spec.Vault != nil && spec.Vault.Enabled == nil {
+		spec.Vault.Enabled = newBool(true)
+	}
+
+	if spec.DeletionPolicy == nil {
+		spec.DeletionPolicy = &DeletionPolicy{}
+	}
+
+	// The Controller volume was always garbage collected, external state is only removed on request
+	if spec.DeletionPolicy.PersistentVolumeClaims == "" {
+		spec.DeletionPolicy.PersistentVolumeClaims = DeletionPolicyDelete
+	}
+
+	if spec.DeletionPolicy.ExternalState == "" {
+		spec.DeletionPolicy.ExternalState = DeletionPolicyRetain
+	}
+
+	if spec.ProgressDeadline == nil {
+		spec.ProgressDeadline = &metav1.Duration{Duration: DefaultProgressDeadline}
+	}
+}
+
+func defaultNats(spec *ControlPlaneSpec) {
+	if spec.Replicas.Nats == 0 {
+		spec.Replicas.Nats = DefaultNatsReplicas
+	}
+
+	if spec.Nats.JetStream.StorageSize == "" {
+		spec.Nats.JetStream.StorageSize = DefaultNatsStorageSize
+	}
+
+	if spec.Nats.JetStream.MemoryStoreSize == "" {
+		spec.Nats.JetStream.MemoryStoreSize = DefaultNatsMemoryStoreSize
+	}
+
+	// NATS resources are left unset, the reconciler sizes the default memory limit after the JetStream memory store
+
+	ports := &spec.Nats.Ports
+	defaultPort(&ports.ServerPort, DefaultNatsServerPort)
+	defaultPort(&ports.ClusterPort, DefaultNatsClusterPort)
+	defaultPort(&ports.LeafPort, DefaultNatsLeafPort)
+	defaultPort(&ports.MqttPort, DefaultNatsMqttPort)
+	defaultPort(&ports.HttpPort, DefaultNatsHTTPPort)
+
+	// Ingress ports are left unset, the reconciler advertises the listener ports for them so they follow port changes
+}
+
+func defaultGateway(namespace string, gateway *Gateway) {
+	if gateway.Namespace == "" {
+		gateway.Namespace = namespace
+	}
+
+	if gateway.Router != nil && gateway.Router.Kind == "" {
+		gateway.Router.Kind = GatewayTCPRoute
+	}
+
+	if gateway.Nats != nil && gateway.Nats.Kind == "" {
+		gateway.Nats.Kind = GatewayTCPRoute
+	}
+}
+
+func defaultIssuerRef(ref *IssuerRef) {
+	if ref.Kind == "" {
+		ref.Kind = DefaultIssuerKind
+	}
+
+	if ref.Group == "" {
+		ref.Group = DefaultIssuerGroup
+	}
+}
+
+func defaultRouterPorts(ports *RouterPorts) {
+	defaultPort(&ports.MessagePort, DefaultRouterMessagePort)
+	defaultPort(&ports.InteriorPort, DefaultRouterInteriorPort)
+	defaultPort(&ports.EdgePort, DefaultRouterEdgePort)
+	defaultPort(&ports.HTTPPort, DefaultRouterHTTPPort)
+}
+
+func defaultPort(port *int, value int) {
+	if *port == 0 {
+		*port = value
+	}
+}
+
+// defaultService sets the Service type and the externalTrafficPolicy that goes with it.
+// LoadBalancer defaults to Local, NodePort to Cluster; ClusterIP must not set a policy.
+func defaultService(svc *Service) {
+	if svc.Type == "" {
+		svc.Type = DefaultServiceType
+	}
+
+	if svc.ExternalTrafficPolicy != "" {
+		return
+	}
+
+	switch {
+	case strings.EqualFold(svc.Type, string(corev1.ServiceTypeLoadBalancer)):
+		svc.ExternalTrafficPolicy = string(corev1.ServiceExternalTrafficPolicyLocal)
+	case strings.EqualFold(svc.Type, string(corev1.ServiceTypeNodePort)):
+		svc.ExternalTrafficPolicy = string(corev1.ServiceExternalTrafficPolicyCluster)
+	}
+}
+
+// defaultResources only applies to a component without any requests or limits,
+// partially configured resources are left as they are so requests never exceed user limits.
+func defaultResources(res *corev1.ResourceRequirements, cpuRequest, memoryRequest, memoryLimit string) {
+	if len(res.Requests) != 0 || len(res.Limits) != 0 {
+		return
+	}
+
+	res.Requests = corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse(cpuRequest),
+		corev1.ResourceMemory: resource.MustParse(memoryRequest),
+	}
+	res.Limits = corev1.ResourceList{
+		corev1.ResourceMemory: resource.MustParse(memoryLimit),
+	}
+}
+
+func newBool(val bool) *bool {
+	return &val
+}
@@ -22,8 +22,10 @@ import (
 	"time"
 
 	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
 	cond "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 const (
@@ -32,6 +34,19 @@ const (
 	conditionUpdating  = "updating"
 )
 
+// Conditions reporting the health of the components, they are set next to the lifecycle condition
+const (
+	ConditionControllerReady   = "ControllerReady"
+	ConditionRouterReady       = "RouterReady"
+	ConditionNatsReady         = "NatsReady"
+	ConditionDatabaseReachable = "DatabaseReachable"
+	ConditionAuthReachable     = "AuthReachable"
+	// ConditionDegraded is set while a ready ControlPlane fails its health checks
+	ConditionDegraded = "Degraded"
+	// ConditionFailed is set when deploying or updating the ControlPlane did not complete within its progress deadline
+	ConditionFailed = "Failed"
+)
+
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
@@ -52,16 +67,153 @@ type ControlPlaneSpec struct {
 	Replicas Replicas `json:"replicas,omitempty"`
 	// Images specifies which containers to run for each component of the ControlPlane
 	Images Images `json:"images,omitempty"`
+	// Resources are the compute resource requests and limits of each component of the ControlPlane
+	Resources Resources `json:"resources,omitempty"`
+	// Scheduling places the pods of each component of the ControlPlane on nodes
+	Scheduling Scheduling `json:"scheduling,omitempty"`
+	// PodDisruptionBudgets override the budgets the operator computes from the replicas of each component
+	PodDisruptionBudgets PodDisruptionBudgets `json:"podDisruptionBudgets,omitempty"`
 	// Controller contains runtime configuration for ioFog Controller
 	Controller Controller `json:"controller,omitempty"`
-	// // Router contains runtime configuration for ioFog Router
-	// Router Router `json:"router,omitempty"`
+	// Router contains runtime configuration for ioFog Router
+	Router Router `json:"router,omitempty"`
 	// Events contains runtime configuration for ioFog Controller events
 	Events Events `json:"events,omitempty"`
 	// Nats contains NATS hub configuration (StatefulSet, JetStream, etc.). When omitted, NATS is enabled with defaults.
 	Nats *Nats `json:"nats,omitempty"`
 	// Vault is optional. When set, the Controller uses the configured vault provider for secrets. Operator creates a Secret from provider-specific config and injects env vars.
 	Vault *Vault `json:"vault,omitempty"`
+	// Gateway is optional. When set, the operator attaches Gateway API routes to the Gateway for the configured components.
+	Gateway *Gateway `json:"gateway,omitempty"`
+	// Certificates configures how the router and NATS TLS certificates are issued, the operator signs them when omitted
+	Certificates *Certificates `json:"certificates,omitempty"`
+	// DeletionPolicy decides which volumes and external state are removed when the ControlPlane is deleted
+	DeletionPolicy *DeletionPolicy `json:"deletionPolicy,omitempty"`
+	// ProgressDeadline is how long deploying or updating the ControlPlane may take before it is Failed, defaults to 10m
+	// +optional
+	ProgressDeadline *metav1.Duration `json:"progressDeadline,omitempty"`
+}
+
+// DeletionPolicy decides what deleting the ControlPlane removes besides the resources it owns.
+type DeletionPolicy struct {
+	// PersistentVolumeClaims of the Controller SQLite database and of JetStream, defaults to Delete
+	// +kubebuilder:validation:Enum=Retain;Delete
+	// +optional
+	PersistentVolumeClaims string `json:"persistentVolumeClaims,omitempty"`
+	// ExternalState is the ECN Viewer client root URL in Keycloak and the external database of the Controller.
+	// Delete resets the root URL and drops the database, defaults to Retain.
+	// +kubebuilder:validation:Enum=Retain;Delete
+	// +optional
+	ExternalState string `json:"externalState,omitempty"`
+}
+
+// Certificates configures the issuance of the router and NATS server certificates.
+type Certificates struct {
+	// IssuerRef makes cert-manager issue the server certificates instead of the operator.
+	// The CA of the issuer is imported into the Controller in place of the operator CAs.
+	// +optional
+	IssuerRef *IssuerRef `json:"issuerRef,omitempty"`
+	// RenewBefore is how long before they expire the server certificates are renewed, defaults to 720h.
+	// It must be shorter than their validity of 43800h, or 2160h when cert-manager issues them.
+	// +optional
+	RenewBefore *metav1.Duration `json:"renewBefore,omitempty"`
+	// CARotation rotates the operator CAs of the router and NATS, CAs of an issuer are rotated by the issuer
+	// +optional
+	CARotation *CARotation `json:"caRotation,omitempty"`
+	// CAs are existing Secrets with a CA tls.crt and tls.key that sign the server certificates instead of operator CAs
+	// and are imported into the Controller. The operator does not copy, rotate or retire them.
+	// +optional
+	CAs *CASecrets `json:"cas,omitempty"`
+}
+
+// CASecrets names the Secrets of the provided CAs per trust domain, trust domains left empty keep operator CAs.
+// A ca.crt in the Secret is trusted instead of tls.crt, e.g. to include the chain of an intermediate CA.
+type CASecrets struct {
+	// RouterSite signs the router site server certificate used by interior routers
+	// +optional
+	RouterSite string `json:"routerSite,omitempty"`
+	// RouterLocal signs the router local server certificate used by edge routers
+	// +optional
+	RouterLocal string `json:"routerLocal,omitempty"`
+	// NatsSite signs the NATS site server certificate
+	// +optional
+	NatsSite string `json:"natsSite,omitempty"`
+	// NatsLocal signs the NATS mqtt server certificate
+	// +optional
+	NatsLocal string `json:"natsLocal,omitempty"`
+}
+
+// CARotation replaces the router and NATS CAs without breaking the agents that trust them.
+// The new CAs are trusted next to the previous ones until the grace period ends.
+type CARotation struct {
+	// Generation starts a rotation whenever it is increased
+	// +kubebuilder:validation:Minimum=0
+	Generation int64 `json:"generation,omitempty"`
+	// GracePeriod the previous CAs stay trusted after a rotation, defaults to 168h
+	// +optional
+	GracePeriod *metav1.Duration `json:"gracePeriod,omitempty"`
+}
+
+// IssuerRef references a cert-manager Issuer or ClusterIssuer.
+type IssuerRef struct {
+	// Name of the issuer
+	Name string `json:"name"`
+	// Kind of the issuer, defaults to Issuer
+	// +kubebuilder:validation:Enum=Issuer;ClusterIssuer
+	// +optional
+	Kind string `json:"kind,omitempty"`
+	// Group of the issuer, defaults to cert-manager.io
+	// +optional
+	Group string `json:"group,omitempty"`
+}
+
+// Gateway references an existing Gateway API Gateway the operator attaches routes to.
+// Advertised addresses are resolved from the Gateway status unless the routes set hostnames.
+type Gateway struct {
+	// Name of the Gateway
+	Name string `json:"name"`
+	// Namespace of the Gateway, defaults to the ControlPlane namespace
+	Namespace string `json:"namespace,omitempty"`
+	// Controller creates an HTTPRoute for the Controller API and the ECN Viewer
+	Controller *GatewayHTTPRoute `json:"controller,omitempty"`
+	// Router creates routes for the router edge and interior listeners
+	Router *GatewayRouterRoutes `json:"router,omitempty"`
+	// Nats creates routes for the NATS leaf and mqtt listeners
+	Nats *GatewayNatsRoutes `json:"nats,omitempty"`
+}
+
+// GatewayHTTPRoute exposes the Controller API and the ECN Viewer through an HTTPRoute.
+type GatewayHTTPRoute struct {
+	// Hostnames of the HTTPRoute, the first one is used for the ECN Viewer URL
+	Hostnames []string `json:"hostnames,omitempty"`
+	// Listener is the section name of the Gateway listener, all listeners when omitted
+	Listener string `json:"listener,omitempty"`
+}
+
+// GatewayRouterRoutes exposes the router edge and interior listeners, each through its own Gateway listener.
+type GatewayRouterRoutes struct {
+	// Kind of the routes, TLSRoute passes TLS through based on the hostnames
+	// +kubebuilder:validation:Enum=TCPRoute;TLSRoute
+	Kind string `json:"kind,omitempty"`
+	// Hostnames of TLSRoutes, the first one is registered with the Controller instead of the Gateway address
+	Hostnames []string `json:"hostnames,omitempty"`
+	// EdgeListener is the section name of the Gateway listener for edge routers
+	EdgeListener string `json:"edgeListener"`
+	// InteriorListener is the section name of the Gateway listener for interior routers
+	InteriorListener string `json:"interiorListener"`
+}
+
+// GatewayNatsRoutes exposes the NATS leaf and mqtt listeners, each through its own Gateway listener.
+type GatewayNatsRoutes struct {
+	// Kind of the routes, TLSRoute passes TLS through based on the hostnames
+	// +kubebuilder:validation:Enum=TCPRoute;TLSRoute
+	Kind string `json:"kind,omitempty"`
+	// Hostnames of TLSRoutes, the first one is registered with the Controller instead of the Gateway address
+	Hostnames []string `json:"hostnames,omitempty"`
+	// LeafListener is the section name of the Gateway listener for leaf nodes
+	LeafListener string `json:"leafListener"`
+	// MqttListener is the section name of the Gateway listener for MQTT clients
+	MqttListener string `json:"mqttListener"`
 }
 
 // Vault configures vault integration for the Controller. Optional; when omitted, no vault env vars are set.
@@ -93,7 +245,9 @@ type Vault struct {
 type VaultHashicorp struct {
 	Address string `json:"address,omitempty"`
 	Token   string `json:"token,omitempty"`
-	Mount   string `json:"mount,omitempty"`
+	// TokenFrom reads the token from a Secret instead of Token.
+	TokenFrom *SecretValueSource `json:"tokenFrom,omitempty"`
+	Mount     string             `json:"mount,omitempty"`
 }
 
 // VaultAws holds AWS Secrets Manager configuration. Operator stores these in a Secret and maps to VAULT_AWS_* env vars.
@@ -101,6 +255,8 @@ type VaultAws struct {
 	Region      string `json:"region,omitempty"`
 	AccessKeyId string `json:"accessKeyId,omitempty"`
 	AccessKey   string `json:"accessKey,omitempty"`
+	// AccessKeyFrom reads the access key from a Secret instead of AccessKey.
+	AccessKeyFrom *SecretValueSource `json:"accessKeyFrom,omitempty"`
 }
 
 // VaultAzure holds Azure Key Vault configuration. Operator stores these in a Secret and maps to VAULT_AZURE_* env vars.
@@ -109,12 +265,22 @@ type VaultAzure struct {
 	TenantId     string `json:"tenantId,omitempty"`
 	ClientId     string `json:"clientId,omitempty"`
 	ClientSecret string `json:"clientSecret,omitempty"`
+	// ClientSecretFrom reads the client secret from a Secret instead of ClientSecret.
+	ClientSecretFrom *SecretValueSource `json:"clientSecretFrom,omitempty"`
 }
 
 // VaultGoogle holds Google Secret Manager configuration. Operator stores these in a Secret and maps to VAULT_GOOGLE_* env vars.
 type VaultGoogle struct {
 	ProjectId   string `json:"projectId,omitempty"`
 	Credentials string `json:"credentials,omitempty"` // path to service account key file or JSON content
+	// CredentialsFrom reads the credentials from a Secret instead of Credentials.
+	CredentialsFrom *SecretValueSource `json:"credentialsFrom,omitempty"`
+}
+
+// SecretValueSource selects a sensitive value from a Secret in the ControlPlane namespace.
+// The operator watches the referenced Secret and rolls the Controller when the value changes.
+type SecretValueSource struct {
+	SecretKeyRef *corev1.SecretKeySelector `json:"secretKeyRef,omitempty"`
 }
 
 type Replicas struct {
@@ -146,14 +312,65 @@ type Images struct {
 	Nats       string `json:"nats,omitempty"`
 }
 
+// Resources configures the container resources of each component.
+// A component without requests and limits gets the operator defaults.
+type Resources struct {
+	Controller corev1.ResourceRequirements `json:"controller,omitempty"`
+	Router     corev1.ResourceRequirements `json:"router,omitempty"`
+	// Nats memory limit must be greater than spec.nats.jetStream.memoryStoreSize.
+	// The default memory limit is the JetStream memory store plus 1Gi.
+	Nats corev1.ResourceRequirements `json:"nats,omitempty"`
+}
+
+// Scheduling configures the pod placement of each component.
+// When Nats sets no affinity or topology spread, NATS replicas are spread across nodes and zones.
+type Scheduling struct {
+	Controller PodScheduling `json:"controller,omitempty"`
+	Router     PodScheduling `json:"router,omitempty"`
+	Nats       PodScheduling `json:"nats,omitempty"`
+}
+
+// PodScheduling is copied as is to the pod template of a component.
+type PodScheduling struct {
+	NodeSelector              map[string]string                 `json:"nodeSelector,omitempty"`
+	Tolerations               []corev1.Toleration               `json:"tolerations,omitempty"`
+	Affinity                  *corev1.Affinity                  `json:"affinity,omitempty"`
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+	PriorityClassName         string                            `json:"priorityClassName,omitempty"`
+}
+
+type PodDisruptionBudgets struct {
+	Controller PodDisruptionBudget `json:"controller,omitempty"`
+	Router     PodDisruptionBudget `json:"router,omitempty"`
+	Nats       PodDisruptionBudget `json:"nats,omitempty"`
+}
+
+// PodDisruptionBudget overrides the budget of a component.
+// By default a budget is created for components with more than one replica.
+type PodDisruptionBudget struct {
+	// Enabled forces the budget to be created (true) or removed (false).
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+	// MinAvailable replaces the computed budget. Cannot be set together with MaxUnavailable.
+	// +optional
+	MinAvailable *intstr.IntOrString `json:"minAvailable,omitempty"`
+	// MaxUnavailable replaces the computed budget. Cannot be set together with MinAvailable.
+	// +optional
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+}
+
 type Auth struct {
 	URL              string `json:"url"`
 	Realm            string `json:"realm"`
 	SSL              string `json:"ssl"`
 	RealmKey         string `json:"realmKey"`
 	ControllerClient string `json:"controllerClient"`
-	ControllerSecret string `json:"controllerSecret"`
-	ViewerClient     string `json:"viewerClient"`
+	// ControllerSecret is the Keycloak client secret. Use ControllerSecretFrom to keep it out of the spec.
+	// +optional
+	ControllerSecret string `json:"controllerSecret,omitempty"`
+	// +optional
+	ControllerSecretFrom *SecretValueSource `json:"controllerSecretFrom,omitempty"`
+	ViewerClient         string             `json:"viewerClient"`
 }
 
 type Events struct {
@@ -164,14 +381,18 @@ type Events struct {
 }
 
 type Database struct {
-	Provider     string  `json:"provider"`
-	Host         string  `json:"host"`
-	Port         int     `json:"port"`
-	User         string  `json:"user"`
-	Password     string  `json:"password"`
-	DatabaseName string  `json:"databaseName"`
-	SSL          *bool   `json:"ssl,omitempty"`
-	CA           *string `json:"ca,omitempty"`
+	Provider string `json:"provider"`
+	Host     string `json:"host"`
+	Port     int    `json:"port"`
+	User     string `json:"user"`
+	// Password of the database user. Use PasswordFrom to keep it out of the spec.
+	// +optional
+	Password string `json:"password,omitempty"`
+	// +optional
+	PasswordFrom *SecretValueSource `json:"passwordFrom,omitempty"`
+	DatabaseName string             `json:"databaseName"`
+	SSL          *bool              `json:"ssl,omitempty"`
+	CA           *string            `json:"ca,omitempty"`
 }
 
 type User struct {
@@ -201,7 +422,7 @@ type Ingress struct {
 }
 
 // NatsIngress specifies the external address and ports for NATS hub registration (required when using ingress).
-// Ports are optional and default to: server 4222, cluster 6222, leaf 7422, mqtt 8883, http 8222.
+// Ports are optional and default to the ports of spec.nats.ports.
 type NatsIngress struct {
 	Address     string `json:"address,omitempty"`
 	ServerPort  int    `json:"serverPort,omitempty"`
@@ -227,9 +448,30 @@ type Controller struct {
 	LogLevel      string `json:"logLevel,omitempty"`
 }
 
-// type Router struct {
-// 	HA *bool `json:"ha,omitempty"`
-// }
+type Router struct {
+	// HA runs a second interior router linked to the first one, both serve the router Service.
+	HA *bool `json:"ha,omitempty"`
+	// Ports the routers listen on, the router Service exposes the same ports.
+	Ports RouterPorts `json:"ports,omitempty"`
+}
+
+// RouterPorts are the router listener ports.
+// They default to: message 5671, interior 55671, edge 45671, http 9090.
+type RouterPorts struct {
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	MessagePort int `json:"messagePort,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	InteriorPort int `json:"interiorPort,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	EdgePort int `json:"edgePort,omitempty"`
+	// HTTPPort serves the health and metrics endpoints
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	HTTPPort int `json:"httpPort,omitempty"`
+}
 
 // NatsJetStream configures JetStream storage.
 type NatsJetStream struct {
@@ -248,6 +490,30 @@ type Nats struct {
 	Enabled *bool `json:"enabled,omitempty"`
 	// JetStream storage and memory limits.
 	JetStream NatsJetStream `json:"jetStream,omitempty"`
+	// Ports the NATS servers listen on, the NATS Services expose the same ports.
+	// spec.ingresses.nats ports that are not set advertise these ports.
+	Ports NatsPorts `json:"ports,omitempty"`
+}
+
+// NatsPorts are the NATS listener ports.
+// They default to: server 4222, cluster 6222, leaf 7422, mqtt 8883, http 8222.
+type NatsPorts struct {
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	ServerPort int `json:"serverPort,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	ClusterPort int `json:"clusterPort,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	LeafPort int `json:"leafPort,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	MqttPort int `json:"mqttPort,omitempty"`
+	// HttpPort serves the monitoring endpoints and the health probes
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	HttpPort int `json:"httpPort,omitempty"`
 }
 
 // ControlPlaneStatus defines the observed state of ControlPlane.
@@ -255,10 +521,72 @@ type ControlPlaneStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
 	Conditions []metav1.Condition `json:"conditions"`
+	// NextCertificateExpiry is the earliest expiry of the router and NATS server certificates
+	// +optional
+	NextCertificateExpiry *metav1.Time `json:"nextCertificateExpiry,omitempty"`
+	// CARotation is the progress of the last CA rotation
+	// +optional
+	CARotation *CARotationStatus `json:"caRotation,omitempty"`
+	// ImportedCAs identify the CAs the Controller imported by their name, a changed CA is imported again
+	// +optional
+	ImportedCAs map[string]string `json:"importedCAs,omitempty"`
+	// Endpoints are the resolved addresses of the components
+	// +optional
+	Endpoints *EndpointsStatus `json:"endpoints,omitempty"`
+	// ControllerVersion is the version reported by the Controller status API
+	// +optional
+	ControllerVersion string `json:"controllerVersion,omitempty"`
+	// Replicas are the ready replicas observed for each component
+	// +optional
+	Replicas *ReplicasStatus `json:"replicas,omitempty"`
+	// LastError is the error of the last failed reconcile, it is cleared once a reconcile succeeds
+	// +optional
+	LastError string `json:"lastError,omitempty"`
+}
+
+// CARotationStatus records the last CA rotation.
+type CARotationStatus struct {
+	// Generation of spec.certificates.caRotation the CAs were last rotated for
+	Generation int64 `json:"generation"`
+	// PendingGeneration of spec.certificates.caRotation the CAs are being rotated for, it is recorded before the CA Secrets are written
+	// +optional
+	PendingGeneration int64 `json:"pendingGeneration,omitempty"`
+	// RetireAfter is when the previous CAs stop being trusted, it is unset once they are retired
+	// +optional
+	RetireAfter *metav1.Time `json:"retireAfter,omitempty"`
+}
+
+// EndpointsStatus records the resolved addresses of the components.
+type EndpointsStatus struct {
+	// Controller is the URL of the Controller API, the external one when it is exposed
+	// +optional
+	Controller string `json:"controller,omitempty"`
+	// Router is the address advertised to the agents' routers
+	// +optional
+	Router string `json:"router,omitempty"`
+	// Nats is the address advertised to the agents' NATS leaf nodes
+	// +optional
+	Nats string `json:"nats,omitempty"`
+}
+
+// ReplicasStatus records the ready replicas of the components.
+type ReplicasStatus struct {
+	Controller int32 `json:"controller"`
+	Router     int32 `json:"router"`
+	Nats       int32 `json:"nats"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:storageversion
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="ready")].status`
+// +kubebuilder:printcolumn:name="Controller",type=string,JSONPath=`.status.conditions[?(@.type=="ControllerReady")].status`
+// +kubebuilder:printcolumn:name="Router",type=string,JSONPath=`.status.conditions[?(@.type=="RouterReady")].status`
+// +kubebuilder:printcolumn:name="NATS",type=string,JSONPath=`.status.conditions[?(@.type=="NatsReady")].status`
+// +kubebuilder:printcolumn:name="Version",type=string,JSONPath=`.status.controllerVersion`
+// +kubebuilder:printcolumn:name="Endpoint",type=string,JSONPath=`.status.endpoints.controller`
+// +kubebuilder:printcolumn:name="Error",type=string,JSONPath=`.status.lastError`,priority=1
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 // ControlPlane is the Schema for the controlplanes API.
 type ControlPlane struct {
 	metav1.TypeMeta   `json:",inline"`
@@ -268,11 +596,18 @@ type ControlPlane struct {
 	Status ControlPlaneStatus `json:"status,omitempty"`
 }
 
+func isLifecycleCondition(conditionType string) bool {
+	return conditionType == conditionReady || conditionType == conditionDeploying || conditionType == conditionUpdating
+}
+
 func (cp *ControlPlane) setCondition(conditionType string, log *logr.Logger) {
 	now := metav1.NewTime(time.Now())
-	// Clear all
+	// Clear all lifecycle conditions, component conditions are set independently
 	for idx := range cp.Status.Conditions {
 		condition := &cp.Status.Conditions[idx]
+		if !isLifecycleCondition(condition.Type) {
+			continue
+		}
 		// Migration: all lower case, no spaces, no -
 		condition.Reason = strings.ToLower(condition.Reason)
 		condition.Reason = strings.Replace(condition.Reason, " ", "_", -1)
@@ -317,7 +652,7 @@ func (cp *ControlPlane) GetCondition() string {
 	state := conditionDeploying
 
 	for _, condition := range cp.Status.Conditions {
-		if condition.Status == metav1.ConditionTrue {
+		if isLifecycleCondition(condition.Type) && condition.Status == metav1.ConditionTrue {
 			if condition.ObservedGeneration == cp.ObjectMeta.Generation {
 				state = condition.Type
 			} else {
@@ -342,6 +677,56 @@ func (cp *ControlPlane) IsUpdating() bool {
 	return cp.GetCondition() == conditionUpdating
 }
 
+// ReadySince returns when the ControlPlane last became ready, the zero time when it is not ready
+func (cp *ControlPlane) ReadySince() time.Time {
+	if !cp.IsReady() {
+		return time.Time{}
+	}
+
+	if condition := cond.FindStatusCondition(cp.Status.Conditions, conditionReady); condition != nil {
+		return condition.LastTransitionTime.Time
+	}
+
+	return time.Time{}
+}
+
+// ProgressingSince returns when deploying or updating the current generation of the ControlPlane started,
+// the zero time when it is ready or the start was not recorded yet
+func (cp *ControlPlane) ProgressingSince() time.Time {
+	for _, condition := range cp.Status.Conditions {
+		if condition.Type == conditionReady || !isLifecycleCondition(condition.Type) {
+			continue
+		}
+
+		if condition.Status == metav1.ConditionTrue && condition.ObservedGeneration == cp.ObjectMeta.Generation {
+			return condition.LastTransitionTime.Time
+		}
+	}
+
+	return time.Time{}
+}
+
+// SetComponentCondition sets a component condition, its transition time only changes with its status
+func (cp *ControlPlane) SetComponentCondition(conditionType string, status bool, reason, message string) {
+	conditionStatus := metav1.ConditionFalse
+	if status {
+		conditionStatus = metav1.ConditionTrue
+	}
+
+	cond.SetStatusCondition(&cp.Status.Conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             conditionStatus,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: cp.ObjectMeta.Generation,
+	})
+}
+
+// RemoveComponentCondition removes the condition of a component that is not deployed
+func (cp *ControlPlane) RemoveComponentCondition(conditionType string) {
+	cond.RemoveStatusCondition(&cp.Status.Conditions, conditionType)
+}
+
 // +kubebuilder:object:root=true
 
 // ControlPlaneList contains a list of ControlPlane.
@@ -0,0 +1,618 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v3
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	minNatsReplicas = 2
+	// routerAmqpPort is the local AMQP listener of skrouterd.json, it is not configurable
+	routerAmqpPort = 5672
+
+	vaultProviderHashicorp = "hashicorp"
+	vaultProviderAws       = "aws"
+	vaultProviderAzure     = "azure"
+	vaultProviderGoogle    = "google"
+)
+
+// vaultProviders maps every accepted Vault provider name to the spec.vault block it requires.
+var vaultProviders = map[string]string{ //nolint:gochecknoglobals
+	"hashicorp":             vaultProviderHashicorp,
+	"openbao":               vaultProviderHashicorp,
+	"vault":                 vaultProviderHashicorp,
+	"aws":                   vaultProviderAws,
+	"aws-secrets-manager":   vaultProviderAws,
+	"azure":                 vaultProviderAzure,
+	"azure-key-vault":       vaultProviderAzure,
+	"google":                vaultProviderGoogle,
+	"google-secret-manager": vaultProviderGoogle,
+}
+
+func (cp *ControlPlane) validateCreate() (admission.Warnings, error) {
+	return cp.warnings(), cp.toInvalidError(cp.validateSpec())
+}
+
+func (cp *ControlPlane) validateUpdate(old *ControlPlane) (admission.Warnings, error) {
+	// Objects stored before the defaulting webhook existed are compared by their effective values
+	old = old.DeepCopy()
+	old.Default()
+
+	// Adding and removing finalizers only changes the metadata, it must not be held back by the spec
+	if cp.DeletionTimestamp != nil || equality.Semantic.DeepEqual(cp.Spec, old.Spec) {
+		return nil, nil
+	}
+
+	allErrs := cp.validateSpec()
+	allErrs = append(allErrs, cp.validateSpecUpdate(old)...)
+
+	return cp.warnings(), cp.toInvalidError(allErrs)
+}
+
+func (cp *ControlPlane) toInvalidError(allErrs field.ErrorList) error {
+	if len(allErrs) == 0 {
+		return nil
+	}
+
+	return apierrors.NewInvalid(GroupVersion.WithKind("ControlPlane").GroupKind(), cp.Name, allErrs)
+}
+
+// validateSpec checks the combinations of fields the reconciler cannot satisfy.
+func (cp *ControlPlane) validateSpec() field.ErrorList {
+	var allErrs field.ErrorList
+
+	specPath := field.NewPath("spec")
+	spec := &cp.Spec
+
+	// Controller replicas share state through the database, the embedded SQLite cannot be shared
+	if spec.Replicas.Controller > 1 && spec.Database.Host == "" {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("replicas", "controller"),
+			"more than 1 Controller replica requires an external database (spec.database.host)"))
+	}
+
+	allErrs = append(allErrs, validateServices(specPath.Child("services"), &spec.Services)...)
+
+	// ClusterIP Controller is only reachable through the Ingress or the HTTPRoute the operator creates
+	if strings.EqualFold(spec.Services.Controller.Type, string(corev1.ServiceTypeClusterIP)) && spec.Ingresses.Controller.Host == "" &&
+		(spec.Gateway == nil || spec.Gateway.Controller == nil) {
+		allErrs = append(allErrs, field.Required(specPath.Child("ingresses", "controller", "host"),
+			"required when spec.services.controller.type is ClusterIP"))
+	}
+
+	// Without a LoadBalancer or a Gateway the Router address cannot be discovered
+	if !strings.EqualFold(spec.Services.Router.Type, string(corev1.ServiceTypeLoadBalancer)) && spec.Ingresses.Router.Address == "" &&
+		(spec.Gateway == nil || spec.Gateway.Router == nil) {
+		allErrs = append(allErrs, field.Required(specPath.Child("ingresses", "router", "address"),
+			"required when spec.services.router.type is not LoadBalancer"))
+	}
+
+	if spec.Controller.Https != nil && *spec.Controller.Https && spec.Controller.SecretName == "" {
+		allErrs = append(allErrs, field.Required(specPath.Child("controller", "secretName"),
+			"required when spec.controller.https is true"))
+	}
+
+	routerPortsPath := specPath.Child("router", "ports")
+	allErrs = append(allErrs, validateUniquePorts(routerPortsPath, []namedPort{
+		{"messagePort", spec.Router.Ports.MessagePort},
+		{"interiorPort", spec.Router.Ports.InteriorPort},
+		{"edgePort", spec.Router.Ports.EdgePort},
+		{"httpPort", spec.Router.Ports.HTTPPort},
+	}, map[int]string{routerAmqpPort: "the local amqp listener"})...)
+
+	allErrs = append(allErrs, validateSecretValue(specPath.Child("auth"), "controllerSecret", spec.Auth.ControllerSecret, spec.Auth.ControllerSecretFrom)...)
+	allErrs = append(allErrs, validateSecretValue(specPath.Child("database"), "password", spec.Database.Password, spec.Database.PasswordFrom)...)
+
+	if cp.isNatsEnabled() {
+		if spec.Replicas.Nats != 0 && spec.Replicas.Nats < minNatsReplicas {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("replicas", "nats"), spec.Replicas.Nats,
+				fmt.Sprintf("must be at least %d when NATS is enabled", minNatsReplicas)))
+		}
+	}
+
+	resourcesPath := specPath.Child("resources")
+	allErrs = append(allErrs, validateResources(resourcesPath.Child("controller"), &spec.Resources.Controller)...)
+	allErrs = append(allErrs, validateResources(resourcesPath.Child("router"), &spec.Resources.Router)...)
+	allErrs = append(allErrs, validateResources(resourcesPath.Child("nats"), &spec.Resources.Nats)...)
+
+	pdbPath := specPath.Child("podDisruptionBudgets")
+	allErrs = append(allErrs, validatePodDisruptionBudget(pdbPath.Child("controller"), &spec.PodDisruptionBudgets.Controller)...)
+	allErrs = append(allErrs, validatePodDisruptionBudget(pdbPath.Child("router"), &spec.PodDisruptionBudgets.Router)...)
+	allErrs = append(allErrs, validatePodDisruptionBudget(pdbPath.Child("nats"), &spec.PodDisruptionBudgets.Nats)...)
+
+	if cp.isNatsEnabled() {
+		allErrs = append(allErrs, cp.validateNatsMemory(resourcesPath.Child("nats"))...)
+	}
+
+	if spec.Nats != nil {
+		allErrs = append(allErrs, validateJetStream(specPath.Child("nats", "jetStream"), &spec.Nats.JetStream)...)
+		allErrs = append(allErrs, validateUniquePorts(specPath.Child("nats", "ports"), []namedPort{
+			{"serverPort", spec.Nats.Ports.ServerPort},
+			{"clusterPort", spec.Nats.Ports.ClusterPort},
+			{"leafPort", spec.Nats.Ports.LeafPort},
+			{"mqttPort", spec.Nats.Ports.MqttPort},
+			{"httpPort", spec.Nats.Ports.HttpPort},
+		}, nil)...)
+	}
+
+	if spec.Vault != nil {
+		allErrs = append(allErrs, validateVault(specPath.Child("vault"), spec.Vault)...)
+	}
+
+	if spec.Gateway != nil {
+		allErrs = append(allErrs, validateGateway(specPath.Child("gateway"), spec.Gateway)...)
+	}
+
+	if spec.Certificates != nil {
+		allErrs = append(allErrs, validateCertificates(specPath.Child("certificates"), spec.Certificates)...)
+	}
+
+	if spec.ProgressDeadline != nil && spec.ProgressDeadline.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("progressDeadline"), spec.ProgressDeadline.Duration.String(), "must be positive"))
+	}
+
+	return allErrs
+}
+
+// validateSpecUpdate checks transitions that cannot be applied to a running ControlPlane.
+func (cp *ControlPlane) validateSpecUpdate(old *ControlPlane) field.ErrorList {
+	var allErrs field.ErrorList
+
+	specPath := field.NewPath("spec")
+
+	// Controller data is not migrated between databases
+	if !strings.EqualFold(old.Spec.Database.Provider, cp.Spec.Database.Provider) {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("database", "provider"),
+			"database provider cannot be changed on an existing ControlPlane"))
+	}
+
+	if (old.Spec.Database.Host == "") != (cp.Spec.Database.Host == "") {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("database", "host"),
+			"cannot switch between the embedded database and an external database on an existing ControlPlane"))
+	}
+
+	if old.isNatsEnabled() && cp.isNatsEnabled() {
+		oldReplicas := old.natsReplicas()
+		newReplicas := cp.natsReplicas()
+
+		if newReplicas < minNatsReplicas && newReplicas < oldReplicas {
+			allErrs = append(allErrs, field.Forbidden(specPath.Child("replicas", "nats"),
+				fmt.Sprintf("cannot scale NATS below %d replicas", minNatsReplicas)))
+		}
+
+		// JetStream volumes are StatefulSet volumeClaimTemplates, which are immutable
+		oldJetStream, newJetStream := old.jetStream(), cp.jetStream()
+		jsPath := specPath.Child("nats", "jetStream")
+
+		if oldJetStream.StorageClassName != newJetStream.StorageClassName {
+			allErrs = append(allErrs, field.Forbidden(jsPath.Child("storageClassName"),
+				"JetStream storage class cannot be changed once NATS is deployed"))
+		}
+
+		if !sameQuantity(oldJetStream.StorageSize, newJetStream.StorageSize) {
+			allErrs = append(allErrs, field.Forbidden(jsPath.Child("storageSize"),
+				"JetStream storage size cannot be changed once NATS is deployed"))
+		}
+	}
+
+	// Rotations are recorded by generation, going back would never rotate again
+	if oldGeneration, newGeneration := old.caRotationGeneration(), cp.caRotationGeneration(); newGeneration < oldGeneration {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("certificates", "caRotation", "generation"),
+			fmt.Sprintf("cannot be decreased below %d", oldGeneration)))
+	}
+
+	return allErrs
+}
+
+func (cp *ControlPlane) caRotationGeneration() int64 {
+	if cp.Spec.Certificates == nil || cp.Spec.Certificates.CARotation == nil {
+		return 0
+	}
+
+	return cp.Spec.Certificates.CARotation.Generation
+}
+
+// warnings reports specs which are valid but only partially functional.
+func (cp *ControlPlane) warnings() admission.Warnings {
+	var warnings admission.Warnings
+
+	if cp.isNatsEnabled() &&
+		!strings.EqualFold(cp.Spec.Services.Nats.Type, string(corev1.ServiceTypeLoadBalancer)) &&
+		cp.Spec.Ingresses.Nats.Address == "" &&
+		(cp.Spec.Gateway == nil || cp.Spec.Gateway.Nats == nil) {
+		warnings = append(warnings,
+			"spec.ingresses.nats.address is empty and spec.services.nats.type is not LoadBalancer: the NATS hub will not be registered with the Controller")
+	}
+
+	return warnings
+}
+
+func validateServices(path *field.Path, services *Services) field.ErrorList {
+	var allErrs field.ErrorList
+
+	allErrs = append(allErrs, validateService(path.Child("controller"), &services.Controller)...)
+	allErrs = append(allErrs, validateService(path.Child("router"), &services.Router)...)
+	allErrs = append(allErrs, validateService(path.Child("nats"), &services.Nats)...)
+	allErrs = append(allErrs, validateService(path.Child("natsServer"), &services.NatsServer)...)
+
+	return allErrs
+}
+
+func validateService(path *field.Path, svc *Service) field.ErrorList {
+	var allErrs field.ErrorList
+
+	serviceTypes := []string{
+		string(corev1.ServiceTypeLoadBalancer),
+		string(corev1.ServiceTypeNodePort),
+		string(corev1.ServiceTypeClusterIP),
+	}
+	if svc.Type != "" && !containsFold(serviceTypes, svc.Type) {
+		allErrs = append(allErrs, field.NotSupported(path.Child("type"), svc.Type, serviceTypes))
+	}
+
+	trafficPolicies := []string{
+		string(corev1.ServiceExternalTrafficPolicyLocal),
+		string(corev1.ServiceExternalTrafficPolicyCluster),
+	}
+	if svc.ExternalTrafficPolicy != "" && !containsFold(trafficPolicies, svc.ExternalTrafficPolicy) {
+		allErrs = append(allErrs, field.NotSupported(path.Child("externalTrafficPolicy"), svc.ExternalTrafficPolicy, trafficPolicies))
+	}
+
+	return allErrs
+}
+
+func validateJetStream(path *field.Path, js *NatsJetStream) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if js.StorageSize != "" {
+		if _, err := resource.ParseQuantity(js.StorageSize); err != nil {
+			allErrs = append(allErrs, field.Invalid(path.Child("storageSize"), js.StorageSize, err.Error()))
+		}
+	}
+
+	if js.MemoryStoreSize != "" {
+		if _, err := resource.ParseQuantity(js.MemoryStoreSize); err != nil {
+			allErrs = append(allErrs, field.Invalid(path.Child("memoryStoreSize"), js.MemoryStoreSize, err.Error()))
+		}
+	}
+
+	return allErrs
+}
+
+// validateResources checks that no request exceeds the limit of the same resource.
+func validateResources(path *field.Path, res *corev1.ResourceRequirements) field.ErrorList {
+	var allErrs field.ErrorList
+
+	names := make([]string, 0, len(res.Requests))
+	for name := range res.Requests {
+		names = append(names, string(name))
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		request := res.Requests[corev1.ResourceName(name)]
+
+		limit, found := res.Limits[corev1.ResourceName(name)]
+		if found && request.Cmp(limit) > 0 {
+			allErrs = append(allErrs, field.Invalid(path.Child("requests").Key(name), request.String(),
+				fmt.Sprintf("must be less than or equal to %s limit of %s", name, limit.String())))
+		}
+	}
+
+	return allErrs
+}
+
+// validateNatsMemory checks that the JetStream memory store fits in the NATS container.
+func (cp *ControlPlane) validateNatsMemory(path *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	limit, found := cp.Spec.Resources.Nats.Limits[corev1.ResourceMemory]
+	if !found {
+		return allErrs
+	}
+
+	memoryStoreSize := cp.jetStream().MemoryStoreSize
+	if memoryStoreSize == "" {
+		memoryStoreSize = DefaultNatsMemoryStoreSize
+	}
+
+	memoryStore, err := resource.ParseQuantity(memoryStoreSize)
+	if err != nil {
+		// Reported by validateJetStream
+		return allErrs
+	}
+
+	if limit.Cmp(memoryStore) <= 0 {
+		allErrs = append(allErrs, field.Invalid(path.Child("limits").Key(string(corev1.ResourceMemory)), limit.String(),
+			fmt.Sprintf("must be greater than spec.nats.jetStream.memoryStoreSize (%s)", memoryStoreSize)))
+	}
+
+	return allErrs
+}
+
+type namedPort struct {
+	name string
+	port int
+}
+
+// validateUniquePorts checks that no two listeners of one container share a port, unset ports are skipped.
+// reserved holds the ports the container always listens on.
+func validateUniquePorts(path *field.Path, ports []namedPort, reserved map[int]string) field.ErrorList {
+	var allErrs field.ErrorList
+
+	seen := map[int]string{}
+	for port, name := range reserved {
+		seen[port] = name
+	}
+
+	for _, p := range ports {
+		if p.port == 0 {
+			continue
+		}
+
+		if other, found := seen[p.port]; found {
+			allErrs = append(allErrs, field.Invalid(path.Child(p.name), p.port, fmt.Sprintf("already used by %s", other)))
+
+			continue
+		}
+
+		seen[p.port] = p.name
+	}
+
+	return allErrs
+}
+
+// validateCertificates checks that the issuer is named, does not compete with provided CAs
+// and that certificates are renewed before they expire. Provided CAs are validated when they are loaded.
+func validateCertificates(path *field.Path, certs *Certificates) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if certs.IssuerRef != nil && certs.IssuerRef.Name == "" {
+		allErrs = append(allErrs, field.Required(path.Child("issuerRef", "name"), ""))
+	}
+
+	if certs.IssuerRef != nil && certs.CAs != nil {
+		allErrs = append(allErrs, field.Forbidden(path.Child("cas"), "cannot be combined with issuerRef"))
+	}
+
+	// A renewBefore of at least the validity would renew the certificates on every reconcile
+	validity := OperatorCertificateValidity
+	if certs.IssuerRef != nil {
+		validity = IssuerCertificateDuration
+	}
+
+	if certs.RenewBefore != nil && certs.RenewBefore.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(path.Child("renewBefore"), certs.RenewBefore.Duration.String(), "must be positive"))
+	} else if certs.RenewBefore != nil && certs.RenewBefore.Duration >= validity {
+		allErrs = append(allErrs, field.Invalid(path.Child("renewBefore"), certs.RenewBefore.Duration.String(),
+			fmt.Sprintf("must be shorter than the %s validity of the issued certificates", validity)))
+	}
+
+	if rotation := certs.CARotation; rotation != nil && rotation.GracePeriod != nil && rotation.GracePeriod.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(path.Child("caRotation", "gracePeriod"), rotation.GracePeriod.Duration.String(), "must be positive"))
+	}
+
+	return allErrs
+}
+
+// validateGateway checks that the Gateway is named and that TLSRoutes have hostnames to match on.
+func validateGateway(path *field.Path, gateway *Gateway) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if gateway.Name == "" {
+		allErrs = append(allErrs, field.Required(path.Child("name"), ""))
+	}
+
+	if gateway.Router != nil {
+		allErrs = append(allErrs, validateGatewayListenerRoutes(path.Child("router"), gateway.Router.Kind, gateway.Router.Hostnames,
+			namedListener{"edgeListener", gateway.Router.EdgeListener}, namedListener{"interiorListener", gateway.Router.InteriorListener})...)
+	}
+
+	if gateway.Nats != nil {
+		allErrs = append(allErrs, validateGatewayListenerRoutes(path.Child("nats"), gateway.Nats.Kind, gateway.Nats.Hostnames,
+			namedListener{"leafListener", gateway.Nats.LeafListener}, namedListener{"mqttListener", gateway.Nats.MqttListener})...)
+	}
+
+	return allErrs
+}
+
+type namedListener struct {
+	name     string
+	listener string
+}
+
+func validateGatewayListenerRoutes(path *field.Path, kind string, hostnames []string, listeners ...namedListener) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if kind == GatewayTLSRoute && len(hostnames) == 0 {
+		allErrs = append(allErrs, field.Required(path.Child("hostnames"), "required when kind is "+GatewayTLSRoute))
+	}
+
+	for _, l := range listeners {
+		if l.listener == "" {
+			allErrs = append(allErrs, field.Required(path.Child(l.name), ""))
+		}
+	}
+
+	// Every listener of a Gateway serves a single port
+	if len(listeners) == 2 && listeners[0].listener != "" && listeners[0].listener == listeners[1].listener {
+		allErrs = append(allErrs, field.Invalid(path.Child(listeners[1].name), listeners[1].listener,
+			fmt.Sprintf("must differ from %s", listeners[0].name)))
+	}
+
+	return allErrs
+}
+
+// validatePodDisruptionBudget checks that at most one of minAvailable and maxUnavailable is set.
+func validatePodDisruptionBudget(path *field.Path, pdb *PodDisruptionBudget) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if pdb.MinAvailable != nil && pdb.MaxUnavailable != nil {
+		allErrs = append(allErrs, field.Forbidden(path.Child("maxUnavailable"),
+			fmt.Sprintf("must not be set together with %s", path.Child("minAvailable").String())))
+	}
+
+	return allErrs
+}
+
+// validateVault checks that exactly the block matching spec.vault.provider is set.
+func validateVault(path *field.Path, vault *Vault) field.ErrorList {
+	var allErrs field.ErrorList
+
+	blocks := map[string]bool{
+		vaultProviderHashicorp: vault.Hashicorp != nil,
+		vaultProviderAws:       vault.Aws != nil,
+		vaultProviderAzure:     vault.Azure != nil,
+		vaultProviderGoogle:    vault.Google != nil,
+	}
+
+	if vault.Provider == "" {
+		for block, set := range blocks {
+			if set {
+				allErrs = append(allErrs, field.Required(path.Child("provider"),
+					fmt.Sprintf("required when spec.vault.%s is set", block)))
+
+				break
+			}
+		}
+
+		return allErrs
+	}
+
+	expected, ok := vaultProviders[strings.ToLower(vault.Provider)]
+	if !ok {
+		supported := make([]string, 0, len(vaultProviders))
+		for provider := range vaultProviders {
+			supported = append(supported, provider)
+		}
+
+		sort.Strings(supported)
+
+		return append(allErrs, field.NotSupported(path.Child("provider"), vault.Provider, supported))
+	}
+
+	if vault.Hashicorp != nil {
+		allErrs = append(allErrs, validateSecretValue(path.Child(vaultProviderHashicorp), "token", vault.Hashicorp.Token, vault.Hashicorp.TokenFrom)...)
+	}
+
+	if vault.Aws != nil {
+		allErrs = append(allErrs, validateSecretValue(path.Child(vaultProviderAws), "accessKey", vault.Aws.AccessKey, vault.Aws.AccessKeyFrom)...)
+	}
+
+	if vault.Azure != nil {
+		allErrs = append(allErrs, validateSecretValue(path.Child(vaultProviderAzure), "clientSecret", vault.Azure.ClientSecret, vault.Azure.ClientSecretFrom)...)
+	}
+
+	if vault.Google != nil {
+		allErrs = append(allErrs, validateSecretValue(path.Child(vaultProviderGoogle), "credentials", vault.Google.Credentials, vault.Google.CredentialsFrom)...)
+	}
+
+	if !blocks[expected] {
+		allErrs = append(allErrs, field.Required(path.Child(expected),
+			fmt.Sprintf("required when spec.vault.provider is %s", vault.Provider)))
+	}
+
+	for _, block := range []string{vaultProviderHashicorp, vaultProviderAws, vaultProviderAzure, vaultProviderGoogle} {
+		if block != expected && blocks[block] {
+			allErrs = append(allErrs, field.Forbidden(path.Child(block),
+				fmt.Sprintf("must not be set when spec.vault.provider is %s", vault.Provider)))
+		}
+	}
+
+	return allErrs
+}
+
+// validateSecretValue checks that a sensitive field is either inlined or referenced, not both.
+func validateSecretValue(path *field.Path, name, inline string, from *SecretValueSource) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if from == nil {
+		return allErrs
+	}
+
+	fromPath := path.Child(name + "From")
+
+	if inline != "" {
+		allErrs = append(allErrs, field.Forbidden(path.Child(name),
+			fmt.Sprintf("must not be set together with %s", fromPath.String())))
+	}
+
+	ref := from.SecretKeyRef
+	refPath := fromPath.Child("secretKeyRef")
+
+	switch {
+	case ref == nil:
+		allErrs = append(allErrs, field.Required(refPath, ""))
+	case ref.Name == "":
+		allErrs = append(allErrs, field.Required(refPath.Child("name"), ""))
+	case ref.Key == "":
+		allErrs = append(allErrs, field.Required(refPath.Child("key"), ""))
+	}
+
+	return allErrs
+}
+
+func (cp *ControlPlane) isNatsEnabled() bool {
+	return cp.Spec.Nats == nil || cp.Spec.Nats.Enabled == nil || *cp.Spec.Nats.Enabled
+}
+
+func (cp *ControlPlane) natsReplicas() int32 {
+	if cp.Spec.Replicas.Nats == 0 {
+		return DefaultNatsReplicas
+	}
+
+	return cp.Spec.Replicas.Nats
+}
+
+func (cp *ControlPlane) jetStream() NatsJetStream {
+	if cp.Spec.Nats == nil {
+		return NatsJetStream{}
+	}
+
+	return cp.Spec.Nats.JetStream
+}
+
+func sameQuantity(a, b string) bool {
+	qa, errA := resource.ParseQuantity(a)
+	qb, errB := resource.ParseQuantity(b)
+
+	if errA != nil || errB != nil {
+		return a == b
+	}
+
+	return qa.Cmp(qb) == 0
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+
+	return false
+}
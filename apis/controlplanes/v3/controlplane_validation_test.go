@@ -0,0 +1,353 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v3
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func newValidControlPlane() *ControlPlane {
+	cp := &ControlPlane{ObjectMeta: metav1.ObjectMeta{Name: "pot", Namespace: "iofog"}}
+	cp.Spec.Database.Provider = "sqlite"
+	cp.Spec.Nats = &Nats{JetStream: NatsJetStream{StorageClassName: "standard"}}
+	cp.Spec.Certificates = &Certificates{CARotation: &CARotation{Generation: 2}}
+	cp.Default()
+
+	return cp
+}
+
+// hasError reports whether allErrs holds an error of errType for path
+func hasError(allErrs field.ErrorList, errType field.ErrorType, path string) bool {
+	for _, err := range allErrs {
+		if err.Type == errType && err.Field == path {
+			return true
+		}
+	}
+
+	return false
+}
+
+func TestValidateSpec(t *testing.T) {
+	for name, tc := range map[string]struct {
+		mutate  func(*ControlPlane)
+		errType field.ErrorType
+		path    string
+	}{
+		"valid": {
+			mutate: func(*ControlPlane) {},
+		},
+		"external database with controller replicas": {
+			mutate: func(cp *ControlPlane) {
+				cp.Spec.Database.Provider = "postgres"
+				cp.Spec.Database.Host = "postgres.iofog"
+				cp.Spec.Replicas.Controller = 3
+			},
+		},
+		"sqlite with controller replicas": {
+			mutate:  func(cp *ControlPlane) { cp.Spec.Replicas.Controller = 2 },
+			errType: field.ErrorTypeForbidden,
+			path:    "spec.replicas.controller",
+		},
+		"cluster ip controller without ingress host": {
+			mutate:  func(cp *ControlPlane) { cp.Spec.Services.Controller.Type = "ClusterIP" },
+			errType: field.ErrorTypeRequired,
+			path:    "spec.ingresses.controller.host",
+		},
+		"cluster ip controller with ingress host": {
+			mutate: func(cp *ControlPlane) {
+				cp.Spec.Services.Controller.Type = "ClusterIP"
+				cp.Spec.Ingresses.Controller.Host = "controller.iofog.example"
+			},
+		},
+		"node port router without address": {
+			mutate:  func(cp *ControlPlane) { cp.Spec.Services.Router.Type = "NodePort" },
+			errType: field.ErrorTypeRequired,
+			path:    "spec.ingresses.router.address",
+		},
+		"node port router with address": {
+			mutate: func(cp *ControlPlane) {
+				cp.Spec.Services.Router.Type = "NodePort"
+				cp.Spec.Ingresses.Router.Address = "router.iofog.example"
+			},
+		},
+		"vault block of another provider": {
+			mutate: func(cp *ControlPlane) {
+				cp.Spec.Vault = &Vault{Provider: "aws", Hashicorp: &VaultHashicorp{Address: "https://vault.iofog.example"}}
+			},
+			errType: field.ErrorTypeRequired,
+			path:    "spec.vault.aws",
+		},
+		"https without secret": {
+			mutate:  func(cp *ControlPlane) { cp.Spec.Controller.Https = newBool(true) },
+			errType: field.ErrorTypeRequired,
+			path:    "spec.controller.secretName",
+		},
+		"negative progress deadline": {
+			mutate:  func(cp *ControlPlane) { cp.Spec.ProgressDeadline = &metav1.Duration{Duration: -time.Minute} },
+			errType: field.ErrorTypeInvalid,
+			path:    "spec.progressDeadline",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			cp := newValidControlPlane()
+			tc.mutate(cp)
+
+			allErrs := cp.validateSpec()
+
+			if tc.path == "" {
+				if len(allErrs) != 0 {
+					t.Fatalf("expected no errors, got %v", allErrs)
+				}
+
+				return
+			}
+
+			if !hasError(allErrs, tc.errType, tc.path) {
+				t.Errorf("expected %s error for %s, got %v", tc.errType, tc.path, allErrs)
+			}
+		})
+	}
+}
+
+func TestValidateVault(t *testing.T) {
+	path := field.NewPath("spec", "vault")
+	hashicorp := &VaultHashicorp{Address: "https://vault.iofog.example"}
+
+	for name, tc := range map[string]struct {
+		vault *Vault
+		want  map[string]field.ErrorType
+	}{
+		"matching block": {
+			vault: &Vault{Provider: "hashicorp", Hashicorp: hashicorp},
+		},
+		"provider alias": {
+			vault: &Vault{Provider: "openbao", Hashicorp: hashicorp},
+		},
+		"block without provider": {
+			vault: &Vault{Hashicorp: hashicorp},
+			want:  map[string]field.ErrorType{"spec.vault.provider": field.ErrorTypeRequired},
+		},
+		"unknown provider": {
+			vault: &Vault{Provider: "keepass", Hashicorp: hashicorp},
+			want:  map[string]field.ErrorType{"spec.vault.provider": field.ErrorTypeNotSupported},
+		},
+		"missing block": {
+			vault: &Vault{Provider: "google"},
+			want:  map[string]field.ErrorType{"spec.vault.google": field.ErrorTypeRequired},
+		},
+		"block of another provider": {
+			vault: &Vault{Provider: "aws", Hashicorp: hashicorp},
+			want: map[string]field.ErrorType{
+				"spec.vault.aws":       field.ErrorTypeRequired,
+				"spec.vault.hashicorp": field.ErrorTypeForbidden,
+			},
+		},
+		"both blocks": {
+			vault: &Vault{Provider: "aws", Aws: &VaultAws{Region: "eu-west-1"}, Hashicorp: hashicorp},
+			want:  map[string]field.ErrorType{"spec.vault.hashicorp": field.ErrorTypeForbidden},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			allErrs := validateVault(path, tc.vault)
+
+			if len(allErrs) != len(tc.want) {
+				t.Fatalf("expected %d errors, got %v", len(tc.want), allErrs)
+			}
+
+			for errPath, errType := range tc.want {
+				if !hasError(allErrs, errType, errPath) {
+					t.Errorf("expected %s error for %s, got %v", errType, errPath, allErrs)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateCertificates(t *testing.T) {
+	path := field.NewPath("spec", "certificates")
+
+	for name, tc := range map[string]struct {
+		certs   *Certificates
+		errType field.ErrorType
+		path    string
+	}{
+		"operator signed": {
+			certs: &Certificates{RenewBefore: &metav1.Duration{Duration: DefaultCertificateRenewBefore}},
+		},
+		"issuer": {
+			certs: &Certificates{IssuerRef: &IssuerRef{Name: "ca"}, RenewBefore: &metav1.Duration{Duration: DefaultCertificateRenewBefore}},
+		},
+		"issuer without name": {
+			certs:   &Certificates{IssuerRef: &IssuerRef{}},
+			errType: field.ErrorTypeRequired,
+			path:    "spec.certificates.issuerRef.name",
+		},
+		"issuer with cas": {
+			certs:   &Certificates{IssuerRef: &IssuerRef{Name: "ca"}, CAs: &CASecrets{RouterSite: "site-ca"}},
+			errType: field.ErrorTypeForbidden,
+			path:    "spec.certificates.cas",
+		},
+		"renew before not positive": {
+			certs:   &Certificates{RenewBefore: &metav1.Duration{}},
+			errType: field.ErrorTypeInvalid,
+			path:    "spec.certificates.renewBefore",
+		},
+		"renew before operator validity": {
+			certs:   &Certificates{RenewBefore: &metav1.Duration{Duration: OperatorCertificateValidity}},
+			errType: field.ErrorTypeInvalid,
+			path:    "spec.certificates.renewBefore",
+		},
+		"renew before issuer duration": {
+			certs:   &Certificates{IssuerRef: &IssuerRef{Name: "ca"}, RenewBefore: &metav1.Duration{Duration: IssuerCertificateDuration}},
+			errType: field.ErrorTypeInvalid,
+			path:    "spec.certificates.renewBefore",
+		},
+		"grace period not positive": {
+			certs:   &Certificates{CARotation: &CARotation{GracePeriod: &metav1.Duration{Duration: -time.Hour}}},
+			errType: field.ErrorTypeInvalid,
+			path:    "spec.certificates.caRotation.gracePeriod",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			allErrs := validateCertificates(path, tc.certs)
+
+			if tc.path == "" {
+				if len(allErrs) != 0 {
+					t.Fatalf("expected no errors, got %v", allErrs)
+				}
+
+				return
+			}
+
+			if !hasError(allErrs, tc.errType, tc.path) {
+				t.Errorf("expected %s error for %s, got %v", tc.errType, tc.path, allErrs)
+			}
+		})
+	}
+}
+
+func TestValidateUpdate(t *testing.T) {
+	for name, tc := range map[string]struct {
+		mutate func(*ControlPlane)
+		path   string
+	}{
+		"allowed change": {
+			mutate: func(cp *ControlPlane) { cp.Spec.Controller.LogLevel = "DEBUG" },
+		},
+		"scale up nats": {
+			mutate: func(cp *ControlPlane) { cp.Spec.Replicas.Nats = 5 },
+		},
+		"database provider": {
+			mutate: func(cp *ControlPlane) { cp.Spec.Database.Provider = "mysql" },
+			path:   "spec.database.provider",
+		},
+		"external database": {
+			mutate: func(cp *ControlPlane) { cp.Spec.Database.Host = "sqlite.iofog" },
+			path:   "spec.database.host",
+		},
+		"scale down nats": {
+			mutate: func(cp *ControlPlane) { cp.Spec.Replicas.Nats = 1 },
+			path:   "spec.replicas.nats",
+		},
+		"jetstream storage class": {
+			mutate: func(cp *ControlPlane) { cp.Spec.Nats.JetStream.StorageClassName = "fast" },
+			path:   "spec.nats.jetStream.storageClassName",
+		},
+		"jetstream storage size": {
+			mutate: func(cp *ControlPlane) { cp.Spec.Nats.JetStream.StorageSize = "50Gi" },
+			path:   "spec.nats.jetStream.storageSize",
+		},
+		"ca rotation generation": {
+			mutate: func(cp *ControlPlane) { cp.Spec.Certificates.CARotation.Generation = 1 },
+			path:   "spec.certificates.caRotation.generation",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			old := newValidControlPlane()
+			cp := old.DeepCopy()
+			tc.mutate(cp)
+
+			_, err := cp.validateUpdate(old)
+
+			if tc.path == "" {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+
+				return
+			}
+
+			var statusErr *apierrors.StatusError
+			if !apierrors.IsInvalid(err) || !errors.As(err, &statusErr) {
+				t.Fatalf("expected an invalid error, got %v", err)
+			}
+
+			found := false
+			for _, cause := range statusErr.ErrStatus.Details.Causes {
+				found = found || cause.Field == tc.path
+			}
+
+			if !found {
+				t.Errorf("expected an error for %s, got %v", tc.path, err)
+			}
+		})
+	}
+}
+
+func TestValidateUpdateMetadataOnly(t *testing.T) {
+	// Stored before the webhook existed, the spec would be rejected on create
+	old := newValidControlPlane()
+	old.Spec.Replicas.Controller = 2
+
+	if len(old.validateSpec()) == 0 {
+		t.Fatal("expected the stored spec to be invalid")
+	}
+
+	t.Run("finalizer", func(t *testing.T) {
+		cp := old.DeepCopy()
+		cp.Finalizers = append(cp.Finalizers, "datasance.com/finalizer")
+
+		if _, err := cp.validateUpdate(old); err != nil {
+			t.Errorf("expected a metadata change to be allowed, got %v", err)
+		}
+	})
+
+	t.Run("deleting", func(t *testing.T) {
+		cp := old.DeepCopy()
+		cp.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+		cp.Spec.Controller.LogLevel = "DEBUG"
+
+		if _, err := cp.validateUpdate(old); err != nil {
+			t.Errorf("expected a deleting ControlPlane to be allowed, got %v", err)
+		}
+	})
+
+	t.Run("spec change", func(t *testing.T) {
+		cp := old.DeepCopy()
+		cp.Spec.Controller.LogLevel = "DEBUG"
+
+		if _, err := cp.validateUpdate(old); err == nil {
+			t.Error("expected a spec change to be validated")
+		}
+	})
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v3
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// controlplanelog is for logging in this package.
+var controlplanelog = logf.Log.WithName("controlplane-resource") //nolint:gochecknoglobals
+
+// SetupWebhookWithManager registers the ControlPlane admission webhooks with the manager.
+func (cp *ControlPlane) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(cp).
+		WithDefaulter(&controlPlaneDefaulter{}).
+		WithValidator(&controlPlaneValidator{}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-datasance-com-v3-controlplane,mutating=true,failurePolicy=fail,sideEffects=None,groups=datasance.com,resources=controlplanes,verbs=create;update,versions=v3,name=mcontrolplane.datasance.com,admissionReviewVersions=v1
+
+// controlPlaneDefaulter writes the defaults the operator deploys with into the stored spec.
+type controlPlaneDefaulter struct{}
+
+var _ webhook.CustomDefaulter = &controlPlaneDefaulter{}
+
+// Default implements webhook.CustomDefaulter.
+func (d *controlPlaneDefaulter) Default(_ context.Context, obj runtime.Object) error {
+	cp, err := toControlPlane(obj)
+	if err != nil {
+		return err
+	}
+
+	controlplanelog.Info("default", "name", cp.Name)
+	cp.Default()
+
+	return nil
+}
+
+// +kubebuilder:webhook:path=/validate-datasance-com-v3-controlplane,mutating=false,failurePolicy=fail,sideEffects=None,groups=datasance.com,resources=controlplanes,verbs=create;update,versions=v3,name=vcontrolplane.datasance.com,admissionReviewVersions=v1
+
+// controlPlaneValidator rejects ControlPlane specs that the reconciler could never bring to Ready.
+type controlPlaneValidator struct{}
+
+var _ webhook.CustomValidator = &controlPlaneValidator{}
+
+// ValidateCreate implements webhook.CustomValidator.
+func (v *controlPlaneValidator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	cp, err := toControlPlane(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	controlplanelog.Info("validate create", "name", cp.Name)
+
+	return cp.validateCreate()
+}
+
+// ValidateUpdate implements webhook.CustomValidator.
+func (v *controlPlaneValidator) ValidateUpdate(_ context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldCP, err := toControlPlane(oldObj)
+	if err != nil {
+		return nil, err
+	}
+
+	cp, err := toControlPlane(newObj)
+	if err != nil {
+		return nil, err
+	}
+
+	controlplanelog.Info("validate update", "name", cp.Name)
+
+	return cp.validateUpdate(oldCP)
+}
+
+// ValidateDelete implements webhook.CustomValidator.
+func (v *controlPlaneValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func toControlPlane(obj runtime.Object) (*ControlPlane, error) {
+	cp, ok := obj.(*ControlPlane)
+	if !ok {
+		return nil, fmt.Errorf("expected a ControlPlane but got a %T", obj)
+	}
+
+	return cp, nil
+}
@@ -0,0 +1,443 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v4
+
+import (
+	"fmt"
+
+	cpv3 "github.com/datasance/iofog-operator/v3/apis/controlplanes/v3"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+)
+
+var _ conversion.Convertible = &ControlPlane{}
+
+// ConvertTo converts this ControlPlane to the Hub version (v3).
+func (cp *ControlPlane) ConvertTo(dstRaw conversion.Hub) error {
+	dst, ok := dstRaw.(*cpv3.ControlPlane)
+	if !ok {
+		return fmt.Errorf("expected a v3 ControlPlane but got a %T", dstRaw)
+	}
+
+	dst.ObjectMeta = cp.ObjectMeta
+	dst.Status.Conditions = cp.Status.Conditions
+	dst.Status.NextCertificateExpiry = cp.Status.NextCertificateExpiry
+	dst.Status.CARotation = (*cpv3.CARotationStatus)(cp.Status.CARotation)
+	dst.Status.ImportedCAs = cp.Status.ImportedCAs
+	dst.Status.Endpoints = (*cpv3.EndpointsStatus)(cp.Status.Endpoints)
+	dst.Status.ControllerVersion = cp.Status.ControllerVersion
+	dst.Status.Replicas = (*cpv3.ReplicasStatus)(cp.Status.Replicas)
+	dst.Status.LastError = cp.Status.LastError
+
+	src := &cp.Spec
+	spec := &dst.Spec
+
+	spec.Auth = convertAuthTo(src.Auth)
+	spec.Database = convertDatabaseTo(src.Database)
+	spec.Events = cpv3.Events(src.Events)
+	spec.Vault = convertVaultTo(src.Vault)
+	spec.Gateway = convertGatewayTo(src)
+	spec.Certificates = convertCertificatesTo(src.Certificates)
+	spec.DeletionPolicy = (*cpv3.DeletionPolicy)(src.DeletionPolicy)
+	spec.ProgressDeadline = src.ProgressDeadline
+
+	spec.Images = cpv3.Images{
+		PullSecret: src.ImagePullSecret,
+		Controller: src.Controller.Image,
+		Router:     src.Router.Image,
+		Nats:       src.Nats.Image,
+	}
+	spec.Replicas = cpv3.Replicas{
+		Controller: src.Controller.Replicas,
+		Nats:       src.Nats.Replicas,
+	}
+	spec.Services = cpv3.Services{
+		Controller: cpv3.Service(src.Controller.Service),
+		Router:     cpv3.Service(src.Router.Service),
+		Nats:       cpv3.Service(src.Nats.Service),
+		NatsServer: cpv3.Service(src.Nats.ServerService),
+	}
+	spec.Ingresses = cpv3.Ingresses{
+		Controller: cpv3.ControllerIngress(src.Controller.Ingress),
+		Router:     cpv3.RouterIngress(src.Router.Ingress),
+		Nats:       cpv3.NatsIngress(src.Nats.Ingress),
+	}
+	spec.Resources = cpv3.Resources{
+		Controller: src.Controller.Resources,
+		Router:     src.Router.Resources,
+		Nats:       src.Nats.Resources,
+	}
+	spec.Scheduling = cpv3.Scheduling{
+		Controller: cpv3.PodScheduling(src.Controller.Scheduling),
+		Router:     cpv3.PodScheduling(src.Router.Scheduling),
+		Nats:       cpv3.PodScheduling(src.Nats.Scheduling),
+	}
+	spec.PodDisruptionBudgets = cpv3.PodDisruptionBudgets{
+		Controller: cpv3.PodDisruptionBudget(src.Controller.PodDisruptionBudget),
+		Router:     cpv3.PodDisruptionBudget(src.Router.PodDisruptionBudget),
+		Nats:       cpv3.PodDisruptionBudget(src.Nats.PodDisruptionBudget),
+	}
+	spec.Router = cpv3.Router{
+		HA:    src.Router.HA,
+		Ports: cpv3.RouterPorts(src.Router.Ports),
+	}
+	spec.Controller = cpv3.Controller{
+		PidBaseDir:    src.Controller.PidBaseDir,
+		EcnViewerPort: src.Controller.EcnViewerPort,
+		EcnViewerURL:  src.Controller.EcnViewerURL,
+		ECNName:       src.Controller.ECNName,
+		Https:         src.Controller.Https,
+		SecretName:    src.Controller.SecretName,
+		LogLevel:      src.Controller.LogLevel,
+	}
+
+	// v3 only has a nats block for the enabled flag, JetStream and the ports
+	spec.Nats = nil
+	if src.Nats.Enabled != nil || src.Nats.JetStream != nil || src.Nats.Ports != (NatsPorts{}) {
+		spec.Nats = &cpv3.Nats{Enabled: src.Nats.Enabled, Ports: cpv3.NatsPorts(src.Nats.Ports)}
+		if src.Nats.JetStream != nil {
+			spec.Nats.JetStream = cpv3.NatsJetStream(*src.Nats.JetStream)
+		}
+	}
+
+	return nil
+}
+
+// ConvertFrom converts from the Hub version (v3) to this version.
+func (cp *ControlPlane) ConvertFrom(srcRaw conversion.Hub) error {
+	src, ok := srcRaw.(*cpv3.ControlPlane)
+	if !ok {
+		return fmt.Errorf("expected a v3 ControlPlane but got a %T", srcRaw)
+	}
+
+	cp.ObjectMeta = src.ObjectMeta
+	cp.Status.Conditions = src.Status.Conditions
+	cp.Status.NextCertificateExpiry = src.Status.NextCertificateExpiry
+	cp.Status.CARotation = (*CARotationStatus)(src.Status.CARotation)
+	cp.Status.ImportedCAs = src.Status.ImportedCAs
+	cp.Status.Endpoints = (*EndpointsStatus)(src.Status.Endpoints)
+	cp.Status.ControllerVersion = src.Status.ControllerVersion
+	cp.Status.Replicas = (*ReplicasStatus)(src.Status.Replicas)
+	cp.Status.LastError = src.Status.LastError
+
+	spec := &src.Spec
+	dst := &cp.Spec
+
+	dst.Auth = convertAuthFrom(spec.Auth)
+	dst.Database = convertDatabaseFrom(spec.Database)
+	dst.Events = Events(spec.Events)
+	dst.Vault = convertVaultFrom(spec.Vault)
+	dst.Certificates = convertCertificatesFrom(spec.Certificates)
+	dst.DeletionPolicy = (*DeletionPolicy)(spec.DeletionPolicy)
+	dst.ProgressDeadline = spec.ProgressDeadline
+	dst.ImagePullSecret = spec.Images.PullSecret
+
+	dst.Controller = Controller{
+		Replicas:            spec.Replicas.Controller,
+		Image:               spec.Images.Controller,
+		Service:             Service(spec.Services.Controller),
+		Ingress:             ControllerIngress(spec.Ingresses.Controller),
+		Resources:           spec.Resources.Controller,
+		Scheduling:          PodScheduling(spec.Scheduling.Controller),
+		PodDisruptionBudget: PodDisruptionBudget(spec.PodDisruptionBudgets.Controller),
+		PidBaseDir:          spec.Controller.PidBaseDir,
+		EcnViewerPort:       spec.Controller.EcnViewerPort,
+		EcnViewerURL:        spec.Controller.EcnViewerURL,
+		ECNName:             spec.Controller.ECNName,
+		Https:               spec.Controller.Https,
+		SecretName:          spec.Controller.SecretName,
+		LogLevel:            spec.Controller.LogLevel,
+	}
+	dst.Router = Router{
+		Image:               spec.Images.Router,
+		Service:             Service(spec.Services.Router),
+		Ingress:             RouterIngress(spec.Ingresses.Router),
+		HA:                  spec.Router.HA,
+		Ports:               RouterPorts(spec.Router.Ports),
+		Resources:           spec.Resources.Router,
+		Scheduling:          PodScheduling(spec.Scheduling.Router),
+		PodDisruptionBudget: PodDisruptionBudget(spec.PodDisruptionBudgets.Router),
+	}
+	dst.Nats = Nats{
+		Replicas:            spec.Replicas.Nats,
+		Image:               spec.Images.Nats,
+		Service:             Service(spec.Services.Nats),
+		ServerService:       Service(spec.Services.NatsServer),
+		Ingress:             NatsIngress(spec.Ingresses.Nats),
+		Resources:           spec.Resources.Nats,
+		Scheduling:          PodScheduling(spec.Scheduling.Nats),
+		PodDisruptionBudget: PodDisruptionBudget(spec.PodDisruptionBudgets.Nats),
+	}
+
+	if spec.Nats != nil {
+		dst.Nats.Enabled = spec.Nats.Enabled
+		dst.Nats.Ports = NatsPorts(spec.Nats.Ports)
+		// An empty v3 jetStream block is the same as none
+		if spec.Nats.JetStream != (cpv3.NatsJetStream{}) {
+			jetStream := NatsJetStream(spec.Nats.JetStream)
+			dst.Nats.JetStream = &jetStream
+		}
+	}
+
+	// The routes live in the component blocks, which are complete by now
+	convertGatewayFrom(spec.Gateway, dst)
+
+	return nil
+}
+
+func convertCertificatesTo(src *Certificates) *cpv3.Certificates {
+	if src == nil {
+		return nil
+	}
+
+	return &cpv3.Certificates{
+		IssuerRef:   (*cpv3.IssuerRef)(src.IssuerRef),
+		RenewBefore: src.RenewBefore,
+		CARotation:  (*cpv3.CARotation)(src.CARotation),
+		CAs:         (*cpv3.CASecrets)(src.CAs),
+	}
+}
+
+func convertCertificatesFrom(src *cpv3.Certificates) *Certificates {
+	if src == nil {
+		return nil
+	}
+
+	return &Certificates{
+		IssuerRef:   (*IssuerRef)(src.IssuerRef),
+		RenewBefore: src.RenewBefore,
+		CARotation:  (*CARotation)(src.CARotation),
+		CAs:         (*CASecrets)(src.CAs),
+	}
+}
+
+// convertGatewayTo gathers the routes of the components under the v3 gateway block
+func convertGatewayTo(src *ControlPlaneSpec) *cpv3.Gateway {
+	if src.Gateway == nil && src.Controller.Gateway == nil && src.Router.Gateway == nil && src.Nats.Gateway == nil {
+		return nil
+	}
+
+	dst := &cpv3.Gateway{}
+	if src.Gateway != nil {
+		dst.Name = src.Gateway.Name
+		dst.Namespace = src.Gateway.Namespace
+	}
+	if src.Controller.Gateway != nil {
+		route := cpv3.GatewayHTTPRoute(*src.Controller.Gateway)
+		dst.Controller = &route
+	}
+	if src.Router.Gateway != nil {
+		routes := cpv3.GatewayRouterRoutes(*src.Router.Gateway)
+		dst.Router = &routes
+	}
+	if src.Nats.Gateway != nil {
+		routes := cpv3.GatewayNatsRoutes(*src.Nats.Gateway)
+		dst.Nats = &routes
+	}
+
+	return dst
+}
+
+func convertGatewayFrom(src *cpv3.Gateway, dst *ControlPlaneSpec) {
+	if src == nil {
+		return
+	}
+
+	dst.Gateway = &Gateway{
+		Name:      src.Name,
+		Namespace: src.Namespace,
+	}
+	if src.Controller != nil {
+		route := GatewayHTTPRoute(*src.Controller)
+		dst.Controller.Gateway = &route
+	}
+	if src.Router != nil {
+		routes := GatewayRouterRoutes(*src.Router)
+		dst.Router.Gateway = &routes
+	}
+	if src.Nats != nil {
+		routes := GatewayNatsRoutes(*src.Nats)
+		dst.Nats.Gateway = &routes
+	}
+}
+
+func convertAuthTo(src Auth) cpv3.Auth {
+	return cpv3.Auth{
+		URL:                  src.URL,
+		Realm:                src.Realm,
+		SSL:                  src.SSL,
+		RealmKey:             src.RealmKey,
+		ControllerClient:     src.ControllerClient,
+		ControllerSecret:     src.ControllerSecret,
+		ControllerSecretFrom: convertSecretValueSourceTo(src.ControllerSecretFrom),
+		ViewerClient:         src.ViewerClient,
+	}
+}
+
+func convertAuthFrom(src cpv3.Auth) Auth {
+	return Auth{
+		URL:                  src.URL,
+		Realm:                src.Realm,
+		SSL:                  src.SSL,
+		RealmKey:             src.RealmKey,
+		ControllerClient:     src.ControllerClient,
+		ControllerSecret:     src.ControllerSecret,
+		ControllerSecretFrom: convertSecretValueSourceFrom(src.ControllerSecretFrom),
+		ViewerClient:         src.ViewerClient,
+	}
+}
+
+func convertDatabaseTo(src Database) cpv3.Database {
+	return cpv3.Database{
+		Provider:     src.Provider,
+		Host:         src.Host,
+		Port:         src.Port,
+		User:         src.User,
+		Password:     src.Password,
+		PasswordFrom: convertSecretValueSourceTo(src.PasswordFrom),
+		DatabaseName: src.DatabaseName,
+		SSL:          src.SSL,
+		CA:           src.CA,
+	}
+}
+
+func convertDatabaseFrom(src cpv3.Database) Database {
+	return Database{
+		Provider:     src.Provider,
+		Host:         src.Host,
+		Port:         src.Port,
+		User:         src.User,
+		Password:     src.Password,
+		PasswordFrom: convertSecretValueSourceFrom(src.PasswordFrom),
+		DatabaseName: src.DatabaseName,
+		SSL:          src.SSL,
+		CA:           src.CA,
+	}
+}
+
+func convertSecretValueSourceTo(src *SecretValueSource) *cpv3.SecretValueSource {
+	if src == nil {
+		return nil
+	}
+
+	return &cpv3.SecretValueSource{SecretKeyRef: src.SecretKeyRef}
+}
+
+func convertSecretValueSourceFrom(src *cpv3.SecretValueSource) *SecretValueSource {
+	if src == nil {
+		return nil
+	}
+
+	return &SecretValueSource{SecretKeyRef: src.SecretKeyRef}
+}
+
+func convertVaultTo(src *Vault) *cpv3.Vault {
+	if src == nil {
+		return nil
+	}
+
+	dst := &cpv3.Vault{
+		Enabled:  src.Enabled,
+		Provider: src.Provider,
+		BasePath: src.BasePath,
+	}
+	if src.Hashicorp != nil {
+		dst.Hashicorp = &cpv3.VaultHashicorp{
+			Address:   src.Hashicorp.Address,
+			Token:     src.Hashicorp.Token,
+			TokenFrom: convertSecretValueSourceTo(src.Hashicorp.TokenFrom),
+			Mount:     src.Hashicorp.Mount,
+		}
+	}
+
+	if src.Aws != nil {
+		dst.Aws = &cpv3.VaultAws{
+			Region:        src.Aws.Region,
+			AccessKeyId:   src.Aws.AccessKeyId,
+			AccessKey:     src.Aws.AccessKey,
+			AccessKeyFrom: convertSecretValueSourceTo(src.Aws.AccessKeyFrom),
+		}
+	}
+
+	if src.Azure != nil {
+		dst.Azure = &cpv3.VaultAzure{
+			URL:              src.Azure.URL,
+			TenantId:         src.Azure.TenantId,
+			ClientId:         src.Azure.ClientId,
+			ClientSecret:     src.Azure.ClientSecret,
+			ClientSecretFrom: convertSecretValueSourceTo(src.Azure.ClientSecretFrom),
+		}
+	}
+
+	if src.Google != nil {
+		dst.Google = &cpv3.VaultGoogle{
+			ProjectId:       src.Google.ProjectId,
+			Credentials:     src.Google.Credentials,
+			CredentialsFrom: convertSecretValueSourceTo(src.Google.CredentialsFrom),
+		}
+	}
+
+	return dst
+}
+
+func convertVaultFrom(src *cpv3.Vault) *Vault {
+	if src == nil {
+		return nil
+	}
+
+	dst := &Vault{
+		Enabled:  src.Enabled,
+		Provider: src.Provider,
+		BasePath: src.BasePath,
+	}
+	if src.Hashicorp != nil {
+		dst.Hashicorp = &VaultHashicorp{
+			Address:   src.Hashicorp.Address,
+			Token:     src.Hashicorp.Token,
+			TokenFrom: convertSecretValueSourceFrom(src.Hashicorp.TokenFrom),
+			Mount:     src.Hashicorp.Mount,
+		}
+	}
+
+	if src.Aws != nil {
+		dst.Aws = &VaultAws{
+			Region:        src.Aws.Region,
+			AccessKeyId:   src.Aws.AccessKeyId,
+			AccessKey:     src.Aws.AccessKey,
+			AccessKeyFrom: convertSecretValueSourceFrom(src.Aws.AccessKeyFrom),
+		}
+	}
+
+	if src.Azure != nil {
+		dst.Azure = &VaultAzure{
+			URL:              src.Azure.URL,
+			TenantId:         src.Azure.TenantId,
+			ClientId:         src.Azure.ClientId,
+			ClientSecret:     src.Azure.ClientSecret,
+			ClientSecretFrom: convertSecretValueSourceFrom(src.Azure.ClientSecretFrom),
+		}
+	}
+
+	if src.Google != nil {
+		dst.Google = &VaultGoogle{
+			ProjectId:       src.Google.ProjectId,
+			Credentials:     src.Google.Credentials,
+			CredentialsFrom: convertSecretValueSourceFrom(src.Google.CredentialsFrom),
+		}
+	}
+
+	return dst
+}
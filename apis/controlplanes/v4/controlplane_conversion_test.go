@@ -0,0 +1,445 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v4
+
+import (
+	"testing"
+	"time"
+
+	cpv3 "github.com/datasance/iofog-operator/v3/apis/controlplanes/v3"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestConvertRoundTripFromHub(t *testing.T) {
+	for name, src := range map[string]*cpv3.ControlPlane{
+		"full":    fullHubControlPlane(),
+		"minimal": {ObjectMeta: metav1.ObjectMeta{Name: "pot", Namespace: "iofog"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			spoke := &ControlPlane{}
+			if err := spoke.ConvertFrom(src.DeepCopy()); err != nil {
+				t.Fatalf("convert from v3: %v", err)
+			}
+
+			hub := &cpv3.ControlPlane{}
+			if err := spoke.ConvertTo(hub); err != nil {
+				t.Fatalf("convert to v3: %v", err)
+			}
+
+			if diff := cmp.Diff(src, hub); diff != "" {
+				t.Errorf("v3 -> v4 -> v3 changed the ControlPlane (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestConvertRoundTripToHub(t *testing.T) {
+	natsOnly := &ControlPlane{ObjectMeta: metav1.ObjectMeta{Name: "pot", Namespace: "iofog"}}
+	natsOnly.Spec.Nats.Enabled = boolPtr(false)
+
+	for name, src := range map[string]*ControlPlane{
+		"full":    fullControlPlane(),
+		"minimal": {ObjectMeta: metav1.ObjectMeta{Name: "pot", Namespace: "iofog"}},
+		"nats":    natsOnly,
+	} {
+		t.Run(name, func(t *testing.T) {
+			hub := &cpv3.ControlPlane{}
+			if err := src.DeepCopy().ConvertTo(hub); err != nil {
+				t.Fatalf("convert to v3: %v", err)
+			}
+
+			spoke := &ControlPlane{}
+			if err := spoke.ConvertFrom(hub); err != nil {
+				t.Fatalf("convert from v3: %v", err)
+			}
+
+			if diff := cmp.Diff(src, spoke); diff != "" {
+				t.Errorf("v4 -> v3 -> v4 changed the ControlPlane (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func fullHubControlPlane() *cpv3.ControlPlane {
+	return &cpv3.ControlPlane{
+		ObjectMeta: testObjectMeta(),
+		Spec: cpv3.ControlPlaneSpec{
+			Auth: cpv3.Auth{
+				URL:                  "https://auth.example.com",
+				Realm:                "pot",
+				SSL:                  "external",
+				RealmKey:             "realm-key",
+				ControllerClient:     "controller",
+				ControllerSecretFrom: &cpv3.SecretValueSource{SecretKeyRef: testSecretKeyRef("auth")},
+				ViewerClient:         "viewer",
+			},
+			Database: cpv3.Database{
+				Provider:     "postgres",
+				Host:         "db.example.com",
+				Port:         5432,
+				User:         "iofog",
+				Password:     "secret",
+				PasswordFrom: &cpv3.SecretValueSource{SecretKeyRef: testSecretKeyRef("db")},
+				DatabaseName: "iofog",
+				SSL:          boolPtr(true),
+				CA:           stringPtr("ca"),
+			},
+			Ingresses: cpv3.Ingresses{
+				Controller: cpv3.ControllerIngress{
+					Annotations:      map[string]string{"a": "b"},
+					IngressClassName: "nginx",
+					Host:             "controller.example.com",
+					SecretName:       "controller-tls",
+				},
+				Router: cpv3.RouterIngress{Address: "router.example.com", MessagePort: 1, InteriorPort: 2, EdgePort: 3},
+				Nats: cpv3.NatsIngress{
+					Address:     "nats.example.com",
+					ServerPort:  1,
+					ClusterPort: 2,
+					LeafPort:    3,
+					MqttPort:    4,
+					HttpPort:    5,
+				},
+			},
+			Services: cpv3.Services{
+				Controller: cpv3.Service(testService("controller")),
+				Router:     cpv3.Service(testService("router")),
+				Nats:       cpv3.Service(testService("nats")),
+				NatsServer: cpv3.Service(testService("nats-server")),
+			},
+			Replicas: cpv3.Replicas{Controller: 2, Nats: 3},
+			Images: cpv3.Images{
+				PullSecret: "pull",
+				Controller: "controller:1",
+				Router:     "router:1",
+				Nats:       "nats:1",
+			},
+			Resources: cpv3.Resources{
+				Controller: testResources("100m"),
+				Router:     testResources("200m"),
+				Nats:       testResources("300m"),
+			},
+			Scheduling: cpv3.Scheduling{
+				Controller: cpv3.PodScheduling(testScheduling("controller")),
+				Router:     cpv3.PodScheduling(testScheduling("router")),
+				Nats:       cpv3.PodScheduling(testScheduling("nats")),
+			},
+			PodDisruptionBudgets: cpv3.PodDisruptionBudgets{
+				Controller: cpv3.PodDisruptionBudget(testPodDisruptionBudget()),
+				Router:     cpv3.PodDisruptionBudget(testPodDisruptionBudget()),
+				Nats:       cpv3.PodDisruptionBudget(testPodDisruptionBudget()),
+			},
+			Controller: cpv3.Controller{
+				PidBaseDir:    "/run",
+				EcnViewerPort: 8008,
+				EcnViewerURL:  "https://viewer.example.com",
+				ECNName:       "pot",
+				Https:         boolPtr(true),
+				SecretName:    "controller-tls",
+				LogLevel:      "debug",
+			},
+			Router: cpv3.Router{
+				HA:    boolPtr(true),
+				Ports: cpv3.RouterPorts{MessagePort: 1, InteriorPort: 2, EdgePort: 3, HTTPPort: 4},
+			},
+			Events: cpv3.Events{
+				AuditEnabled:     boolPtr(true),
+				RetentionDays:    7,
+				CleanupInterval:  60,
+				CaptureIpAddress: boolPtr(false),
+			},
+			Nats: &cpv3.Nats{
+				Enabled:   boolPtr(true),
+				JetStream: cpv3.NatsJetStream{StorageSize: "10Gi", MemoryStoreSize: "1Gi", StorageClassName: "fast"},
+				Ports:     cpv3.NatsPorts{ServerPort: 1, ClusterPort: 2, LeafPort: 3, MqttPort: 4, HttpPort: 5},
+			},
+			Vault: &cpv3.Vault{
+				Enabled:  boolPtr(true),
+				Provider: "hashicorp",
+				BasePath: "pot/$namespace/secrets",
+				Hashicorp: &cpv3.VaultHashicorp{
+					Address:   "https://vault.example.com",
+					Token:     "token",
+					TokenFrom: &cpv3.SecretValueSource{SecretKeyRef: testSecretKeyRef("vault")},
+					Mount:     "secret",
+				},
+				Aws: &cpv3.VaultAws{
+					Region:        "eu-west-1",
+					AccessKeyId:   "id",
+					AccessKey:     "key",
+					AccessKeyFrom: &cpv3.SecretValueSource{SecretKeyRef: testSecretKeyRef("aws")},
+				},
+				Azure: &cpv3.VaultAzure{
+					URL:              "https://azure.example.com",
+					TenantId:         "tenant",
+					ClientId:         "client",
+					ClientSecret:     "secret",
+					ClientSecretFrom: &cpv3.SecretValueSource{SecretKeyRef: testSecretKeyRef("azure")},
+				},
+				Google: &cpv3.VaultGoogle{
+					ProjectId:       "project",
+					Credentials:     "{}",
+					CredentialsFrom: &cpv3.SecretValueSource{SecretKeyRef: testSecretKeyRef("google")},
+				},
+			},
+			Gateway: &cpv3.Gateway{
+				Name:       "gateway",
+				Namespace:  "gateways",
+				Controller: &cpv3.GatewayHTTPRoute{Hostnames: []string{"controller.example.com"}, Listener: "https"},
+				Router: &cpv3.GatewayRouterRoutes{
+					Kind:             "TLSRoute",
+					Hostnames:        []string{"router.example.com"},
+					EdgeListener:     "edge",
+					InteriorListener: "interior",
+				},
+				Nats: &cpv3.GatewayNatsRoutes{
+					Kind:         "TCPRoute",
+					LeafListener: "leaf",
+					MqttListener: "mqtt",
+				},
+			},
+			Certificates: &cpv3.Certificates{
+				IssuerRef:   &cpv3.IssuerRef{Name: "issuer", Kind: "ClusterIssuer", Group: "cert-manager.io"},
+				RenewBefore: &metav1.Duration{Duration: 24 * time.Hour},
+				CARotation:  &cpv3.CARotation{Generation: 2, GracePeriod: &metav1.Duration{Duration: time.Hour}},
+				CAs: &cpv3.CASecrets{
+					RouterSite:  "router-site",
+					RouterLocal: "router-local",
+					NatsSite:    "nats-site",
+					NatsLocal:   "nats-local",
+				},
+			},
+			DeletionPolicy:   &cpv3.DeletionPolicy{PersistentVolumeClaims: "Retain", ExternalState: "Delete"},
+			ProgressDeadline: &metav1.Duration{Duration: 15 * time.Minute},
+		},
+		Status: cpv3.ControlPlaneStatus{
+			Conditions:            testConditions(),
+			NextCertificateExpiry: testTime(),
+			CARotation:            &cpv3.CARotationStatus{Generation: 2, RetireAfter: testTime()},
+			ImportedCAs:           map[string]string{"router-site-ca": "hash"},
+			Endpoints:             &cpv3.EndpointsStatus{Controller: "https://controller", Router: "router", Nats: "nats"},
+			ControllerVersion:     "3.5.0",
+			Replicas:              &cpv3.ReplicasStatus{Controller: 2, Router: 1, Nats: 3},
+			LastError:             "error",
+		},
+	}
+}
+
+func fullControlPlane() *ControlPlane {
+	return &ControlPlane{
+		ObjectMeta: testObjectMeta(),
+		Spec: ControlPlaneSpec{
+			Auth: Auth{
+				URL:              "https://auth.example.com",
+				Realm:            "pot",
+				SSL:              "external",
+				RealmKey:         "realm-key",
+				ControllerClient: "controller",
+				ControllerSecret: "secret",
+				ViewerClient:     "viewer",
+			},
+			Database: Database{
+				Provider:     "mysql",
+				Host:         "db.example.com",
+				Port:         3306,
+				User:         "iofog",
+				PasswordFrom: &SecretValueSource{SecretKeyRef: testSecretKeyRef("db")},
+				DatabaseName: "iofog",
+				SSL:          boolPtr(false),
+			},
+			ImagePullSecret: "pull",
+			Controller: Controller{
+				Replicas: 2,
+				Image:    "controller:1",
+				Service:  testService("controller"),
+				Ingress: ControllerIngress{
+					Annotations:      map[string]string{"a": "b"},
+					IngressClassName: "nginx",
+					Host:             "controller.example.com",
+					SecretName:       "controller-tls",
+				},
+				Gateway:             &GatewayHTTPRoute{Hostnames: []string{"controller.example.com"}},
+				Resources:           testResources("100m"),
+				Scheduling:          testScheduling("controller"),
+				PodDisruptionBudget: testPodDisruptionBudget(),
+				PidBaseDir:          "/run",
+				EcnViewerPort:       8008,
+				EcnViewerURL:        "https://viewer.example.com",
+				ECNName:             "pot",
+				Https:               boolPtr(false),
+				SecretName:          "controller-tls",
+				LogLevel:            "info",
+			},
+			Router: Router{
+				Image:   "router:1",
+				Service: testService("router"),
+				Ingress: RouterIngress{Address: "router.example.com", MessagePort: 1, InteriorPort: 2, EdgePort: 3},
+				Gateway: &GatewayRouterRoutes{
+					Kind:             "TCPRoute",
+					EdgeListener:     "edge",
+					InteriorListener: "interior",
+				},
+				HA:                  boolPtr(false),
+				Ports:               RouterPorts{MessagePort: 1, InteriorPort: 2, EdgePort: 3, HTTPPort: 4},
+				Resources:           testResources("200m"),
+				Scheduling:          testScheduling("router"),
+				PodDisruptionBudget: testPodDisruptionBudget(),
+			},
+			Nats: Nats{
+				Enabled:       boolPtr(true),
+				Replicas:      3,
+				Image:         "nats:1",
+				Service:       testService("nats"),
+				ServerService: testService("nats-server"),
+				Ingress: NatsIngress{
+					Address:     "nats.example.com",
+					ServerPort:  1,
+					ClusterPort: 2,
+					LeafPort:    3,
+					MqttPort:    4,
+					HttpPort:    5,
+				},
+				Gateway: &GatewayNatsRoutes{
+					Kind:         "TLSRoute",
+					Hostnames:    []string{"leaf.example.com", "mqtt.example.com"},
+					LeafListener: "leaf",
+					MqttListener: "mqtt",
+				},
+				JetStream:           &NatsJetStream{StorageSize: "20Gi", MemoryStoreSize: "2Gi"},
+				Ports:               NatsPorts{ServerPort: 1, ClusterPort: 2, LeafPort: 3, MqttPort: 4, HttpPort: 5},
+				Resources:           testResources("300m"),
+				Scheduling:          testScheduling("nats"),
+				PodDisruptionBudget: testPodDisruptionBudget(),
+			},
+			Events: Events{AuditEnabled: boolPtr(false), RetentionDays: 30},
+			Vault: &Vault{
+				Enabled:  boolPtr(true),
+				Provider: "google",
+				Google: &VaultGoogle{
+					ProjectId:       "project",
+					CredentialsFrom: &SecretValueSource{SecretKeyRef: testSecretKeyRef("google")},
+				},
+			},
+			Gateway: &Gateway{Name: "gateway"},
+			Certificates: &Certificates{
+				RenewBefore: &metav1.Duration{Duration: 48 * time.Hour},
+				CARotation:  &CARotation{Generation: 1},
+				CAs:         &CASecrets{NatsSite: "nats-site"},
+			},
+			DeletionPolicy:   &DeletionPolicy{PersistentVolumeClaims: "Delete", ExternalState: "Retain"},
+			ProgressDeadline: &metav1.Duration{Duration: 5 * time.Minute},
+		},
+		Status: ControlPlaneStatus{
+			Conditions:            testConditions(),
+			NextCertificateExpiry: testTime(),
+			CARotation:            &CARotationStatus{Generation: 1},
+			ImportedCAs:           map[string]string{"router-site-ca": "hash"},
+			Endpoints:             &EndpointsStatus{Controller: "https://controller", Router: "router", Nats: "nats"},
+			ControllerVersion:     "3.5.0",
+			Replicas:              &ReplicasStatus{Controller: 2, Router: 1, Nats: 3},
+		},
+	}
+}
+
+func testObjectMeta() metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:        "pot",
+		Namespace:   "iofog",
+		Labels:      map[string]string{"app": "pot"},
+		Annotations: map[string]string{"note": "pot"},
+		Generation:  3,
+		Finalizers:  []string{"datasance.com/finalizer"},
+	}
+}
+
+func testService(name string) Service {
+	return Service{
+		Type:                  "LoadBalancer",
+		Address:               name + ".example.com",
+		Annotations:           map[string]string{"service": name},
+		ExternalTrafficPolicy: "Local",
+	}
+}
+
+func testResources(cpu string) corev1.ResourceRequirements {
+	return corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu)},
+		Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
+	}
+}
+
+func testScheduling(component string) PodScheduling {
+	return PodScheduling{
+		NodeSelector: map[string]string{"pool": component},
+		Tolerations:  []corev1.Toleration{{Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: component}},
+		Affinity: &corev1.Affinity{
+			PodAntiAffinity: &corev1.PodAntiAffinity{
+				PreferredDuringSchedulingIgnoredDuringExecution: []corev1.WeightedPodAffinityTerm{{
+					Weight: 100,
+					PodAffinityTerm: corev1.PodAffinityTerm{
+						LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"name": component}},
+						TopologyKey:   corev1.LabelHostname,
+					},
+				}},
+			},
+		},
+		TopologySpreadConstraints: []corev1.TopologySpreadConstraint{{
+			MaxSkew:           1,
+			TopologyKey:       corev1.LabelTopologyZone,
+			WhenUnsatisfiable: corev1.ScheduleAnyway,
+		}},
+		PriorityClassName: "high",
+	}
+}
+
+func testPodDisruptionBudget() PodDisruptionBudget {
+	minAvailable := intstr.FromInt32(1)
+
+	return PodDisruptionBudget{Enabled: boolPtr(true), MinAvailable: &minAvailable}
+}
+
+func testSecretKeyRef(name string) *corev1.SecretKeySelector {
+	return &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: name}, Key: "value"}
+}
+
+func testConditions() []metav1.Condition {
+	return []metav1.Condition{{
+		Type:               "ready",
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: 3,
+		LastTransitionTime: *testTime(),
+		Reason:             "initial_Ready",
+	}}
+}
+
+func testTime() *metav1.Time {
+	t := metav1.NewTime(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC))
+
+	return &t
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
@@ -0,0 +1,541 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v4
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// ControlPlaneSpec defines the desired state of ControlPlane.
+// Each component (controller, router, nats) owns its image, service and ingress configuration.
+type ControlPlaneSpec struct {
+	// Auth contains Keycloak Client Configuration of Controller and ECN Viewer
+	Auth Auth `json:"auth"`
+	// Database for ioFog Controller
+	Database Database `json:"database"`
+	// ImagePullSecret is used to pull the images of every component
+	ImagePullSecret string `json:"imagePullSecret,omitempty"`
+	// Controller contains the deployment and runtime configuration of ioFog Controller
+	Controller Controller `json:"controller,omitempty"`
+	// Router contains the deployment configuration of the default ioFog Router
+	Router Router `json:"router,omitempty"`
+	// Nats contains the deployment configuration of the NATS hub. When omitted, NATS is enabled with defaults.
+	Nats Nats `json:"nats,omitempty"`
+	// Events contains runtime configuration for ioFog Controller events
+	Events Events `json:"events,omitempty"`
+	// Vault is optional. When set, the Controller uses the configured vault provider for secrets.
+	Vault *Vault `json:"vault,omitempty"`
+	// Gateway is the Gateway API Gateway the routes of the components attach to
+	Gateway *Gateway `json:"gateway,omitempty"`
+	// Certificates configures how the router and NATS TLS certificates are issued, the operator signs them when omitted
+	Certificates *Certificates `json:"certificates,omitempty"`
+	// DeletionPolicy decides which volumes and external state are removed when the ControlPlane is deleted
+	DeletionPolicy *DeletionPolicy `json:"deletionPolicy,omitempty"`
+	// ProgressDeadline is how long deploying or updating the ControlPlane may take before it is Failed, defaults to 10m
+	// +optional
+	ProgressDeadline *metav1.Duration `json:"progressDeadline,omitempty"`
+}
+
+// DeletionPolicy decides what deleting the ControlPlane removes besides the resources it owns.
+type DeletionPolicy struct {
+	// PersistentVolumeClaims of the Controller SQLite database and of JetStream, defaults to Delete
+	// +kubebuilder:validation:Enum=Retain;Delete
+	// +optional
+	PersistentVolumeClaims string `json:"persistentVolumeClaims,omitempty"`
+	// ExternalState is the ECN Viewer client root URL in Keycloak and the external database of the Controller.
+	// Delete resets the root URL and drops the database, defaults to Retain.
+	// +kubebuilder:validation:Enum=Retain;Delete
+	// +optional
+	ExternalState string `json:"externalState,omitempty"`
+}
+
+// Controller configures the ioFog Controller Deployment, Service and Ingress.
+type Controller struct {
+	// Replicas of ioFog Controller should be 1 unless an external DB is configured
+	Replicas int32 `json:"replicas,omitempty"`
+	// Image overrides the Controller image built into the operator
+	Image string `json:"image,omitempty"`
+	// Service should be LoadBalancer unless Ingress is being configured
+	Service Service `json:"service,omitempty"`
+	// Ingress is created when the Service is ClusterIP
+	Ingress ControllerIngress `json:"ingress,omitempty"`
+	// Gateway creates an HTTPRoute for the Controller API and the ECN Viewer on spec.gateway
+	Gateway *GatewayHTTPRoute `json:"gateway,omitempty"`
+	// Resources of the Controller container. When omitted, the operator defaults are used.
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+	// Scheduling places the Controller pods on nodes
+	Scheduling PodScheduling `json:"scheduling,omitempty"`
+	// PodDisruptionBudget overrides the budget computed from the Controller replicas
+	PodDisruptionBudget PodDisruptionBudget `json:"podDisruptionBudget,omitempty"`
+
+	PidBaseDir    string `json:"pidBaseDir,omitempty"`
+	EcnViewerPort int    `json:"ecnViewerPort,omitempty"`
+	EcnViewerURL  string `json:"ecnViewerUrl,omitempty"`
+	ECNName       string `json:"ecn,omitempty"`
+	Https         *bool  `json:"https,omitempty"`
+	SecretName    string `json:"secretName,omitempty"`
+	LogLevel      string `json:"logLevel,omitempty"`
+}
+
+// Router configures the default ioFog Router Deployment and Service.
+type Router struct {
+	// Image overrides the Router image built into the operator
+	Image string `json:"image,omitempty"`
+	// Service should be LoadBalancer unless Ingress is being configured
+	Service Service `json:"service,omitempty"`
+	// Ingress is the external address of the Router when the Service is not a LoadBalancer
+	Ingress RouterIngress `json:"ingress,omitempty"`
+	// Gateway creates routes for the edge and interior listeners on spec.gateway
+	Gateway *GatewayRouterRoutes `json:"gateway,omitempty"`
+	// HA runs a second interior router linked to the first one, both serve the router Service.
+	HA *bool `json:"ha,omitempty"`
+	// Ports the routers listen on, the Service exposes the same ports
+	Ports RouterPorts `json:"ports,omitempty"`
+	// Resources of the Router container. When omitted, the operator defaults are used.
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+	// Scheduling places the Router pods on nodes
+	Scheduling PodScheduling `json:"scheduling,omitempty"`
+	// PodDisruptionBudget overrides the budget computed from the Router replicas
+	PodDisruptionBudget PodDisruptionBudget `json:"podDisruptionBudget,omitempty"`
+}
+
+// Nats configures the NATS hub (StatefulSet, JetStream, services).
+// When Enabled is omitted, NATS is enabled. When false, no NATS resources are created and no hub is registered.
+type Nats struct {
+	// Enabled toggles NATS deployment. When omitted, treated as true.
+	Enabled *bool `json:"enabled,omitempty"`
+	// Replicas is the number of NATS server replicas (default 2, min 2 when NATS enabled).
+	// +kubebuilder:validation:Minimum=2
+	Replicas int32 `json:"replicas,omitempty"`
+	// Image overrides the NATS image built into the operator
+	Image string `json:"image,omitempty"`
+	// Service exposes the cluster, leaf and mqtt ports
+	Service Service `json:"service,omitempty"`
+	// ServerService exposes the client and monitoring ports
+	ServerService Service `json:"serverService,omitempty"`
+	// Ingress is the external address and ports used for hub registration
+	Ingress NatsIngress `json:"ingress,omitempty"`
+	// Gateway creates routes for the leaf and mqtt listeners on spec.gateway
+	Gateway *GatewayNatsRoutes `json:"gateway,omitempty"`
+	// JetStream storage and memory limits.
+	JetStream *NatsJetStream `json:"jetStream,omitempty"`
+	// Ports the NATS servers listen on, the Services expose the same ports.
+	// Ingress ports that are not set advertise these ports.
+	Ports NatsPorts `json:"ports,omitempty"`
+	// Resources of the NATS container. The memory limit must be greater than JetStream.MemoryStoreSize.
+	// The default memory limit is the JetStream memory store plus 1Gi.
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+	// Scheduling places the NATS pods on nodes. Without affinity or topology spread, replicas are spread across nodes and zones.
+	Scheduling PodScheduling `json:"scheduling,omitempty"`
+	// PodDisruptionBudget overrides the budget computed from the NATS replicas
+	PodDisruptionBudget PodDisruptionBudget `json:"podDisruptionBudget,omitempty"`
+}
+
+// PodDisruptionBudget overrides the budget of a component.
+// By default a budget is created for components with more than one replica.
+type PodDisruptionBudget struct {
+	// Enabled forces the budget to be created (true) or removed (false).
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+	// MinAvailable replaces the computed budget. Cannot be set together with MaxUnavailable.
+	// +optional
+	MinAvailable *intstr.IntOrString `json:"minAvailable,omitempty"`
+	// MaxUnavailable replaces the computed budget. Cannot be set together with MinAvailable.
+	// +optional
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+}
+
+// PodScheduling is copied as is to the pod template of a component.
+type PodScheduling struct {
+	NodeSelector              map[string]string                 `json:"nodeSelector,omitempty"`
+	Tolerations               []corev1.Toleration               `json:"tolerations,omitempty"`
+	Affinity                  *corev1.Affinity                  `json:"affinity,omitempty"`
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+	PriorityClassName         string                            `json:"priorityClassName,omitempty"`
+}
+
+type Service struct {
+	Type        string            `json:"type,omitempty"`
+	Address     string            `json:"address,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// ExternalTrafficPolicy for LoadBalancer/NodePort: "Local" or "Cluster". When omitted, LoadBalancer defaults to Local, others to Cluster.
+	ExternalTrafficPolicy string `json:"externalTrafficPolicy,omitempty"`
+}
+
+type ControllerIngress struct {
+	Annotations      map[string]string `json:"annotations,omitempty"`
+	IngressClassName string            `json:"ingressClassName,omitempty"`
+	Host             string            `json:"host,omitempty"`
+	SecretName       string            `json:"secretName,omitempty"`
+}
+
+// Certificates configures the issuance of the router and NATS server certificates.
+type Certificates struct {
+	// IssuerRef makes cert-manager issue the server certificates instead of the operator.
+	// The CA of the issuer is imported into the Controller in place of the operator CAs.
+	// +optional
+	IssuerRef *IssuerRef `json:"issuerRef,omitempty"`
+	// RenewBefore is how long before they expire the server certificates are renewed, defaults to 720h.
+	// It must be shorter than their validity of 43800h, or 2160h when cert-manager issues them.
+	// +optional
+	RenewBefore *metav1.Duration `json:"renewBefore,omitempty"`
+	// CARotation rotates the operator CAs of the router and NATS, CAs of an issuer are rotated by the issuer
+	// +optional
+	CARotation *CARotation `json:"caRotation,omitempty"`
+	// CAs are existing Secrets with a CA tls.crt and tls.key that sign the server certificates instead of operator CAs
+	// and are imported into the Controller. The operator does not copy, rotate or retire them.
+	// +optional
+	CAs *CASecrets `json:"cas,omitempty"`
+}
+
+// CASecrets names the Secrets of the provided CAs per trust domain, trust domains left empty keep operator CAs.
+// A ca.crt in the Secret is trusted instead of tls.crt, e.g. to include the chain of an intermediate CA.
+type CASecrets struct {
+	// RouterSite signs the router site server certificate used by interior routers
+	// +optional
+	RouterSite string `json:"routerSite,omitempty"`
+	// RouterLocal signs the router local server certificate used by edge routers
+	// +optional
+	RouterLocal string `json:"routerLocal,omitempty"`
+	// NatsSite signs the NATS site server certificate
+	// +optional
+	NatsSite string `json:"natsSite,omitempty"`
+	// NatsLocal signs the NATS mqtt server certificate
+	// +optional
+	NatsLocal string `json:"natsLocal,omitempty"`
+}
+
+// CARotation replaces the router and NATS CAs without breaking the agents that trust them.
+// The new CAs are trusted next to the previous ones until the grace period ends.
+type CARotation struct {
+	// Generation starts a rotation whenever it is increased
+	// +kubebuilder:validation:Minimum=0
+	Generation int64 `json:"generation,omitempty"`
+	// GracePeriod the previous CAs stay trusted after a rotation, defaults to 168h
+	// +optional
+	GracePeriod *metav1.Duration `json:"gracePeriod,omitempty"`
+}
+
+// IssuerRef references a cert-manager Issuer or ClusterIssuer.
+type IssuerRef struct {
+	// Name of the issuer
+	Name string `json:"name"`
+	// Kind of the issuer, defaults to Issuer
+	// +kubebuilder:validation:Enum=Issuer;ClusterIssuer
+	// +optional
+	Kind string `json:"kind,omitempty"`
+	// Group of the issuer, defaults to cert-manager.io
+	// +optional
+	Group string `json:"group,omitempty"`
+}
+
+// Gateway references an existing Gateway API Gateway the operator attaches routes to.
+// Advertised addresses are resolved from the Gateway status unless the routes set hostnames.
+type Gateway struct {
+	// Name of the Gateway
+	Name string `json:"name"`
+	// Namespace of the Gateway, defaults to the ControlPlane namespace
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// GatewayHTTPRoute exposes the Controller API and the ECN Viewer through an HTTPRoute.
+type GatewayHTTPRoute struct {
+	// Hostnames of the HTTPRoute, the first one is used for the ECN Viewer URL
+	Hostnames []string `json:"hostnames,omitempty"`
+	// Listener is the section name of the Gateway listener, all listeners when omitted
+	Listener string `json:"listener,omitempty"`
+}
+
+// GatewayRouterRoutes exposes the router edge and interior listeners, each through its own Gateway listener.
+type GatewayRouterRoutes struct {
+	// Kind of the routes, TLSRoute passes TLS through based on the hostnames
+	// +kubebuilder:validation:Enum=TCPRoute;TLSRoute
+	Kind string `json:"kind,omitempty"`
+	// Hostnames of TLSRoutes, the first one is registered with the Controller instead of the Gateway address
+	Hostnames []string `json:"hostnames,omitempty"`
+	// EdgeListener is the section name of the Gateway listener for edge routers
+	EdgeListener string `json:"edgeListener"`
+	// InteriorListener is the section name of the Gateway listener for interior routers
+	InteriorListener string `json:"interiorListener"`
+}
+
+// GatewayNatsRoutes exposes the NATS leaf and mqtt listeners, each through its own Gateway listener.
+type GatewayNatsRoutes struct {
+	// Kind of the routes, TLSRoute passes TLS through based on the hostnames
+	// +kubebuilder:validation:Enum=TCPRoute;TLSRoute
+	Kind string `json:"kind,omitempty"`
+	// Hostnames of TLSRoutes, the first one is registered with the Controller instead of the Gateway address
+	Hostnames []string `json:"hostnames,omitempty"`
+	// LeafListener is the section name of the Gateway listener for leaf nodes
+	LeafListener string `json:"leafListener"`
+	// MqttListener is the section name of the Gateway listener for MQTT clients
+	MqttListener string `json:"mqttListener"`
+}
+
+// RouterPorts are the router listener ports.
+// They default to: message 5671, interior 55671, edge 45671, http 9090.
+type RouterPorts struct {
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	MessagePort int `json:"messagePort,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	InteriorPort int `json:"interiorPort,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	EdgePort int `json:"edgePort,omitempty"`
+	// HTTPPort serves the health and metrics endpoints
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	HTTPPort int `json:"httpPort,omitempty"`
+}
+
+type RouterIngress struct {
+	Address      string `json:"address,omitempty"`
+	MessagePort  int    `json:"messagePort,omitempty"`
+	InteriorPort int    `json:"interiorPort,omitempty"`
+	EdgePort     int    `json:"edgePort,omitempty"`
+}
+
+// NatsIngress specifies the external address and ports for NATS hub registration.
+// Ports are optional and default to the listener ports of the Nats block.
+type NatsIngress struct {
+	Address     string `json:"address,omitempty"`
+	ServerPort  int    `json:"serverPort,omitempty"`
+	ClusterPort int    `json:"clusterPort,omitempty"`
+	LeafPort    int    `json:"leafPort,omitempty"`
+	MqttPort    int    `json:"mqttPort,omitempty"`
+	HttpPort    int    `json:"httpPort,omitempty"`
+}
+
+// NatsPorts are the NATS listener ports.
+// They default to: server 4222, cluster 6222, leaf 7422, mqtt 8883, http 8222.
+type NatsPorts struct {
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	ServerPort int `json:"serverPort,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	ClusterPort int `json:"clusterPort,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	LeafPort int `json:"leafPort,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	MqttPort int `json:"mqttPort,omitempty"`
+	// HttpPort serves the monitoring endpoints and the health probes
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	HttpPort int `json:"httpPort,omitempty"`
+}
+
+// NatsJetStream configures JetStream storage.
+type NatsJetStream struct {
+	// StorageSize is used for the PVC size and max_file_store in server.conf (default 10Gi).
+	StorageSize string `json:"storageSize,omitempty"`
+	// MemoryStoreSize is used for max_memory_store in server.conf only (default 1Gi).
+	MemoryStoreSize string `json:"memoryStoreSize,omitempty"`
+	// StorageClassName for the JetStream PVC (optional).
+	StorageClassName string `json:"storageClassName,omitempty"`
+}
+
+type Auth struct {
+	URL              string `json:"url"`
+	Realm            string `json:"realm"`
+	SSL              string `json:"ssl"`
+	RealmKey         string `json:"realmKey"`
+	ControllerClient string `json:"controllerClient"`
+	// ControllerSecret is the Keycloak client secret. Use ControllerSecretFrom to keep it out of the spec.
+	// +optional
+	ControllerSecret string `json:"controllerSecret,omitempty"`
+	// +optional
+	ControllerSecretFrom *SecretValueSource `json:"controllerSecretFrom,omitempty"`
+	ViewerClient         string             `json:"viewerClient"`
+}
+
+type Database struct {
+	Provider string `json:"provider"`
+	Host     string `json:"host"`
+	Port     int    `json:"port"`
+	User     string `json:"user"`
+	// Password of the database user. Use PasswordFrom to keep it out of the spec.
+	// +optional
+	Password string `json:"password,omitempty"`
+	// +optional
+	PasswordFrom *SecretValueSource `json:"passwordFrom,omitempty"`
+	DatabaseName string             `json:"databaseName"`
+	SSL          *bool              `json:"ssl,omitempty"`
+	CA           *string            `json:"ca,omitempty"`
+}
+
+type Events struct {
+	AuditEnabled     *bool `json:"auditEnabled,omitempty"`
+	RetentionDays    int   `json:"retentionDays,omitempty"`
+	CleanupInterval  int   `json:"cleanupInterval,omitempty"`
+	CaptureIpAddress *bool `json:"captureIpAddress,omitempty"`
+}
+
+// Vault configures vault integration for the Controller.
+// Provide only the block for the selected provider (hashicorp, aws, azure, or google).
+type Vault struct {
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+	// Provider: hashicorp, openbao, vault, aws, aws-secrets-manager, azure, azure-key-vault, google, google-secret-manager.
+	// +optional
+	Provider string `json:"provider,omitempty"`
+	// BasePath for secrets in vault; $namespace is replaced with the ControlPlane namespace (e.g. pot/$namespace/secrets).
+	// +optional
+	BasePath string `json:"basePath,omitempty"`
+	// +optional
+	Hashicorp *VaultHashicorp `json:"hashicorp,omitempty"`
+	// +optional
+	Aws *VaultAws `json:"aws,omitempty"`
+	// +optional
+	Azure *VaultAzure `json:"azure,omitempty"`
+	// +optional
+	Google *VaultGoogle `json:"google,omitempty"`
+}
+
+type VaultHashicorp struct {
+	Address string `json:"address,omitempty"`
+	Token   string `json:"token,omitempty"`
+	// TokenFrom reads the token from a Secret instead of Token.
+	TokenFrom *SecretValueSource `json:"tokenFrom,omitempty"`
+	Mount     string             `json:"mount,omitempty"`
+}
+
+type VaultAws struct {
+	Region      string `json:"region,omitempty"`
+	AccessKeyId string `json:"accessKeyId,omitempty"`
+	AccessKey   string `json:"accessKey,omitempty"`
+	// AccessKeyFrom reads the access key from a Secret instead of AccessKey.
+	AccessKeyFrom *SecretValueSource `json:"accessKeyFrom,omitempty"`
+}
+
+type VaultAzure struct {
+	URL          string `json:"url,omitempty"`
+	TenantId     string `json:"tenantId,omitempty"`
+	ClientId     string `json:"clientId,omitempty"`
+	ClientSecret string `json:"clientSecret,omitempty"`
+	// ClientSecretFrom reads the client secret from a Secret instead of ClientSecret.
+	ClientSecretFrom *SecretValueSource `json:"clientSecretFrom,omitempty"`
+}
+
+type VaultGoogle struct {
+	ProjectId   string `json:"projectId,omitempty"`
+	Credentials string `json:"credentials,omitempty"`
+	// CredentialsFrom reads the credentials from a Secret instead of Credentials.
+	CredentialsFrom *SecretValueSource `json:"credentialsFrom,omitempty"`
+}
+
+// SecretValueSource selects a sensitive value from a Secret in the ControlPlane namespace.
+type SecretValueSource struct {
+	SecretKeyRef *corev1.SecretKeySelector `json:"secretKeyRef,omitempty"`
+}
+
+// ControlPlaneStatus defines the observed state of ControlPlane.
+type ControlPlaneStatus struct {
+	Conditions []metav1.Condition `json:"conditions"`
+	// NextCertificateExpiry is the earliest expiry of the router and NATS server certificates
+	// +optional
+	NextCertificateExpiry *metav1.Time `json:"nextCertificateExpiry,omitempty"`
+	// CARotation is the progress of the last CA rotation
+	// +optional
+	CARotation *CARotationStatus `json:"caRotation,omitempty"`
+	// ImportedCAs identify the CAs the Controller imported by their name, a changed CA is imported again
+	// +optional
+	ImportedCAs map[string]string `json:"importedCAs,omitempty"`
+	// Endpoints are the resolved addresses of the components
+	// +optional
+	Endpoints *EndpointsStatus `json:"endpoints,omitempty"`
+	// ControllerVersion is the version reported by the Controller status API
+	// +optional
+	ControllerVersion string `json:"controllerVersion,omitempty"`
+	// Replicas are the ready replicas observed for each component
+	// +optional
+	Replicas *ReplicasStatus `json:"replicas,omitempty"`
+	// LastError is the error of the last failed reconcile, it is cleared once a reconcile succeeds
+	// +optional
+	LastError string `json:"lastError,omitempty"`
+}
+
+// CARotationStatus records the last CA rotation.
+type CARotationStatus struct {
+	// Generation of spec.certificates.caRotation the CAs were last rotated for
+	Generation int64 `json:"generation"`
+	// PendingGeneration of spec.certificates.caRotation the CAs are being rotated for, it is recorded before the CA Secrets are written
+	// +optional
+	PendingGeneration int64 `json:"pendingGeneration,omitempty"`
+	// RetireAfter is when the previous CAs stop being trusted, it is unset once they are retired
+	// +optional
+	RetireAfter *metav1.Time `json:"retireAfter,omitempty"`
+}
+
+// EndpointsStatus records the resolved addresses of the components.
+type EndpointsStatus struct {
+	// Controller is the URL of the Controller API, the external one when it is exposed
+	// +optional
+	Controller string `json:"controller,omitempty"`
+	// Router is the address advertised to the agents' routers
+	// +optional
+	Router string `json:"router,omitempty"`
+	// Nats is the address advertised to the agents' NATS leaf nodes
+	// +optional
+	Nats string `json:"nats,omitempty"`
+}
+
+// ReplicasStatus records the ready replicas of the components.
+type ReplicasStatus struct {
+	Controller int32 `json:"controller"`
+	Router     int32 `json:"router"`
+	Nats       int32 `json:"nats"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:unservedversion
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="ready")].status`
+// +kubebuilder:printcolumn:name="Controller",type=string,JSONPath=`.status.conditions[?(@.type=="ControllerReady")].status`
+// +kubebuilder:printcolumn:name="Router",type=string,JSONPath=`.status.conditions[?(@.type=="RouterReady")].status`
+// +kubebuilder:printcolumn:name="NATS",type=string,JSONPath=`.status.conditions[?(@.type=="NatsReady")].status`
+// +kubebuilder:printcolumn:name="Version",type=string,JSONPath=`.status.controllerVersion`
+// +kubebuilder:printcolumn:name="Endpoint",type=string,JSONPath=`.status.endpoints.controller`
+// +kubebuilder:printcolumn:name="Error",type=string,JSONPath=`.status.lastError`,priority=1
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+// ControlPlane is the Schema for the controlplanes API.
+type ControlPlane struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ControlPlaneSpec   `json:"spec,omitempty"`
+	Status ControlPlaneStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ControlPlaneList contains a list of ControlPlane.
+type ControlPlaneList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ControlPlane `json:"items"`
+}
+
+func init() { //nolint:gochecknoinits
+	SchemeBuilder.Register(&ControlPlane{}, &ControlPlaneList{})
+}
@@ -26,20 +26,25 @@ import (
 	"github.com/go-logr/logr"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 )
 
 // ApplicationReconciler reconciles a Application object.
 type ApplicationReconciler struct {
 	client.Client
-	Log    logr.Logger
-	Scheme *runtime.Scheme
+	Log      logr.Logger
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
 }
 
 // +kubebuilder:rbac:groups=datasance.com,resources=applications,verbs=get;list;watch;create;update;patch;delete
@@ -48,6 +53,8 @@ type ApplicationReconciler struct {
 // +kubebuilder:rbac:groups=datasance.com,resources=applications/finalizers,verbs=get;update;patch
 // +kubebuilder:rbac:groups=datasance.com,resources=apps/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=datasance.com,resources=apps/finalizers,verbs=get;update;patch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
 
 func (r *ApplicationReconciler) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
 	log := r.Log.WithValues("application", request.NamespacedName)
@@ -63,31 +70,53 @@ func (r *ApplicationReconciler) Reconcile(ctx context.Context, request ctrl.Requ
 		return ctrl.Result{}, err
 	}
 
+	dep, err := r.deploymentForApp(instance)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
 	found := &appsv1.Deployment{}
 
-	err = r.Client.Get(ctx, types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}, found)
+	err = r.Client.Get(ctx, types.NamespacedName{Name: dep.Name, Namespace: dep.Namespace}, found)
 	if err != nil && errors.IsNotFound(err) {
-		dep, err := r.deploymentForApp(instance)
-		if err != nil {
-			return ctrl.Result{}, err
-		}
-
 		log.Info("Creating a new Deployment", "Deployment.Namespace", dep.Namespace, "Deployment.Name", dep.Name)
 
 		err = r.Client.Create(ctx, dep)
 		if err != nil {
 			log.Error(err, "Failed to create new Deployment", "Deployment.Namespace", dep.Namespace, "Deployment.Name", dep.Name)
+			r.Recorder.Eventf(instance, corev1.EventTypeWarning, "CreateFailed", "Failed to create Deployment %s: %s", dep.Name, err.Error())
 
 			return ctrl.Result{}, err
 		}
 
+		r.Recorder.Eventf(instance, corev1.EventTypeNormal, "Created", "Created Deployment %s", dep.Name)
+
 		return ctrl.Result{Requeue: true}, nil
 	} else if err != nil {
 		log.Error(err, "Failed to get Deployment")
+		r.Recorder.Eventf(instance, corev1.EventTypeWarning, "GetFailed", "Failed to get Deployment %s: %s", dep.Name, err.Error())
 
 		return ctrl.Result{}, err
 	}
 
+	// Restore the pod template when the Deployment was changed, the API server only adds defaults to it
+	if !equality.Semantic.DeepDerivative(dep.Spec.Template, found.Spec.Template) {
+		log.Info("Restoring Deployment", "Deployment.Namespace", found.Namespace, "Deployment.Name", found.Name)
+
+		found.Spec.Template = dep.Spec.Template
+
+		if err := r.Client.Update(ctx, found); err != nil {
+			log.Error(err, "Failed to update Deployment", "Deployment.Namespace", found.Namespace, "Deployment.Name", found.Name)
+			r.Recorder.Eventf(instance, corev1.EventTypeWarning, "UpdateFailed", "Failed to restore Deployment %s: %s", found.Name, err.Error())
+
+			return ctrl.Result{}, err
+		}
+
+		r.Recorder.Eventf(instance, corev1.EventTypeNormal, "Restored", "Restored the pod template of Deployment %s", found.Name)
+
+		return ctrl.Result{Requeue: true}, nil
+	}
+
 	count := instance.Spec.Replicas
 	log.Info("Scaling", "Current count: ", *found.Spec.Replicas)
 	log.Info("Scaling", "Desired count: ", count)
@@ -97,11 +126,14 @@ func (r *ApplicationReconciler) Reconcile(ctx context.Context, request ctrl.Requ
 
 		err = r.Client.Update(ctx, found)
 		if err != nil {
-			log.Error(err, "Failed to update Deployment", "Deployment.Namespace", instance.Namespace, "Deployment.Name", instance.Name)
+			log.Error(err, "Failed to update Deployment", "Deployment.Namespace", found.Namespace, "Deployment.Name", found.Name)
+			r.Recorder.Eventf(instance, corev1.EventTypeWarning, "UpdateFailed", "Failed to scale Deployment %s: %s", found.Name, err.Error())
 
 			return ctrl.Result{}, err
 		}
 
+		r.Recorder.Eventf(instance, corev1.EventTypeNormal, "Scaled", "Scaled Deployment %s to %d replicas", found.Name, count)
+
 		return ctrl.Result{Requeue: true}, nil
 	}
 
@@ -133,6 +165,8 @@ func (r *ApplicationReconciler) Reconcile(ctx context.Context, request ctrl.Requ
 func (r *ApplicationReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&appsv3.Application{}).
+		// Restore the Deployment when it is deleted or its spec changed, status updates are ignored
+		Owns(&appsv1.Deployment{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
 		Complete(r)
 }
 
@@ -0,0 +1,170 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/util/csaupgrade"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// fieldManager owns the fields the operator applies to the resources of ControlPlanes.
+// Releases before server-side apply wrote the resources with Create and Update under the same name.
+const fieldManager = "iofog-operator"
+
+// restartFieldManager owns the restartedAt annotation of restarted pod templates, apart from the fields of fieldManager
+const restartFieldManager = "iofog-operator-restart"
+
+// restartedAtAnnotation rolls the pods of a workload when it changes, like kubectl rollout restart
+const restartedAtAnnotation = "kubectl.kubernetes.io/restartedAt"
+
+// applyObject creates or updates obj with server-side apply, obj only holds the fields the operator manages.
+// Fields the operator no longer sets are removed, fields set by others are kept.
+// The applied state is recorded on obj, obj is restored when it drifts from it.
+// It reports whether an existing object was changed.
+func (r *ControlPlaneReconciler) applyObject(ctx context.Context, obj client.Object) (updated bool, err error) {
+	if err := setAppliedState(obj); err != nil {
+		return false, err
+	}
+
+	return r.applyGeneratedObject(ctx, obj)
+}
+
+// createObject applies obj unless it exists, for objects that are generated once
+func (r *ControlPlaneReconciler) createObject(ctx context.Context, obj client.Object) error {
+	found, ok := obj.DeepCopyObject().(client.Object)
+	if !ok {
+		return fmt.Errorf("%T is not a client.Object", obj)
+	}
+
+	err := r.Client.Get(ctx, types.NamespacedName{Name: obj.GetName(), Namespace: obj.GetNamespace()}, found)
+	if err == nil {
+		return nil
+	}
+
+	if !k8serrors.IsNotFound(err) {
+		return err
+	}
+
+	_, err = r.applyGeneratedObject(ctx, obj)
+
+	return err
+}
+
+// applyGeneratedObject applies obj like applyObject without recording its applied state.
+// It is used for objects the operator generates once and only rewrites when they are stale, e.g. certificates,
+// they are restored when they are deleted but changes by others are kept.
+func (r *ControlPlaneReconciler) applyGeneratedObject(ctx context.Context, obj client.Object) (updated bool, err error) {
+	// Set ControlPlane instance as the owner and controller
+	if err := controllerutil.SetControllerReference(&r.cp, obj, r.Scheme); err != nil {
+		return false, err
+	}
+
+	gvk, err := apiutil.GVKForObject(obj, r.Scheme)
+	if err != nil {
+		return false, err
+	}
+
+	// Apply patches need the kind of typed objects
+	obj.GetObjectKind().SetGroupVersionKind(gvk)
+	obj.SetResourceVersion("")
+	obj.SetManagedFields(nil)
+
+	found, err := r.getObject(ctx, obj)
+	if err != nil {
+		return false, err
+	}
+
+	if found != nil {
+		if err := r.upgradeManagedFields(ctx, found); err != nil {
+			return false, err
+		}
+	}
+
+	if err := r.Client.Patch(ctx, obj, client.Apply, client.FieldOwner(fieldManager), client.ForceOwnership); err != nil {
+		return false, fmt.Errorf("apply %s %s: %w", gvk.Kind, obj.GetName(), err)
+	}
+
+	if found == nil {
+		r.log.Info(fmt.Sprintf("Created %s %s for ControlPlane %s", gvk.Kind, obj.GetName(), r.cp.Name))
+		r.recordCreated(gvk.Kind, obj.GetName())
+
+		return false, nil
+	}
+
+	r.recordUpdated(gvk.Kind, obj.GetName(), found.GetResourceVersion(), obj.GetResourceVersion())
+
+	return found.GetResourceVersion() != obj.GetResourceVersion(), nil
+}
+
+// getObject returns the current state of obj, nil when it does not exist
+func (r *ControlPlaneReconciler) getObject(ctx context.Context, obj client.Object) (client.Object, error) {
+	var found client.Object
+
+	if _, ok := obj.(*unstructured.Unstructured); ok {
+		u := &unstructured.Unstructured{}
+		u.SetGroupVersionKind(obj.GetObjectKind().GroupVersionKind())
+		found = u
+	} else {
+		newObj, err := r.Scheme.New(obj.GetObjectKind().GroupVersionKind())
+		if err != nil {
+			return nil, err
+		}
+
+		typed, ok := newObj.(client.Object)
+		if !ok {
+			return nil, fmt.Errorf("%T is not a client.Object", newObj)
+		}
+
+		found = typed
+	}
+
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: obj.GetName(), Namespace: obj.GetNamespace()}, found); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return found, nil
+}
+
+// upgradeManagedFields hands the fields written with Update by earlier releases over to the apply field manager,
+// otherwise fields the operator stopped setting would never be removed
+func (r *ControlPlaneReconciler) upgradeManagedFields(ctx context.Context, found client.Object) error {
+	patch, err := csaupgrade.UpgradeManagedFieldsPatch(found, sets.New(fieldManager), fieldManager)
+	if err != nil || patch == nil {
+		return err
+	}
+
+	return r.Client.Patch(ctx, found, client.RawPatch(types.JSONPatchType, patch))
+}
+
+// isScaledByOthers reports whether the replicas of a workload were set through its scale subresource,
+// e.g. by a HorizontalPodAutoscaler or kubectl scale
+func isScaledByOthers(obj client.Object) bool {
+	for _, entry := range obj.GetManagedFields() {
+		if entry.Subresource != "scale" || entry.FieldsV1 == nil {
+			continue
+		}
+
+		fields := map[string]map[string]interface{}{}
+		if err := json.Unmarshal(entry.FieldsV1.Raw, &fields); err != nil {
+			continue
+		}
+
+		if _, found := fields["f:spec"]["f:replicas"]; found {
+			return true
+		}
+	}
+
+	return false
+}
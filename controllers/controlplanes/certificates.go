@@ -0,0 +1,697 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	iofogclient "github.com/datasance/iofog-go-sdk/v3/pkg/client"
+	cpv3 "github.com/datasance/iofog-operator/v3/apis/controlplanes/v3"
+	"github.com/datasance/iofog-operator/v3/controllers/controlplanes/nats"
+	util "github.com/datasance/iofog-operator/v3/internal/util/certs"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// natsCertsHashAnnotation is set on the NATS pod template so reissued certificates roll the servers
+	natsCertsHashAnnotation = "datasance.com/nats-certs-hash"
+
+	// caRotationGenerationAnnotation records the generation of spec.certificates.caRotation a CA Secret was rotated for
+	caRotationGenerationAnnotation = "datasance.com/ca-rotation-generation"
+
+	// certificateCheckInterval bounds how long a Ready ControlPlane goes without checking its certificates
+	certificateCheckInterval = 12 * time.Hour
+)
+
+var (
+	routerServerSecrets = []string{"router-site-server", "router-local-server"}
+	natsServerSecrets   = []string{nats.NatsSiteServerSecret, nats.NatsMqttServerSecret}
+	routerCASecrets     = []string{"router-site-ca", "default-router-local-ca"}
+	natsCASecrets       = []string{nats.NatsSiteCASecret, nats.NatsLocalCASecret}
+
+	// serverCASecrets map the server certificate Secrets to the CAs that sign them
+	serverCASecrets = map[string]string{
+		"router-site-server":      "router-site-ca",
+		"router-local-server":     "default-router-local-ca",
+		nats.NatsSiteServerSecret: nats.NatsSiteCASecret,
+		nats.NatsMqttServerSecret: nats.NatsLocalCASecret,
+	}
+)
+
+// getServerSecrets returns the names of the server certificate Secrets the ControlPlane deploys
+func getServerSecrets(cp cpv3.ControlPlane) []string {
+	names := append([]string{}, routerServerSecrets...)
+	if isNatsEnabled(cp) {
+		names = append(names, natsServerSecrets...)
+	}
+
+	return names
+}
+
+// getCASecrets returns the names of the CA Secrets the ControlPlane imports into the Controller
+func getCASecrets(cp cpv3.ControlPlane) []string {
+	names := append([]string{}, routerCASecrets...)
+	if isNatsEnabled(cp) {
+		names = append(names, natsCASecrets...)
+	}
+
+	return names
+}
+
+// getProvidedCAs maps CA Secrets to the provided Secrets that replace them
+func getProvidedCAs(cp cpv3.ControlPlane) map[string]string {
+	provided := map[string]string{}
+	if cp.Spec.Certificates == nil || cp.Spec.Certificates.CAs == nil {
+		return provided
+	}
+
+	cas := cp.Spec.Certificates.CAs
+	for name, source := range map[string]string{
+		"router-site-ca":          cas.RouterSite,
+		"default-router-local-ca": cas.RouterLocal,
+		nats.NatsSiteCASecret:     cas.NatsSite,
+		nats.NatsLocalCASecret:    cas.NatsLocal,
+	} {
+		if source != "" {
+			provided[name] = source
+		}
+	}
+
+	return provided
+}
+
+// getCASource returns the Secret holding the CA name: the CA of the issuer, a provided CA or the operator CA.
+// Server certificates are signed from it and the Controller imports it under name.
+func getCASource(cp cpv3.ControlPlane, name string) string {
+	if isCertManagerEnabled(cp) {
+		return getIssuerCASecret(name)
+	}
+
+	if source, found := getProvidedCAs(cp)[name]; found {
+		return source
+	}
+
+	return name
+}
+
+func getRenewBefore(cp cpv3.ControlPlane) time.Duration {
+	if cp.Spec.Certificates == nil || cp.Spec.Certificates.RenewBefore == nil {
+		return cpv3.DefaultCertificateRenewBefore
+	}
+
+	return cp.Spec.Certificates.RenewBefore.Duration
+}
+
+// getCertificateNotAfter returns the expiry of the tls.crt of a Secret
+func getCertificateNotAfter(secret *corev1.Secret) (time.Time, bool) {
+	cert, err := util.DecodeCertificate(secret.Data["tls.crt"])
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return cert.NotAfter, true
+}
+
+// certificateExpiring reports whether the tls.crt of a Secret is due for renewal.
+// Certificates that cannot be decoded are renewed as well.
+func certificateExpiring(secret *corev1.Secret, renewBefore time.Duration) bool {
+	notAfter, ok := getCertificateNotAfter(secret)
+
+	return !ok || time.Now().Add(renewBefore).After(notAfter)
+}
+
+// certificateIssuedBy reports whether the tls.crt of a Secret is signed by the current key of a CA Secret
+// and trusts the same CAs, a CA rotation changes both.
+func certificateIssuedBy(secret, ca *corev1.Secret) bool {
+	cert, err := util.DecodeCertificate(secret.Data["tls.crt"])
+	if err != nil {
+		return false
+	}
+
+	caCert, err := util.DecodeCertificate(ca.Data["tls.crt"])
+	if err != nil {
+		return false
+	}
+
+	return cert.CheckSignatureFrom(caCert) == nil && bytes.Equal(secret.Data["ca.crt"], util.CABundle(ca))
+}
+
+// getSecretsCertsHash identifies the certificates of the named Secrets, missing Secrets are skipped
+func (r *ControlPlaneReconciler) getSecretsCertsHash(ctx context.Context, names []string) (string, error) {
+	hash := sha256.New()
+
+	for _, name := range names {
+		secret := &corev1.Secret{}
+		if err := r.Client.Get(ctx, types.NamespacedName{Name: name, Namespace: r.cp.Namespace}, secret); err != nil {
+			if k8serrors.IsNotFound(err) {
+				continue
+			}
+
+			return "", err
+		}
+
+		hash.Write(secret.Data["tls.crt"])
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// getNextCertificateExpiry returns the earliest expiry of the deployed server certificates, nil without any
+func (r *ControlPlaneReconciler) getNextCertificateExpiry(ctx context.Context) (*metav1.Time, error) {
+	var next *metav1.Time
+
+	for _, name := range getServerSecrets(r.cp) {
+		secret := &corev1.Secret{}
+		if err := r.Client.Get(ctx, types.NamespacedName{Name: name, Namespace: r.cp.Namespace}, secret); err != nil {
+			if k8serrors.IsNotFound(err) {
+				continue
+			}
+
+			return nil, err
+		}
+
+		notAfter, ok := getCertificateNotAfter(secret)
+		if !ok {
+			continue
+		}
+
+		setCertificateExpiryMetric(&r.cp, name, notAfter)
+
+		if next == nil || notAfter.Before(next.Time) {
+			t := metav1.NewTime(notAfter)
+			next = &t
+		}
+	}
+
+	return next, nil
+}
+
+// setCertificatesStatus records the next certificate expiry, it reports whether the status changed
+func (r *ControlPlaneReconciler) setCertificatesStatus(ctx context.Context) (bool, error) {
+	next, err := r.getNextCertificateExpiry(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	current := r.cp.Status.NextCertificateExpiry
+	if (current == nil && next == nil) || (current != nil && next != nil && current.Equal(next)) {
+		return false, nil
+	}
+
+	r.cp.Status.NextCertificateExpiry = next
+
+	return true, nil
+}
+
+// certificatesChanged reports whether server certificates are due for renewal, were not issued by their current CA,
+// an advertised address changed
+// or certificates were reissued without the router and NATS pods being rolled yet, e.g. by cert-manager.
+func (r *ControlPlaneReconciler) certificatesChanged(ctx context.Context) (bool, error) {
+	// cert-manager renews its certificates itself
+	if !isCertManagerEnabled(r.cp) && r.cp.Status.NextCertificateExpiry != nil &&
+		time.Now().Add(getRenewBefore(r.cp)).After(r.cp.Status.NextCertificateExpiry.Time) {
+		return true, nil
+	}
+
+	changed, err := r.serverCAsChanged(ctx)
+	if err != nil || changed {
+		return changed, err
+	}
+
+	changed, err = r.advertisedAddressesChanged(ctx)
+	if err != nil || changed {
+		return changed, err
+	}
+
+	changed, err = r.podCertsHashChanged(ctx, &appsv1.Deployment{}, routerName, routerCertsHashAnnotation, routerServerSecrets)
+	if err != nil || changed || !isNatsEnabled(r.cp) {
+		return changed, err
+	}
+
+	return r.podCertsHashChanged(ctx, &appsv1.StatefulSet{}, "nats", natsCertsHashAnnotation, natsServerSecrets)
+}
+
+// serverHostsChanged reports whether a generated server certificate was issued for other hosts.
+// Certificates from before the hosts were recorded are checked against their SANs.
+func serverHostsChanged(secret *corev1.Secret, hosts string) bool {
+	if recorded, found := secret.Annotations[util.HostsAnnotation]; found {
+		return recorded != hosts
+	}
+
+	dnsNames, ipAddresses := util.ParseHosts(hosts)
+	for _, name := range dnsNames {
+		if !certificateCoversHost(secret, name) {
+			return true
+		}
+	}
+
+	for _, ip := range ipAddresses {
+		if !certificateCoversHost(secret, ip.String()) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// advertisedAddressesChanged reports whether the LoadBalancer or Gateway address of the router or NATS is missing
+// from their server certificates. Addresses in the spec change the generation, these change without the ControlPlane.
+func (r *ControlPlaneReconciler) advertisedAddressesChanged(ctx context.Context) (bool, error) {
+	var address string
+	var err error
+
+	if isRouterGatewayEnabled(r.cp) {
+		var routerIngress cpv3.RouterIngress
+		routerIngress, err = r.getRouterGatewayIngress(ctx)
+		address = routerIngress.Address
+	} else if strings.EqualFold(r.cp.Spec.Services.Router.Type, string(corev1.ServiceTypeLoadBalancer)) {
+		address, err = r.getLoadBalancerAddress(ctx, routerName)
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	changed, err := r.secretMissesHost(ctx, "router-site-server", address)
+	if err != nil || changed || !isNatsEnabled(r.cp) {
+		return changed, err
+	}
+
+	address = ""
+
+	if isNatsGatewayEnabled(r.cp) {
+		var natsIngress cpv3.NatsIngress
+		natsIngress, err = r.getNatsGatewayIngress(ctx)
+		address = natsIngress.Address
+	} else if strings.EqualFold(r.cp.Spec.Services.Nats.Type, string(corev1.ServiceTypeLoadBalancer)) {
+		address, err = r.getLoadBalancerAddress(ctx, nats.ClientServiceName)
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	return r.secretMissesHost(ctx, nats.NatsSiteServerSecret, address)
+}
+
+// getLoadBalancerAddress returns the address of a LoadBalancer Service, empty until it is provisioned
+func (r *ControlPlaneReconciler) getLoadBalancerAddress(ctx context.Context, name string) (string, error) {
+	svc := &corev1.Service{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: name, Namespace: r.cp.Namespace}, svc); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return "", nil
+		}
+
+		return "", err
+	}
+
+	for _, ingress := range svc.Status.LoadBalancer.Ingress {
+		if ingress.IP != "" {
+			return ingress.IP, nil
+		}
+
+		if ingress.Hostname != "" {
+			return ingress.Hostname, nil
+		}
+	}
+
+	return "", nil
+}
+
+// secretMissesHost reports whether the certificate of an existing Secret is not valid for host
+func (r *ControlPlaneReconciler) secretMissesHost(ctx context.Context, name, host string) (bool, error) {
+	if host == "" {
+		return false, nil
+	}
+
+	secret := &corev1.Secret{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: name, Namespace: r.cp.Namespace}, secret); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return !certificateCoversHost(secret, host), nil
+}
+
+// podCertsHashChanged compares the certificates hash on the pod template of a workload with the Secrets
+func (r *ControlPlaneReconciler) podCertsHashChanged(ctx context.Context, workload client.Object, name, annotation string, secrets []string) (bool, error) {
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: name, Namespace: r.cp.Namespace}, workload); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	var annotations map[string]string
+
+	switch w := workload.(type) {
+	case *appsv1.Deployment:
+		annotations = w.Spec.Template.Annotations
+	case *appsv1.StatefulSet:
+		annotations = w.Spec.Template.Annotations
+	}
+
+	deployed, found := annotations[annotation]
+	if !found {
+		return false, nil
+	}
+
+	hash, err := r.getSecretsCertsHash(ctx, secrets)
+	if err != nil {
+		return false, err
+	}
+
+	return deployed != hash, nil
+}
+
+// getCertificateCheckDelay returns when a Ready ControlPlane checks its certificates again
+func (r *ControlPlaneReconciler) getCertificateCheckDelay() time.Duration {
+	delay := certificateCheckInterval
+	if r.cp.Status.NextCertificateExpiry != nil {
+		delay = time.Until(r.cp.Status.NextCertificateExpiry.Add(-getRenewBefore(r.cp)))
+	}
+
+	if delay <= 0 || delay > certificateCheckInterval {
+		delay = certificateCheckInterval
+	}
+
+	// Previous CAs are retired on time
+	if rotation := r.cp.Status.CARotation; rotation != nil && rotation.RetireAfter != nil {
+		if retire := time.Until(rotation.RetireAfter.Time); retire > 0 && retire < delay {
+			delay = retire
+		}
+	}
+
+	return delay
+}
+
+// reconcileCARotation starts a requested CA rotation and retires the previous CAs once the grace period is over.
+// It reports whether the status changed and the server certificates have to be reissued.
+func (r *ControlPlaneReconciler) reconcileCARotation(ctx context.Context) (bool, error) {
+	var rotation *cpv3.CARotation
+	if r.cp.Spec.Certificates != nil {
+		rotation = r.cp.Spec.Certificates.CARotation
+	}
+
+	status := r.cp.Status.CARotation
+
+	var rotatedGeneration int64
+	if status != nil {
+		rotatedGeneration = status.Generation
+	}
+
+	if rotation != nil && rotation.Generation > rotatedGeneration {
+		// CAs of an issuer are rotated by the issuer
+		if isCertManagerEnabled(r.cp) {
+			r.cp.Status.CARotation = &cpv3.CARotationStatus{Generation: rotation.Generation}
+
+			return true, nil
+		}
+
+		// A rotation retried after a failed status update continues with the CA Secrets not rotated yet
+		if status == nil || status.PendingGeneration != rotation.Generation {
+			pending := &cpv3.CARotationStatus{Generation: rotatedGeneration, PendingGeneration: rotation.Generation}
+			if status != nil {
+				pending.RetireAfter = status.RetireAfter
+			}
+
+			r.cp.Status.CARotation = pending
+			if err := r.Status().Update(ctx, &r.cp); err != nil {
+				return false, err
+			}
+		}
+
+		r.log.Info(fmt.Sprintf("Rotating CAs for ControlPlane %s", r.cp.Name))
+
+		if err := r.rotateCAs(ctx, rotation.Generation); err != nil {
+			return false, err
+		}
+
+		gracePeriod := cpv3.DefaultCARotationGracePeriod
+		if rotation.GracePeriod != nil {
+			gracePeriod = rotation.GracePeriod.Duration
+		}
+
+		retireAfter := metav1.NewTime(time.Now().Add(gracePeriod))
+		r.cp.Status.CARotation = &cpv3.CARotationStatus{Generation: rotation.Generation, RetireAfter: &retireAfter}
+
+		return true, nil
+	}
+
+	if status != nil && status.RetireAfter != nil && time.Now().After(status.RetireAfter.Time) {
+		r.log.Info(fmt.Sprintf("Retiring previous CAs for ControlPlane %s", r.cp.Name))
+
+		if err := r.retireCAs(ctx); err != nil {
+			return false, err
+		}
+
+		status.RetireAfter = nil
+
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// rotateCAs replaces the key of every CA Secret not rotated for generation yet,
+// ca.crt trusts the new and the previous CAs until they are retired.
+// Provided CAs are rotated by replacing their Secrets.
+func (r *ControlPlaneReconciler) rotateCAs(ctx context.Context, generation int64) error {
+	provided := getProvidedCAs(r.cp)
+	rotated := strconv.FormatInt(generation, 10)
+
+	for _, name := range getCASecrets(r.cp) {
+		if _, found := provided[name]; found {
+			continue
+		}
+
+		ca := &corev1.Secret{}
+		if err := r.Client.Get(ctx, types.NamespacedName{Name: name, Namespace: r.cp.Namespace}, ca); err != nil {
+			if k8serrors.IsNotFound(err) {
+				continue
+			}
+
+			return err
+		}
+
+		if ca.Annotations[caRotationGenerationAnnotation] == rotated {
+			continue
+		}
+
+		newCA, err := util.IssueCA(name, util.Options{})
+		if err != nil {
+			return err
+		}
+
+		ca.Data["ca.crt"] = bundleCertificates(newCA.Data["tls.crt"], ca.Data["ca.crt"], ca.Data["tls.crt"])
+		ca.Data["tls.crt"] = newCA.Data["tls.crt"]
+		ca.Data["tls.key"] = newCA.Data["tls.key"]
+
+		if ca.Annotations == nil {
+			ca.Annotations = map[string]string{}
+		}
+		ca.Annotations[caRotationGenerationAnnotation] = rotated
+
+		if err := r.Client.Update(ctx, ca); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// bundleCertificates concatenates the PEM certificates of bundles in order, every certificate once
+func bundleCertificates(bundles ...[]byte) []byte {
+	var bundle []byte
+
+	seen := map[string]bool{}
+
+	for _, rest := range bundles {
+		for {
+			var block *pem.Block
+			block, rest = pem.Decode(rest)
+			if block == nil {
+				break
+			}
+
+			if block.Type != "CERTIFICATE" || seen[string(block.Bytes)] {
+				continue
+			}
+
+			seen[string(block.Bytes)] = true
+			bundle = append(bundle, pem.EncodeToMemory(block)...)
+		}
+	}
+
+	return bundle
+}
+
+// retireCAs stops trusting the previous CAs, provided CAs keep the CAs they trust
+func (r *ControlPlaneReconciler) retireCAs(ctx context.Context) error {
+	provided := getProvidedCAs(r.cp)
+
+	for _, name := range getCASecrets(r.cp) {
+		if _, found := provided[name]; found {
+			continue
+		}
+
+		ca := &corev1.Secret{}
+		if err := r.Client.Get(ctx, types.NamespacedName{Name: name, Namespace: r.cp.Namespace}, ca); err != nil {
+			if k8serrors.IsNotFound(err) {
+				continue
+			}
+
+			return err
+		}
+
+		if bytes.Equal(ca.Data["ca.crt"], ca.Data["tls.crt"]) {
+			continue
+		}
+
+		ca.Data["ca.crt"] = ca.Data["tls.crt"]
+		if err := r.Client.Update(ctx, ca); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateProvidedCAs checks that the provided CAs standing in for the named CAs can sign the server certificates
+func (r *ControlPlaneReconciler) validateProvidedCAs(ctx context.Context, names []string) error {
+	provided := getProvidedCAs(r.cp)
+
+	for _, name := range names {
+		source, found := provided[name]
+		if !found {
+			continue
+		}
+
+		src := &corev1.Secret{}
+		if err := r.Client.Get(ctx, types.NamespacedName{Name: source, Namespace: r.cp.Namespace}, src); err != nil {
+			return fmt.Errorf("get provided CA %s: %w", source, err)
+		}
+
+		if _, err := util.LoadCA(src); err != nil {
+			return fmt.Errorf("provided CA %s: %w", source, err)
+		}
+	}
+
+	return nil
+}
+
+// serverCAsChanged reports whether a server certificate was not issued by the current source of its CA,
+// e.g. a provided CA was referenced, replaced or its reference removed
+func (r *ControlPlaneReconciler) serverCAsChanged(ctx context.Context) (bool, error) {
+	// cert-manager reissues its certificates itself
+	if isCertManagerEnabled(r.cp) {
+		return false, nil
+	}
+
+	for _, name := range getServerSecrets(r.cp) {
+		secret := &corev1.Secret{}
+		if err := r.Client.Get(ctx, types.NamespacedName{Name: name, Namespace: r.cp.Namespace}, secret); err != nil {
+			if k8serrors.IsNotFound(err) {
+				continue
+			}
+
+			return false, err
+		}
+
+		ca := &corev1.Secret{}
+		if err := r.Client.Get(ctx, types.NamespacedName{Name: getCASource(r.cp, serverCASecrets[name]), Namespace: r.cp.Namespace}, ca); err != nil {
+			if k8serrors.IsNotFound(err) {
+				return true, nil
+			}
+
+			return false, err
+		}
+
+		if !certificateIssuedBy(secret, ca) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// importCACertificate imports a CA into the Controller and imports it again once its source changed.
+// The CA of the issuer and provided CAs are imported under the name of the operator CA they stand in for.
+func (r *ControlPlaneReconciler) importCACertificate(ctx context.Context, iofogClient *iofogclient.Client, name string) error {
+	source := getCASource(r.cp, name)
+
+	ca := &corev1.Secret{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: source, Namespace: r.cp.Namespace}, ca); err != nil {
+		return err
+	}
+
+	hash := getHash(source + string(ca.Data["tls.crt"]) + string(ca.Data["ca.crt"]))
+
+	var imported string
+	var found bool
+
+	r.mutateStatus(func(status *cpv3.ControlPlaneStatus) {
+		imported, found = status.ImportedCAs[name]
+	})
+
+	if found && imported == hash {
+		return nil
+	}
+
+	// The Controller keeps the CA it imported first, a changed CA replaces it.
+	// The CA is only deleted once the changed one is known to be valid, the Controller is without it until it is imported.
+	if found {
+		if _, err := util.DecodeCertificate(ca.Data["tls.crt"]); err != nil {
+			return fmt.Errorf("changed CA %s from %s: %w", name, source, err)
+		}
+
+		r.log.Info(fmt.Sprintf("Importing changed CA %s from %s for ControlPlane %s", name, source, r.cp.Name))
+
+		start := time.Now()
+		err := iofogClient.DeleteCA(name)
+		observeControllerAPI("DeleteCA", start, err)
+
+		if err != nil && !strings.Contains(err.Error(), "NotFoundError") {
+			return err
+		}
+
+		// A failed import is created by the next reconcile without deleting it again
+		r.mutateStatus(func(status *cpv3.ControlPlaneStatus) {
+			delete(status.ImportedCAs, name)
+		})
+	}
+
+	// Retry right away, edge routers cannot get certificates while the Controller has no CA
+	err := retry.OnError(retry.DefaultBackoff, func(error) bool { return found }, func() error {
+		return r.ImportRouterCACertificate(iofogClient, name, source)
+	})
+	if err != nil {
+		return err
+	}
+
+	r.mutateStatus(func(status *cpv3.ControlPlaneStatus) {
+		if status.ImportedCAs == nil {
+			status.ImportedCAs = map[string]string{}
+		}
+
+		status.ImportedCAs[name] = hash
+	})
+
+	return nil
+}
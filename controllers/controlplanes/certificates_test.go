@@ -0,0 +1,276 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/pem"
+	"errors"
+	"testing"
+	"time"
+
+	cpv3 "github.com/datasance/iofog-operator/v3/apis/controlplanes/v3"
+	util "github.com/datasance/iofog-operator/v3/internal/util/certs"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const testNamespace = "iofog"
+
+// newTestReconciler returns a reconciler of cp backed by a fake client holding cp and objs
+func newTestReconciler(t *testing.T, cp *cpv3.ControlPlane, objs ...client.Object) *ControlPlaneReconciler {
+	t.Helper()
+
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cpv3.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	c := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(append([]client.Object{cp}, objs...)...).
+		WithStatusSubresource(&cpv3.ControlPlane{}).
+		Build()
+
+	r := &ControlPlaneReconciler{Client: c, Scheme: scheme, log: logr.Discard()}
+	if err := c.Get(context.Background(), client.ObjectKeyFromObject(cp), &r.cp); err != nil {
+		t.Fatal(err)
+	}
+
+	return r
+}
+
+func newRotationControlPlane(generation int64) *cpv3.ControlPlane {
+	return &cpv3.ControlPlane{
+		ObjectMeta: metav1.ObjectMeta{Name: "pot", Namespace: testNamespace},
+		Spec: cpv3.ControlPlaneSpec{
+			Certificates: &cpv3.Certificates{CARotation: &cpv3.CARotation{Generation: generation}},
+		},
+	}
+}
+
+func newTestCA(t *testing.T, name string) *corev1.Secret {
+	t.Helper()
+
+	ca, err := util.IssueCA(name, util.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ca.Namespace = testNamespace
+
+	return &ca
+}
+
+func getTestSecret(t *testing.T, r *ControlPlaneReconciler, name string) *corev1.Secret {
+	t.Helper()
+
+	secret := &corev1.Secret{}
+	if err := r.Client.Get(context.Background(), types.NamespacedName{Name: name, Namespace: testNamespace}, secret); err != nil {
+		t.Fatal(err)
+	}
+
+	return secret
+}
+
+func countCertificates(bundle []byte) int {
+	count := 0
+
+	for {
+		var block *pem.Block
+		block, bundle = pem.Decode(bundle)
+		if block == nil {
+			return count
+		}
+
+		count++
+	}
+}
+
+func TestReconcileCARotationRetried(t *testing.T) {
+	var objs []client.Object
+	for _, name := range routerCASecrets {
+		objs = append(objs, newTestCA(t, name))
+	}
+
+	r := newTestReconciler(t, newRotationControlPlane(1), objs...)
+	ctx := context.Background()
+
+	// Writing the second CA Secret fails, the rotation is retried
+	r.Client = errorOnUpdate{Client: r.Client, name: routerCASecrets[1]}
+
+	if _, err := r.reconcileCARotation(ctx); err == nil {
+		t.Fatal("expected the rotation to fail")
+	}
+
+	r.Client = r.Client.(errorOnUpdate).Client
+
+	stored := &cpv3.ControlPlane{}
+	if err := r.Client.Get(ctx, client.ObjectKeyFromObject(&r.cp), stored); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := stored.Status.CARotation; got == nil || got.PendingGeneration != 1 || got.Generation != 0 {
+		t.Fatalf("expected the pending rotation to be recorded, got %+v", got)
+	}
+
+	first := getTestSecret(t, r, routerCASecrets[0])
+
+	// The status update after the rotation fails as well, every reconcile starts from the recorded status
+	var second *corev1.Secret
+
+	for i := 0; i < 2; i++ {
+		r.cp = *stored.DeepCopy()
+
+		rotated, err := r.reconcileCARotation(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !rotated || r.cp.Status.CARotation.Generation != 1 {
+			t.Fatalf("expected the rotation to complete, got %+v", r.cp.Status.CARotation)
+		}
+
+		ca := getTestSecret(t, r, routerCASecrets[1])
+		if second != nil && !bytes.Equal(ca.Data["tls.crt"], second.Data["tls.crt"]) {
+			t.Error("expected the retried rotation not to rotate the CA again")
+		}
+
+		second = ca
+	}
+
+	if got := getTestSecret(t, r, routerCASecrets[0]); !bytes.Equal(got.Data["tls.crt"], first.Data["tls.crt"]) {
+		t.Error("expected the retried rotation not to rotate the CA again")
+	}
+
+	for _, ca := range []*corev1.Secret{first, second} {
+		if got := countCertificates(ca.Data["ca.crt"]); got != 2 {
+			t.Errorf("expected %s to trust 2 CAs, got %d", ca.Name, got)
+		}
+	}
+}
+
+func TestReconcileCARotationGracePeriod(t *testing.T) {
+	original := newTestCA(t, routerCASecrets[0])
+	r := newTestReconciler(t, newRotationControlPlane(1), original)
+	ctx := context.Background()
+
+	if _, err := r.reconcileCARotation(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	first := getTestSecret(t, r, routerCASecrets[0])
+
+	// A second rotation within the grace period keeps trusting the original CA
+	r.cp.Spec.Certificates.CARotation.Generation = 2
+	if _, err := r.reconcileCARotation(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	ca := getTestSecret(t, r, routerCASecrets[0])
+	want := bundleCertificates(ca.Data["tls.crt"], first.Data["tls.crt"], original.Data["tls.crt"])
+
+	if !bytes.Equal(ca.Data["ca.crt"], want) {
+		t.Errorf("expected ca.crt to bundle the current, previous and original CAs once, got %d certificates",
+			countCertificates(ca.Data["ca.crt"]))
+	}
+
+	if r.cp.Status.CARotation.RetireAfter == nil {
+		t.Fatal("expected the previous CAs to be retired after the grace period")
+	}
+
+	// Once the grace period is over only the current CA is trusted
+	past := metav1.NewTime(time.Now().Add(-time.Minute))
+	r.cp.Status.CARotation.RetireAfter = &past
+
+	if _, err := r.reconcileCARotation(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	ca = getTestSecret(t, r, routerCASecrets[0])
+	if !bytes.Equal(ca.Data["ca.crt"], ca.Data["tls.crt"]) {
+		t.Error("expected the previous CAs to be retired")
+	}
+}
+
+func TestReconcileCARotationKeepsProvidedCAs(t *testing.T) {
+	cp := newRotationControlPlane(1)
+	cp.Spec.Certificates.CAs = &cpv3.CASecrets{RouterSite: "customer-ca"}
+
+	// The provided CA trusts the chain of an intermediate CA
+	root := newTestCA(t, "root")
+	provided := newTestCA(t, "customer-ca")
+	provided.Data["ca.crt"] = append(append([]byte{}, provided.Data["tls.crt"]...), root.Data["tls.crt"]...)
+
+	r := newTestReconciler(t, cp, provided, newTestCA(t, routerCASecrets[1]))
+	ctx := context.Background()
+
+	if _, err := r.reconcileCARotation(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	past := metav1.NewTime(time.Now().Add(-time.Minute))
+	r.cp.Status.CARotation.RetireAfter = &past
+
+	if _, err := r.reconcileCARotation(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	got := getTestSecret(t, r, "customer-ca")
+	for _, key := range []string{"tls.crt", "tls.key", "ca.crt"} {
+		if !bytes.Equal(got.Data[key], provided.Data[key]) {
+			t.Errorf("expected the provided CA to keep its %s", key)
+		}
+	}
+
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: routerCASecrets[0], Namespace: testNamespace}, &corev1.Secret{}); err == nil {
+		t.Error("expected the provided CA not to be copied into the operator CA Secret")
+	}
+
+	if local := getTestSecret(t, r, routerCASecrets[1]); !bytes.Equal(local.Data["ca.crt"], local.Data["tls.crt"]) {
+		t.Error("expected the operator CA to be rotated and retired")
+	}
+}
+
+func TestBundleCertificates(t *testing.T) {
+	a := newTestCA(t, "a").Data["tls.crt"]
+	b := newTestCA(t, "b").Data["tls.crt"]
+
+	for name, tc := range map[string]struct {
+		bundles [][]byte
+		want    []byte
+	}{
+		"empty":     {bundles: [][]byte{nil, {}}, want: nil},
+		"ordered":   {bundles: [][]byte{b, a}, want: append(append([]byte{}, b...), a...)},
+		"duplicate": {bundles: [][]byte{a, append(append([]byte{}, b...), a...), b}, want: append(append([]byte{}, a...), b...)},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if got := bundleCertificates(tc.bundles...); !bytes.Equal(got, tc.want) {
+				t.Errorf("expected %d certificates, got %d", countCertificates(tc.want), countCertificates(got))
+			}
+		})
+	}
+}
+
+// errorOnUpdate fails updates of the named object
+type errorOnUpdate struct {
+	client.Client
+	name string
+}
+
+func (c errorOnUpdate) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	if obj.GetName() == c.name {
+		return errors.New("update failed")
+	}
+
+	return c.Client.Update(ctx, obj, opts...)
+}
@@ -0,0 +1,240 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	cpv3 "github.com/datasance/iofog-operator/v3/apis/controlplanes/v3"
+	util "github.com/datasance/iofog-operator/v3/internal/util/certs"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// issuerCASecretSuffix names the Secrets the CAs of an issuer are published to, the operator CAs are kept
+	// so they sign the server certificates again once cert-manager is no longer used
+	issuerCASecretSuffix = "-issuer"
+
+	// Certificates are watched when cert-manager was installed before the operator started,
+	// the requeue only backs them up and waits as long as a Certificate already waited within these bounds
+	certificateMinWait = 5 * time.Second
+	certificateMaxWait = 2 * time.Minute
+)
+
+// cert-manager Certificates are handled unstructured, cert-manager is optional in the cluster
+var certificateGVK = schema.GroupVersionKind{Group: "cert-manager.io", Version: "v1", Kind: "Certificate"}
+
+// issuedCertificate is a server certificate cert-manager issues into secretName.
+// The CA of the issuer stands in for the operator CA caSecretName when it is imported into the Controller.
+type issuedCertificate struct {
+	secretName   string
+	caSecretName string
+	commonName   string
+	hosts        string // comma separated like the hosts of util.ParseHosts
+}
+
+func isCertManagerEnabled(cp cpv3.ControlPlane) bool {
+	return cp.Spec.Certificates != nil && cp.Spec.Certificates.IssuerRef != nil
+}
+
+func getIssuerCASecret(caSecretName string) string {
+	return caSecretName + issuerCASecretSuffix
+}
+
+// getCertificateWait returns how long to wait for Certificates pending since a time
+func getCertificateWait(pendingSince time.Time) time.Duration {
+	wait := time.Since(pendingSince)
+	if wait < certificateMinWait {
+		return certificateMinWait
+	}
+
+	if wait > certificateMaxWait {
+		return certificateMaxWait
+	}
+
+	return wait
+}
+
+// createIssuedSecrets creates the Certificates and returns the issued Secrets once all of them are ready.
+// wait is how long to wait for cert-manager while it has not issued the current spec of every Certificate.
+func (r *ControlPlaneReconciler) createIssuedSecrets(ctx context.Context, labels map[string]string, certs []issuedCertificate) (secrets []corev1.Secret, wait time.Duration, err error) {
+	var pendingSince time.Time
+
+	for _, cert := range certs {
+		since, err := r.createCertificate(ctx, labels, cert)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		if !since.IsZero() && (pendingSince.IsZero() || since.Before(pendingSince)) {
+			pendingSince = since
+		}
+	}
+
+	if !pendingSince.IsZero() {
+		return nil, getCertificateWait(pendingSince), nil
+	}
+
+	for _, cert := range certs {
+		secret := corev1.Secret{}
+		if err := r.Client.Get(ctx, types.NamespacedName{Name: cert.secretName, Namespace: r.cp.Namespace}, &secret); err != nil {
+			return nil, 0, err
+		}
+
+		if err := r.publishIssuerCA(ctx, labels, cert.caSecretName, &secret); err != nil {
+			return nil, 0, err
+		}
+
+		secrets = append(secrets, secret)
+	}
+
+	return secrets, 0, nil
+}
+
+// createCertificate creates or updates the Certificate of cert and returns since when its current spec is pending,
+// zero once it is issued
+func (r *ControlPlaneReconciler) createCertificate(ctx context.Context, labels map[string]string, cert issuedCertificate) (time.Time, error) {
+	issuer := r.cp.Spec.Certificates.IssuerRef
+	dnsNames, ipAddresses := splitHosts(cert.hosts)
+
+	spec := map[string]interface{}{
+		"secretName":  cert.secretName,
+		"commonName":  cert.commonName,
+		"dnsNames":    dnsNames,
+		"usages":      []interface{}{"digital signature", "key encipherment", "server auth", "client auth"},
+		"duration":    cpv3.IssuerCertificateDuration.String(),
+		"renewBefore": getRenewBefore(r.cp).String(),
+		"issuerRef": map[string]interface{}{
+			"name":  issuer.Name,
+			"kind":  issuer.Kind,
+			"group": issuer.Group,
+		},
+		"secretTemplate": map[string]interface{}{
+			"labels": toInterfaceMap(labels),
+		},
+	}
+	if len(ipAddresses) > 0 {
+		spec["ipAddresses"] = ipAddresses
+	}
+
+	certificate := &unstructured.Unstructured{}
+	certificate.SetGroupVersionKind(certificateGVK)
+	certificate.SetName(cert.secretName)
+	certificate.SetNamespace(r.cp.Namespace)
+	certificate.SetLabels(labels)
+	certificate.Object["spec"] = spec
+
+	if _, err := r.applyObject(ctx, certificate); err != nil {
+		return time.Time{}, err
+	}
+
+	return getCertificatePendingSince(certificate), nil
+}
+
+// publishIssuerCA stores the CA of an issued Secret next to the operator CA caSecretName,
+// the Controller imports CAs from Secrets
+func (r *ControlPlaneReconciler) publishIssuerCA(ctx context.Context, labels map[string]string, caSecretName string, issued *corev1.Secret) error {
+	caCert := issued.Data["ca.crt"]
+	if len(caCert) == 0 {
+		return fmt.Errorf("issuer of Certificate %s does not provide a CA certificate", issued.Name)
+	}
+
+	caSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      getIssuerCASecret(caSecretName),
+			Namespace: r.cp.Namespace,
+			Labels:    labels,
+		},
+		Type: corev1.SecretTypeTLS,
+		Data: map[string][]byte{
+			"tls.crt": caCert,
+			"tls.key": {},
+			"ca.crt":  caCert,
+		},
+	}
+	updated, err := r.applyObject(ctx, caSecret)
+	if updated {
+		r.log.Info(fmt.Sprintf("Updated CA Secret %s with the CA of the issuer for ControlPlane %s", caSecret.Name, r.cp.Name))
+	}
+
+	return err
+}
+
+// getCertificatePendingSince returns since when the current generation of a Certificate waits for cert-manager,
+// zero once it is issued
+func getCertificatePendingSince(certificate *unstructured.Unstructured) time.Time {
+	if isCertificateReady(certificate) {
+		return time.Time{}
+	}
+
+	since := certificate.GetCreationTimestamp().Time
+
+	conditions, _, _ := unstructured.NestedSlice(certificate.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok || condition["type"] != "Ready" {
+			continue
+		}
+
+		lastTransition, _ := condition["lastTransitionTime"].(string)
+		if t, err := time.Parse(time.RFC3339, lastTransition); err == nil && t.After(since) {
+			since = t
+		}
+	}
+
+	if since.IsZero() {
+		return time.Now()
+	}
+
+	return since
+}
+
+// isCertificateReady reports whether cert-manager issued the current generation of a Certificate
+func isCertificateReady(certificate *unstructured.Unstructured) bool {
+	conditions, _, _ := unstructured.NestedSlice(certificate.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		if condition["type"] != "Ready" {
+			continue
+		}
+
+		observedGeneration, _, _ := unstructured.NestedInt64(condition, "observedGeneration")
+
+		return condition["status"] == string(metav1.ConditionTrue) && observedGeneration == certificate.GetGeneration()
+	}
+
+	return false
+}
+
+// splitHosts splits comma separated hosts into the DNS names and IP addresses of a Certificate
+func splitHosts(hosts string) (dnsNames, ipAddresses []interface{}) {
+	dnsNames = []interface{}{}
+
+	names, ips := util.ParseHosts(hosts)
+	for _, name := range names {
+		dnsNames = append(dnsNames, name)
+	}
+
+	for _, ip := range ips {
+		ipAddresses = append(ipAddresses, ip.String())
+	}
+
+	return dnsNames, ipAddresses
+}
+
+func toInterfaceMap(in map[string]string) map[string]interface{} {
+	out := make(map[string]interface{}, len(in))
+	for k, v := range in {
+		out[k] = v
+	}
+
+	return out
+}
@@ -18,30 +18,64 @@ package controllers
 
 import (
 	"context"
+	"sync"
 
 	iofogclient "github.com/datasance/iofog-go-sdk/v3/pkg/client"
 	op "github.com/datasance/iofog-go-sdk/v3/pkg/k8s/operator"
 	cpv3 "github.com/datasance/iofog-operator/v3/apis/controlplanes/v3"
 	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 )
 
 // ControlPlaneReconciler reconciles a ControlPlane object.
 type ControlPlaneReconciler struct {
 	client.Client
-	Log    logr.Logger
-	log    logr.Logger
-	Scheme *runtime.Scheme
-	cp     cpv3.ControlPlane
+	// APIReader reads objects uncached when the cache may lag behind the reconciler's own writes
+	APIReader client.Reader
+	Log       logr.Logger
+	log       logr.Logger
+	Scheme    *runtime.Scheme
+	Recorder  record.EventRecorder
+	cp        cpv3.ControlPlane
+	// credentials of cp resolved from inline values and referenced Secrets
+	credentials *credentials
+	// statusLock guards the status of cp written by the parallel component reconciles
+	statusLock sync.Mutex
+	// gateway is the status of spec.gateway, read once per reconcile and shared by the component reconciles
+	gateway     *gatewayStatus
+	gatewayLock sync.Mutex
+	// deletedObjects are the owned objects deleted since each ControlPlane became ready, by kind and name
+	deletedObjects map[types.NamespacedName]map[string]client.Object
+	deletedLock    sync.Mutex
 }
 
 // +kubebuilder:rbac:groups=datasance.com,resources=controlplanes,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=datasance.com,resources=controlplanes/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=datasance.com,resources=controlplanes/finalizers,verbs=get;update;patch
-// +kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=apps,resources=deployments;statefulsets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=services;configmaps;serviceaccounts,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=roles;rolebindings,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=cert-manager.io,resources=certificates,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=gateways,verbs=get;list;watch
+// +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=httproutes;tcproutes;tlsroutes,verbs=get;list;watch;create;update;patch;delete
 
 func (r *ControlPlaneReconciler) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
 	r.log = r.Log.WithValues("controlplane", request.NamespacedName)
@@ -52,12 +86,43 @@ func (r *ControlPlaneReconciler) Reconcile(ctx context.Context, request ctrl.Req
 			// Request object not found, could have been deleted after reconcile request.
 			// Owned objects are automatically garbage collected. For additional cleanup logic use finalizers.
 			// Return and don't requeue
+			deleteControlPlaneMetrics(request.Namespace, request.Name)
+			r.forgetDeletedObjects(request.NamespacedName)
+
 			return op.DoNotRequeue()
 		}
 		// Error reading the object - requeue the request.
 		return op.RequeueWithError(err)
 	}
 
+	// Hold the deletion for the teardown, before defaulting so only the finalizer is written
+	if r.cp.DeletionTimestamp.IsZero() {
+		if err := r.ensureFinalizer(ctx); err != nil {
+			return op.RequeueWithError(err)
+		}
+	}
+
+	// Work on a fully defaulted spec, also for objects admitted without the defaulting webhook
+	r.cp.Default()
+
+	if !r.cp.DeletionTimestamp.IsZero() {
+		recon := r.reconcileDeletion(ctx)
+		if recon.End {
+			deleteControlPlaneMetrics(r.cp.Namespace, r.cp.Name)
+			r.forgetDeletedObjects(request.NamespacedName)
+		}
+
+		return recon.Result()
+	}
+
+	creds, err := r.resolveCredentials(ctx)
+	if err != nil {
+		return op.RequeueWithError(err)
+	}
+
+	r.credentials = creds
+	r.gateway = nil
+
 	// Reconcile based on state
 	reconciler, err := r.getReconcileFunc(ctx)
 	if err != nil {
@@ -66,6 +131,8 @@ func (r *ControlPlaneReconciler) Reconcile(ctx context.Context, request ctrl.Req
 
 	recon := reconciler(ctx)
 
+	setConditionMetric(&r.cp)
+
 	return recon.Result()
 }
 
@@ -78,7 +145,25 @@ func (r *ControlPlaneReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		},
 	})
 
-	return ctrl.NewControllerManagedBy(mgr).
+	bldr := ctrl.NewControllerManagedBy(mgr).
 		For(&cpv3.ControlPlane{}).
-		Complete(r)
+		// Restore owned objects that were changed or deleted
+		Owns(&appsv1.Deployment{}, builder.WithPredicates(specChangedPredicate, r.deletionRecorder())).
+		Owns(&appsv1.StatefulSet{}, builder.WithPredicates(specChangedPredicate, r.deletionRecorder())).
+		Owns(&networkingv1.Ingress{}, builder.WithPredicates(specChangedPredicate, r.deletionRecorder())).
+		Owns(&corev1.Service{}, builder.WithPredicates(serviceChangedPredicate, r.deletionRecorder())).
+		Owns(&corev1.ConfigMap{}, builder.WithPredicates(dataChangedPredicate, r.deletionRecorder())).
+		Owns(&corev1.Secret{}, builder.WithPredicates(dataChangedPredicate, r.deletionRecorder())).
+		Owns(&corev1.PersistentVolumeClaim{}, builder.WithPredicates(deletedPredicate, r.deletionRecorder())).
+		// Roll the Controller when a Secret referenced by the spec changes
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.findControlPlanesForSecret))
+
+	// Continue once cert-manager issued the Certificates, cert-manager is optional in the cluster
+	if _, err := mgr.GetRESTMapper().RESTMapping(certificateGVK.GroupKind(), certificateGVK.Version); err == nil {
+		certificate := &unstructured.Unstructured{}
+		certificate.SetGroupVersionKind(certificateGVK)
+		bldr = bldr.Owns(certificate)
+	}
+
+	return bldr.Complete(r)
 }
@@ -0,0 +1,215 @@
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	cpv3 "github.com/datasance/iofog-operator/v3/apis/controlplanes/v3"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// credentialsHashAnnotation is set on the Controller pod template so a credential change rolls the Controller
+const credentialsHashAnnotation = "datasance.com/credentials-hash"
+
+// credentials are the sensitive parts of the spec with every secret reference resolved.
+// They are kept apart from r.cp so resolved values are never written back to the ControlPlane.
+type credentials struct {
+	auth  cpv3.Auth
+	db    cpv3.Database
+	vault *cpv3.Vault
+	hash  string
+}
+
+func (r *ControlPlaneReconciler) resolveCredentials(ctx context.Context) (*credentials, error) {
+	var err error
+
+	creds := &credentials{
+		auth: r.cp.Spec.Auth,
+		db:   r.cp.Spec.Database,
+	}
+
+	creds.auth.ControllerSecret, err = r.getSecretValue(ctx, creds.auth.ControllerSecret, creds.auth.ControllerSecretFrom)
+	if err != nil {
+		return nil, fmt.Errorf("resolve spec.auth.controllerSecretFrom: %w", err)
+	}
+
+	creds.db.Password, err = r.getSecretValue(ctx, creds.db.Password, creds.db.PasswordFrom)
+	if err != nil {
+		return nil, fmt.Errorf("resolve spec.database.passwordFrom: %w", err)
+	}
+
+	if vault := getVaultIfConfigured(r.cp.Spec); vault != nil {
+		if creds.vault, err = r.resolveVaultCredentials(ctx, vault); err != nil {
+			return nil, err
+		}
+	}
+
+	creds.hash = creds.computeHash()
+
+	return creds, nil
+}
+
+func (r *ControlPlaneReconciler) resolveVaultCredentials(ctx context.Context, vault *cpv3.Vault) (*cpv3.Vault, error) {
+	var err error
+
+	resolved := vault.DeepCopy()
+
+	switch {
+	case resolved.Hashicorp != nil:
+		resolved.Hashicorp.Token, err = r.getSecretValue(ctx, resolved.Hashicorp.Token, resolved.Hashicorp.TokenFrom)
+		if err != nil {
+			return nil, fmt.Errorf("resolve spec.vault.hashicorp.tokenFrom: %w", err)
+		}
+	case resolved.Aws != nil:
+		resolved.Aws.AccessKey, err = r.getSecretValue(ctx, resolved.Aws.AccessKey, resolved.Aws.AccessKeyFrom)
+		if err != nil {
+			return nil, fmt.Errorf("resolve spec.vault.aws.accessKeyFrom: %w", err)
+		}
+	case resolved.Azure != nil:
+		resolved.Azure.ClientSecret, err = r.getSecretValue(ctx, resolved.Azure.ClientSecret, resolved.Azure.ClientSecretFrom)
+		if err != nil {
+			return nil, fmt.Errorf("resolve spec.vault.azure.clientSecretFrom: %w", err)
+		}
+	case resolved.Google != nil:
+		resolved.Google.Credentials, err = r.getSecretValue(ctx, resolved.Google.Credentials, resolved.Google.CredentialsFrom)
+		if err != nil {
+			return nil, fmt.Errorf("resolve spec.vault.google.credentialsFrom: %w", err)
+		}
+	}
+
+	return resolved, nil
+}
+
+// getSecretValue returns the inline value unless a Secret reference is configured
+func (r *ControlPlaneReconciler) getSecretValue(ctx context.Context, inline string, from *cpv3.SecretValueSource) (string, error) {
+	if from == nil || from.SecretKeyRef == nil {
+		return inline, nil
+	}
+
+	ref := from.SecretKeyRef
+	optional := ref.Optional != nil && *ref.Optional
+
+	secret := &corev1.Secret{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: r.cp.Namespace}, secret); err != nil {
+		if k8serrors.IsNotFound(err) && optional {
+			return "", nil
+		}
+
+		return "", err
+	}
+
+	value, found := secret.Data[ref.Key]
+	if !found && !optional {
+		return "", fmt.Errorf("key %s not found in Secret %s", ref.Key, ref.Name)
+	}
+
+	return string(value), nil
+}
+
+func (c *credentials) computeHash() string {
+	hash := sha256.New()
+	values := []string{c.auth.ControllerSecret, c.db.Password}
+
+	if c.vault != nil {
+		switch {
+		case c.vault.Hashicorp != nil:
+			values = append(values, c.vault.Hashicorp.Token)
+		case c.vault.Aws != nil:
+			values = append(values, c.vault.Aws.AccessKey)
+		case c.vault.Azure != nil:
+			values = append(values, c.vault.Azure.ClientSecret)
+		case c.vault.Google != nil:
+			values = append(values, c.vault.Google.Credentials)
+		}
+	}
+
+	for _, value := range values {
+		hash.Write([]byte(value))
+		hash.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// credentialsChanged reports whether the running Controller was rolled out with other credentials
+func (r *ControlPlaneReconciler) credentialsChanged(ctx context.Context) (bool, error) {
+	dep := &appsv1.Deployment{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: controllerName, Namespace: r.cp.Namespace}, dep); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return dep.Spec.Template.Annotations[credentialsHashAnnotation] != r.credentials.hash, nil
+}
+
+// getSecretReferences returns the names of all Secrets referenced by the spec
+func getSecretReferences(cp *cpv3.ControlPlane) []string {
+	refs := []*cpv3.SecretValueSource{
+		cp.Spec.Auth.ControllerSecretFrom,
+		cp.Spec.Database.PasswordFrom,
+	}
+
+	if vault := cp.Spec.Vault; vault != nil {
+		if vault.Hashicorp != nil {
+			refs = append(refs, vault.Hashicorp.TokenFrom)
+		}
+
+		if vault.Aws != nil {
+			refs = append(refs, vault.Aws.AccessKeyFrom)
+		}
+
+		if vault.Azure != nil {
+			refs = append(refs, vault.Azure.ClientSecretFrom)
+		}
+
+		if vault.Google != nil {
+			refs = append(refs, vault.Google.CredentialsFrom)
+		}
+	}
+
+	names := []string{}
+
+	for _, ref := range refs {
+		if ref != nil && ref.SecretKeyRef != nil {
+			names = append(names, ref.SecretKeyRef.Name)
+		}
+	}
+
+	return names
+}
+
+// findControlPlanesForSecret maps a Secret to the ControlPlanes referencing it
+func (r *ControlPlaneReconciler) findControlPlanesForSecret(ctx context.Context, secret client.Object) []reconcile.Request {
+	cps := &cpv3.ControlPlaneList{}
+	if err := r.Client.List(ctx, cps, client.InNamespace(secret.GetNamespace())); err != nil {
+		r.Log.Error(err, "Failed to list ControlPlanes for Secret", "Secret.Name", secret.GetName())
+
+		return nil
+	}
+
+	requests := []reconcile.Request{}
+
+	for i := range cps.Items {
+		for _, name := range getSecretReferences(&cps.Items[i]) {
+			if name == secret.GetName() {
+				requests = append(requests, reconcile.Request{
+					NamespacedName: types.NamespacedName{Name: cps.Items[i].Name, Namespace: cps.Items[i].Namespace},
+				})
+
+				break
+			}
+		}
+	}
+
+	return requests
+}
@@ -0,0 +1,387 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+const (
+	eventReasonDrifted = "Drifted"
+
+	// appliedSpecAnnotation holds the spec the operator applied to a workload, Service or Ingress
+	appliedSpecAnnotation = "datasance.com/applied-spec"
+	// appliedDataHashAnnotation holds the hash of the data the operator applied to a ConfigMap or Secret
+	appliedDataHashAnnotation = "datasance.com/applied-data-hash"
+)
+
+// Changes to owned objects that reconcile their ControlPlane. Workloads, Services and Ingresses drift when their spec
+// changes, ConfigMaps and Secrets when their data changes, claims only when they are deleted.
+var (
+	specChangedPredicate = predicate.GenerationChangedPredicate{}
+
+	serviceChangedPredicate = predicate.Funcs{
+		CreateFunc: func(event.CreateEvent) bool { return false },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldService, oldOk := e.ObjectOld.(*corev1.Service)
+			newService, newOk := e.ObjectNew.(*corev1.Service)
+
+			return oldOk && newOk && !equality.Semantic.DeepEqual(oldService.Spec, newService.Spec)
+		},
+	}
+
+	dataChangedPredicate = predicate.Funcs{
+		CreateFunc: func(event.CreateEvent) bool { return false },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldHash, oldErr := getDataHash(e.ObjectOld)
+			newHash, newErr := getDataHash(e.ObjectNew)
+
+			return oldErr != nil || newErr != nil || oldHash != newHash
+		},
+		GenericFunc: func(event.GenericEvent) bool { return false },
+	}
+
+	deletedPredicate = predicate.Funcs{
+		CreateFunc:  func(event.CreateEvent) bool { return false },
+		UpdateFunc:  func(event.UpdateEvent) bool { return false },
+		GenericFunc: func(event.GenericEvent) bool { return false },
+	}
+)
+
+// getSpec returns the spec of the kinds whose spec drift is detected on, nil for other kinds
+func getSpec(obj client.Object) interface{} {
+	switch o := obj.(type) {
+	case *appsv1.Deployment:
+		// Replicas of autoscaled Deployments are kept, scaling is no drift
+		spec := o.Spec.DeepCopy()
+		spec.Replicas = nil
+
+		return spec
+	case *appsv1.StatefulSet:
+		return &o.Spec
+	case *corev1.Service:
+		return &o.Spec
+	case *networkingv1.Ingress:
+		return &o.Spec
+	default:
+		return nil
+	}
+}
+
+// getDataHash returns the hash of the data of a ConfigMap or Secret, string data is hashed as the data it is stored as
+func getDataHash(obj client.Object) (string, error) {
+	var data interface{}
+
+	switch o := obj.(type) {
+	case *corev1.ConfigMap:
+		if len(o.Data) == 0 && len(o.BinaryData) == 0 {
+			return "", nil
+		}
+
+		data = []interface{}{o.Data, o.BinaryData}
+	case *corev1.Secret:
+		merged := map[string][]byte{}
+		for key, value := range o.Data {
+			merged[key] = value
+		}
+
+		for key, value := range o.StringData {
+			merged[key] = []byte(value)
+		}
+
+		if len(merged) == 0 {
+			return "", nil
+		}
+
+		data = merged
+	default:
+		return "", nil
+	}
+
+	value, err := json.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+
+	return getHash(string(value)), nil
+}
+
+// setAppliedState records the desired state of obj on it before it is applied, obj is restored when it drifts from it
+func setAppliedState(obj client.Object) error {
+	annotation, value := "", ""
+
+	switch o := obj.(type) {
+	case *corev1.ConfigMap, *corev1.Secret:
+		hash, err := getDataHash(o)
+		if err != nil {
+			return err
+		}
+
+		annotation, value = appliedDataHashAnnotation, hash
+	default:
+		spec := getSpec(obj)
+		if spec == nil {
+			return nil
+		}
+
+		data, err := json.Marshal(spec)
+		if err != nil {
+			return err
+		}
+
+		annotation, value = appliedSpecAnnotation, string(data)
+	}
+
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+
+	annotations[annotation] = value
+	obj.SetAnnotations(annotations)
+
+	return nil
+}
+
+// isDrifted reports whether obj differs from the state the operator applied to it.
+// Only the fields of the applied spec are compared, the API server defaults the fields the operator leaves unset.
+func isDrifted(obj client.Object) (bool, error) {
+	if applied, found := obj.GetAnnotations()[appliedDataHashAnnotation]; found {
+		hash, err := getDataHash(obj)
+
+		return hash != applied, err
+	}
+
+	applied, found := obj.GetAnnotations()[appliedSpecAnnotation]
+	spec := getSpec(obj)
+
+	if !found || spec == nil {
+		return false, nil
+	}
+
+	var appliedSpec interface{}
+	if err := json.Unmarshal([]byte(applied), &appliedSpec); err != nil {
+		return false, err
+	}
+
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return false, err
+	}
+
+	var currentSpec interface{}
+	if err := json.Unmarshal(data, &currentSpec); err != nil {
+		return false, err
+	}
+
+	return !containsFields(currentSpec, appliedSpec), nil
+}
+
+// containsFields reports whether the decoded JSON value current holds every field set in applied with the same value.
+// Zero numbers and empty strings are unset, e.g. the target port of a Service port is marshalled as 0 when it is not set.
+func containsFields(current, applied interface{}) bool {
+	switch a := applied.(type) {
+	case nil:
+		return true
+	case float64:
+		return a == 0 || current == applied
+	case string:
+		return a == "" || current == applied
+	case map[string]interface{}:
+		c, ok := current.(map[string]interface{})
+		if !ok {
+			return len(a) == 0
+		}
+
+		for key, value := range a {
+			if !containsFields(c[key], value) {
+				return false
+			}
+		}
+
+		return true
+	case []interface{}:
+		c, ok := current.([]interface{})
+		if !ok {
+			return len(a) == 0
+		}
+
+		if len(c) != len(a) {
+			return false
+		}
+
+		for i := range a {
+			if !containsFields(c[i], a[i]) {
+				return false
+			}
+		}
+
+		return true
+	default:
+		return current == applied
+	}
+}
+
+// recordDeletedObject remembers an owned object that was deleted until its ControlPlane is reconciled
+func (r *ControlPlaneReconciler) recordDeletedObject(obj client.Object) {
+	owner := metav1.GetControllerOf(obj)
+	if owner == nil || owner.Kind != "ControlPlane" {
+		return
+	}
+
+	gvk, err := apiutil.GVKForObject(obj, r.Scheme)
+	if err != nil {
+		return
+	}
+
+	r.deletedLock.Lock()
+	defer r.deletedLock.Unlock()
+
+	key := types.NamespacedName{Namespace: obj.GetNamespace(), Name: owner.Name}
+	if r.deletedObjects == nil {
+		r.deletedObjects = map[types.NamespacedName]map[string]client.Object{}
+	}
+
+	if r.deletedObjects[key] == nil {
+		r.deletedObjects[key] = map[string]client.Object{}
+	}
+
+	r.deletedObjects[key][fmt.Sprintf("%s %s", gvk.Kind, obj.GetName())] = obj
+}
+
+// deletionRecorder passes every event and records the deleted owned objects
+func (r *ControlPlaneReconciler) deletionRecorder() predicate.Funcs {
+	return predicate.Funcs{
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			r.recordDeletedObject(e.Object)
+
+			return true
+		},
+	}
+}
+
+// forgetDeletedObjects drops the deleted objects of a ControlPlane once they were restored or the ControlPlane is gone
+func (r *ControlPlaneReconciler) forgetDeletedObjects(key types.NamespacedName) {
+	r.deletedLock.Lock()
+	defer r.deletedLock.Unlock()
+
+	delete(r.deletedObjects, key)
+}
+
+// getDriftedObjects returns the owned objects that were deleted or that differ from the state the operator applied
+func (r *ControlPlaneReconciler) getDriftedObjects(ctx context.Context) ([]string, error) {
+	drifted, err := r.getDeletedObjects(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	changed, err := r.getChangedObjects(ctx, r.Client)
+	if err != nil {
+		return nil, err
+	}
+
+	// Confirm the changes, the cache may lag behind
+	if len(changed) > 0 {
+		if changed, err = r.getChangedObjects(ctx, r.APIReader); err != nil {
+			return nil, err
+		}
+	}
+
+	drifted = append(drifted, changed...)
+	sort.Strings(drifted)
+
+	return drifted, nil
+}
+
+// getDeletedObjects returns the recorded deleted objects that were not recreated since
+func (r *ControlPlaneReconciler) getDeletedObjects(ctx context.Context) ([]string, error) {
+	r.deletedLock.Lock()
+	recorded := map[string]client.Object{}
+	for name, obj := range r.deletedObjects[types.NamespacedName{Namespace: r.cp.Namespace, Name: r.cp.Name}] {
+		recorded[name] = obj
+	}
+	r.deletedLock.Unlock()
+
+	deleted := []string{}
+
+	for name, obj := range recorded {
+		found, ok := obj.DeepCopyObject().(client.Object)
+		if !ok {
+			continue
+		}
+
+		err := r.APIReader.Get(ctx, client.ObjectKeyFromObject(obj), found)
+		if err == nil {
+			continue
+		}
+
+		if !k8serrors.IsNotFound(err) {
+			return nil, err
+		}
+
+		deleted = append(deleted, fmt.Sprintf("%s deleted", name))
+	}
+
+	return deleted, nil
+}
+
+// getChangedObjects returns the owned objects that differ from the state the operator applied to them
+func (r *ControlPlaneReconciler) getChangedObjects(ctx context.Context, reader client.Reader) ([]string, error) {
+	lists := []client.ObjectList{
+		&appsv1.DeploymentList{},
+		&appsv1.StatefulSetList{},
+		&corev1.ServiceList{},
+		&corev1.ConfigMapList{},
+		&corev1.SecretList{},
+		&networkingv1.IngressList{},
+	}
+
+	changed := []string{}
+
+	for _, list := range lists {
+		if err := reader.List(ctx, list, client.InNamespace(r.cp.Namespace)); err != nil {
+			return nil, err
+		}
+
+		err := meta.EachListItem(list, func(item runtime.Object) error {
+			obj, ok := item.(client.Object)
+			if !ok || !metav1.IsControlledBy(obj, &r.cp) {
+				return nil
+			}
+
+			drifted, err := isDrifted(obj)
+			if err != nil || !drifted {
+				return err
+			}
+
+			gvk, err := apiutil.GVKForObject(obj, r.Scheme)
+			if err != nil {
+				return err
+			}
+
+			changed = append(changed, fmt.Sprintf("%s %s changed", gvk.Kind, obj.GetName()))
+
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return changed, nil
+}
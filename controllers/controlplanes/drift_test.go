@@ -0,0 +1,84 @@
+package controllers
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestIsDriftedSpec(t *testing.T) {
+	applied := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "router", Namespace: testNamespace},
+		Spec: corev1.ServiceSpec{
+			Type:     corev1.ServiceTypeLoadBalancer,
+			Selector: map[string]string{"name": "router"},
+			Ports:    []corev1.ServicePort{{Name: "edge", Port: 45671}},
+		},
+	}
+
+	if err := setAppliedState(applied); err != nil {
+		t.Fatal(err)
+	}
+
+	// The API server defaults the fields the operator leaves unset
+	live := applied.DeepCopy()
+	live.Spec.ClusterIP = "10.0.0.1"
+	live.Spec.SessionAffinity = corev1.ServiceAffinityNone
+	live.Spec.Ports[0].Protocol = corev1.ProtocolTCP
+	live.Spec.Ports[0].NodePort = 30123
+	live.Spec.Ports[0].TargetPort = intstr.FromInt32(45671)
+
+	for name, tc := range map[string]struct {
+		mutate func(*corev1.Service)
+		want   bool
+	}{
+		"defaulted": {mutate: func(*corev1.Service) {}, want: false},
+		"changed":   {mutate: func(svc *corev1.Service) { svc.Spec.Ports[0].Port = 443 }, want: true},
+		"removed":   {mutate: func(svc *corev1.Service) { svc.Spec.Selector = nil }, want: true},
+		"added":     {mutate: func(svc *corev1.Service) { svc.Spec.Ports = append(svc.Spec.Ports, corev1.ServicePort{Port: 80}) }, want: true},
+		"untracked": {mutate: func(svc *corev1.Service) { svc.Annotations = nil; svc.Spec.Ports = nil }, want: false},
+	} {
+		t.Run(name, func(t *testing.T) {
+			svc := live.DeepCopy()
+			tc.mutate(svc)
+
+			drifted, err := isDrifted(svc)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if drifted != tc.want {
+				t.Errorf("expected drifted %t, got %t", tc.want, drifted)
+			}
+		})
+	}
+}
+
+func TestIsDriftedData(t *testing.T) {
+	applied := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "credentials", Namespace: testNamespace},
+		StringData: map[string]string{"password": "secret"},
+	}
+
+	if err := setAppliedState(applied); err != nil {
+		t.Fatal(err)
+	}
+
+	// String data is stored as data
+	live := &corev1.Secret{
+		ObjectMeta: *applied.ObjectMeta.DeepCopy(),
+		Data:       map[string][]byte{"password": []byte("secret")},
+	}
+
+	if drifted, err := isDrifted(live); err != nil || drifted {
+		t.Fatalf("expected the stored data not to drift, got %t, %v", drifted, err)
+	}
+
+	live.Data["password"] = []byte("changed")
+
+	if drifted, err := isDrifted(live); err != nil || !drifted {
+		t.Fatalf("expected the changed data to drift, got %t, %v", drifted, err)
+	}
+}
@@ -0,0 +1,43 @@
+package controllers
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Reasons of the Events recorded on ControlPlanes
+const (
+	eventReasonCreated                   = "Created"
+	eventReasonUpdated                   = "Updated"
+	eventReasonWaitingForLoadBalancer    = "WaitingForLoadBalancer"
+	eventReasonLoadBalancerReady         = "LoadBalancerReady"
+	eventReasonLoadBalancerTimeout       = "LoadBalancerTimeout"
+	eventReasonLoginFailed               = "LoginFailed"
+	eventReasonNatsHubRegistrationFailed = "NatsHubRegistrationFailed"
+	eventReasonCAImportFailed            = "CAImportFailed"
+	eventReasonUpdating                  = "Updating"
+	eventReasonReady                     = "Ready"
+)
+
+// recordEvent records a Normal Event on the ControlPlane
+func (r *ControlPlaneReconciler) recordEvent(reason, messageFmt string, args ...interface{}) {
+	r.Recorder.Eventf(&r.cp, corev1.EventTypeNormal, reason, messageFmt, args...)
+}
+
+// recordWarning records a Warning Event on the ControlPlane
+func (r *ControlPlaneReconciler) recordWarning(reason, messageFmt string, args ...interface{}) {
+	r.Recorder.Eventf(&r.cp, corev1.EventTypeWarning, reason, messageFmt, args...)
+}
+
+// recordCreated records the creation of a resource of the ControlPlane
+func (r *ControlPlaneReconciler) recordCreated(kind, name string) {
+	r.recordEvent(eventReasonCreated, "Created %s %s", kind, name)
+}
+
+// recordUpdated records an update of a resource of the ControlPlane, updates without changes keep the resource version
+func (r *ControlPlaneReconciler) recordUpdated(kind, name, previousVersion, version string) {
+	if previousVersion == version {
+		return
+	}
+
+	r.recordEvent(eventReasonUpdated, "Updated %s %s", kind, name)
+}
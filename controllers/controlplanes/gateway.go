@@ -0,0 +1,397 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	cpv3 "github.com/datasance/iofog-operator/v3/apis/controlplanes/v3"
+	"github.com/datasance/iofog-operator/v3/controllers/controlplanes/nats"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Gateway API objects are handled unstructured, the Gateway API CRDs are optional in the cluster
+const (
+	gatewayAPIGroup = "gateway.networking.k8s.io"
+
+	controllerRouteName     = "controller"
+	routerEdgeRouteName     = "router-edge"
+	routerInteriorRouteName = "router-interior"
+	natsLeafRouteName       = "nats-leaf"
+	natsMqttRouteName       = "nats-mqtt"
+)
+
+var (
+	gatewayGVK   = schema.GroupVersionKind{Group: gatewayAPIGroup, Version: "v1", Kind: "Gateway"}
+	httpRouteGVK = schema.GroupVersionKind{Group: gatewayAPIGroup, Version: "v1", Kind: "HTTPRoute"}
+	tcpRouteGVK  = schema.GroupVersionKind{Group: gatewayAPIGroup, Version: "v1alpha2", Kind: cpv3.GatewayTCPRoute}
+	tlsRouteGVK  = schema.GroupVersionKind{Group: gatewayAPIGroup, Version: "v1alpha2", Kind: cpv3.GatewayTLSRoute}
+)
+
+// gatewayListener is a listener of the referenced Gateway
+type gatewayListener struct {
+	port     int
+	protocol string
+}
+
+// gatewayStatus is what the routes need to know about the referenced Gateway
+type gatewayStatus struct {
+	address   string
+	listeners map[string]gatewayListener
+}
+
+// getGatewayStatus returns the address and listeners of spec.gateway, it fails until the Gateway has an address.
+// The Gateway is read once per reconcile.
+func (r *ControlPlaneReconciler) getGatewayStatus(ctx context.Context) (*gatewayStatus, error) {
+	r.gatewayLock.Lock()
+	defer r.gatewayLock.Unlock()
+
+	if r.gateway != nil {
+		return r.gateway, nil
+	}
+
+	status, err := r.readGatewayStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.gateway = status
+
+	return status, nil
+}
+
+func (r *ControlPlaneReconciler) readGatewayStatus(ctx context.Context) (*gatewayStatus, error) {
+	ref := r.cp.Spec.Gateway
+
+	gw := &unstructured.Unstructured{}
+	gw.SetGroupVersionKind(gatewayGVK)
+
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: ref.Namespace}, gw); err != nil {
+		return nil, fmt.Errorf("get Gateway %s/%s: %w", ref.Namespace, ref.Name, err)
+	}
+
+	status := &gatewayStatus{listeners: map[string]gatewayListener{}}
+
+	listeners, _, _ := unstructured.NestedSlice(gw.Object, "spec", "listeners")
+	for _, l := range listeners {
+		listener, ok := l.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		name, _, _ := unstructured.NestedString(listener, "name")
+		port, _, _ := unstructured.NestedInt64(listener, "port")
+		protocol, _, _ := unstructured.NestedString(listener, "protocol")
+		status.listeners[name] = gatewayListener{port: int(port), protocol: protocol}
+	}
+
+	addresses, _, _ := unstructured.NestedSlice(gw.Object, "status", "addresses")
+	for _, a := range addresses {
+		if address, ok := a.(map[string]interface{}); ok {
+			if value, _, _ := unstructured.NestedString(address, "value"); value != "" {
+				status.address = value
+
+				break
+			}
+		}
+	}
+
+	if status.address == "" {
+		return nil, fmt.Errorf("Gateway %s/%s has no address yet", ref.Namespace, ref.Name)
+	}
+
+	return status, nil
+}
+
+// getListenerPort returns the port of a listener, routes can only be advertised on listeners of the Gateway
+func (s *gatewayStatus) getListenerPort(name string) (int, error) {
+	listener, found := s.listeners[name]
+	if !found {
+		return 0, fmt.Errorf("Gateway has no listener %s", name)
+	}
+
+	return listener.port, nil
+}
+
+// getAdvertisedAddress returns the first hostname of a route, or the Gateway address without hostnames
+func (s *gatewayStatus) getAdvertisedAddress(hostnames []string) string {
+	if len(hostnames) != 0 {
+		return hostnames[0]
+	}
+
+	return s.address
+}
+
+// getHTTPScheme returns the scheme clients use to reach an HTTPRoute on the listener, all listeners when empty
+func (s *gatewayStatus) getHTTPScheme(listener string) string {
+	for name, l := range s.listeners {
+		if (listener == "" || name == listener) && l.protocol == "HTTPS" {
+			return "https"
+		}
+	}
+
+	return "http"
+}
+
+// getRouterGatewayIngress returns the Router address and ports registered with the Controller
+func (r *ControlPlaneReconciler) getRouterGatewayIngress(ctx context.Context) (cpv3.RouterIngress, error) {
+	routes := r.cp.Spec.Gateway.Router
+
+	gw, err := r.getGatewayStatus(ctx)
+	if err != nil {
+		return cpv3.RouterIngress{}, err
+	}
+
+	edgePort, err := gw.getListenerPort(routes.EdgeListener)
+	if err != nil {
+		return cpv3.RouterIngress{}, err
+	}
+
+	interiorPort, err := gw.getListenerPort(routes.InteriorListener)
+	if err != nil {
+		return cpv3.RouterIngress{}, err
+	}
+
+	return cpv3.RouterIngress{
+		Address:      gw.getAdvertisedAddress(routes.Hostnames),
+		MessagePort:  r.cp.Spec.Router.Ports.MessagePort,
+		InteriorPort: interiorPort,
+		EdgePort:     edgePort,
+	}, nil
+}
+
+// getNatsGatewayIngress returns the NATS address and ports registered with the Controller
+func (r *ControlPlaneReconciler) getNatsGatewayIngress(ctx context.Context) (cpv3.NatsIngress, error) {
+	routes := r.cp.Spec.Gateway.Nats
+	ports := getNatsPorts(r.cp.Spec.Nats)
+
+	gw, err := r.getGatewayStatus(ctx)
+	if err != nil {
+		return cpv3.NatsIngress{}, err
+	}
+
+	leafPort, err := gw.getListenerPort(routes.LeafListener)
+	if err != nil {
+		return cpv3.NatsIngress{}, err
+	}
+
+	mqttPort, err := gw.getListenerPort(routes.MqttListener)
+	if err != nil {
+		return cpv3.NatsIngress{}, err
+	}
+
+	return cpv3.NatsIngress{
+		Address:     gw.getAdvertisedAddress(routes.Hostnames),
+		ServerPort:  ports.ServerPort,
+		ClusterPort: ports.ClusterPort,
+		LeafPort:    leafPort,
+		MqttPort:    mqttPort,
+		HttpPort:    ports.HttpPort,
+	}, nil
+}
+
+// reconcileControllerRoute creates the HTTPRoute of the Controller or removes it when it is not configured.
+// The API and the ECN Viewer are routed by path to the ports of the Controller Service.
+func (r *ControlPlaneReconciler) reconcileControllerRoute(ctx context.Context, ms *microservice) error {
+	if !isControllerGatewayEnabled(r.cp) {
+		return r.deleteGatewayRoute(ctx, httpRouteGVK, controllerRouteName)
+	}
+
+	paths := map[string]string{
+		"controller-api": "/api/v3",
+		"ecn-viewer":     "/",
+	}
+
+	rules := []interface{}{}
+
+	for _, port := range ms.services[0].ports {
+		if path, found := paths[port.Name]; found {
+			rules = append(rules, newHTTPRouteRule(path, ms.services[0].name, int64(port.Port)))
+		}
+	}
+
+	cfg := r.cp.Spec.Gateway.Controller
+	route := r.newGatewayRoute(httpRouteGVK, controllerRouteName, "controller", cfg.Listener, cfg.Hostnames)
+
+	if err := unstructured.SetNestedSlice(route.Object, rules, "spec", "rules"); err != nil {
+		return err
+	}
+
+	return r.createGatewayRoute(ctx, route)
+}
+
+// reconcileRouterRoutes creates the routes of the router edge and interior listeners or removes them
+func (r *ControlPlaneReconciler) reconcileRouterRoutes(ctx context.Context) error {
+	if !isRouterGatewayEnabled(r.cp) {
+		if err := r.deleteGatewayL4Routes(ctx, routerEdgeRouteName); err != nil {
+			return err
+		}
+
+		return r.deleteGatewayL4Routes(ctx, routerInteriorRouteName)
+	}
+
+	cfg := r.cp.Spec.Gateway.Router
+	ports := r.cp.Spec.Router.Ports
+
+	if err := r.createGatewayL4Route(ctx, cfg.Kind, routerEdgeRouteName, routerName, cfg.EdgeListener, cfg.Hostnames, ports.EdgePort); err != nil {
+		return err
+	}
+
+	return r.createGatewayL4Route(ctx, cfg.Kind, routerInteriorRouteName, routerName, cfg.InteriorListener, cfg.Hostnames, ports.InteriorPort)
+}
+
+// reconcileNatsRoutes creates the routes of the NATS leaf and mqtt listeners or removes them
+func (r *ControlPlaneReconciler) reconcileNatsRoutes(ctx context.Context, ports nats.Ports) error {
+	if !isNatsGatewayEnabled(r.cp) {
+		if err := r.deleteGatewayL4Routes(ctx, natsLeafRouteName); err != nil {
+			return err
+		}
+
+		return r.deleteGatewayL4Routes(ctx, natsMqttRouteName)
+	}
+
+	cfg := r.cp.Spec.Gateway.Nats
+
+	if err := r.createGatewayL4Route(ctx, cfg.Kind, natsLeafRouteName, nats.ClientServiceName, cfg.LeafListener, cfg.Hostnames, ports.LeafPort); err != nil {
+		return err
+	}
+
+	return r.createGatewayL4Route(ctx, cfg.Kind, natsMqttRouteName, nats.ClientServiceName, cfg.MqttListener, cfg.Hostnames, ports.MqttPort)
+}
+
+// createGatewayL4Route creates a TCPRoute or TLSRoute to a Service port and removes a route of the other kind
+func (r *ControlPlaneReconciler) createGatewayL4Route(ctx context.Context, kind, name, serviceName, listener string, hostnames []string, port int) error {
+	gvk, staleGVK := tcpRouteGVK, tlsRouteGVK
+	if kind == cpv3.GatewayTLSRoute {
+		gvk, staleGVK = tlsRouteGVK, tcpRouteGVK
+	}
+
+	if err := r.deleteGatewayRoute(ctx, staleGVK, name); err != nil {
+		return err
+	}
+
+	// TCPRoutes cannot match on hostnames
+	if kind != cpv3.GatewayTLSRoute {
+		hostnames = nil
+	}
+
+	route := r.newGatewayRoute(gvk, name, serviceName, listener, hostnames)
+	rules := []interface{}{
+		map[string]interface{}{
+			"backendRefs": []interface{}{newBackendRef(serviceName, int64(port))},
+		},
+	}
+
+	if err := unstructured.SetNestedSlice(route.Object, rules, "spec", "rules"); err != nil {
+		return err
+	}
+
+	return r.createGatewayRoute(ctx, route)
+}
+
+func (r *ControlPlaneReconciler) newGatewayRoute(gvk schema.GroupVersionKind, name, component, listener string, hostnames []string) *unstructured.Unstructured {
+	parentRef := map[string]interface{}{
+		"name":      r.cp.Spec.Gateway.Name,
+		"namespace": r.cp.Spec.Gateway.Namespace,
+	}
+	if listener != "" {
+		parentRef["sectionName"] = listener
+	}
+
+	route := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"parentRefs": []interface{}{parentRef},
+		},
+	}}
+	route.SetGroupVersionKind(gvk)
+	route.SetName(name)
+	route.SetNamespace(r.cp.Namespace)
+	route.SetLabels(getStandardLabels(component, r.cp.Name))
+
+	if len(hostnames) != 0 {
+		values := make([]interface{}, 0, len(hostnames))
+		for _, hostname := range hostnames {
+			values = append(values, hostname)
+		}
+
+		_ = unstructured.SetNestedSlice(route.Object, values, "spec", "hostnames")
+	}
+
+	return route
+}
+
+func newHTTPRouteRule(path, serviceName string, port int64) map[string]interface{} {
+	return map[string]interface{}{
+		"matches": []interface{}{
+			map[string]interface{}{
+				"path": map[string]interface{}{
+					"type":  "PathPrefix",
+					"value": path,
+				},
+			},
+		},
+		"backendRefs": []interface{}{newBackendRef(serviceName, port)},
+	}
+}
+
+func newBackendRef(serviceName string, port int64) map[string]interface{} {
+	return map[string]interface{}{
+		"name": serviceName,
+		"port": port,
+	}
+}
+
+func (r *ControlPlaneReconciler) createGatewayRoute(ctx context.Context, route *unstructured.Unstructured) error {
+	_, err := r.applyObject(ctx, route)
+
+	return err
+}
+
+func (r *ControlPlaneReconciler) deleteGatewayL4Routes(ctx context.Context, name string) error {
+	if err := r.deleteGatewayRoute(ctx, tcpRouteGVK, name); err != nil {
+		return err
+	}
+
+	return r.deleteGatewayRoute(ctx, tlsRouteGVK, name)
+}
+
+// deleteGatewayRoute removes a route of the ControlPlane, clusters without the Gateway API CRDs have nothing to remove.
+// Routes of the same name that the ControlPlane does not own are left alone.
+func (r *ControlPlaneReconciler) deleteGatewayRoute(ctx context.Context, gvk schema.GroupVersionKind, name string) error {
+	route := &unstructured.Unstructured{}
+	route.SetGroupVersionKind(gvk)
+
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: name, Namespace: r.cp.Namespace}, route); err != nil {
+		if k8serrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			return nil
+		}
+
+		return err
+	}
+
+	if !metav1.IsControlledBy(route, &r.cp) {
+		return nil
+	}
+
+	if err := r.Client.Delete(ctx, route); err != nil && !k8serrors.IsNotFound(err) {
+		return err
+	}
+
+	return nil
+}
+
+func isControllerGatewayEnabled(cp cpv3.ControlPlane) bool {
+	return cp.Spec.Gateway != nil && cp.Spec.Gateway.Controller != nil
+}
+
+func isRouterGatewayEnabled(cp cpv3.ControlPlane) bool {
+	return cp.Spec.Gateway != nil && cp.Spec.Gateway.Router != nil
+}
+
+func isNatsGatewayEnabled(cp cpv3.ControlPlane) bool {
+	return cp.Spec.Gateway != nil && cp.Spec.Gateway.Nats != nil
+}
@@ -0,0 +1,337 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	cpv3 "github.com/datasance/iofog-operator/v3/apis/controlplanes/v3"
+	"github.com/datasance/iofog-operator/v3/controllers/controlplanes/nats"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	cond "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// healthCheckInterval is how often a ready ControlPlane is checked
+	healthCheckInterval = 30 * time.Second
+	// degradedGracePeriod is how long a ControlPlane is degraded before it is redeployed, at most once per period
+	degradedGracePeriod = 2 * time.Minute
+	healthProbeTimeout  = 5 * time.Second
+
+	conditionReasonHealthy   = "Healthy"
+	conditionReasonUnhealthy = "Unhealthy"
+
+	eventReasonDegraded  = "Degraded"
+	eventReasonRecovered = "Recovered"
+	eventReasonRepairing = "Repairing"
+)
+
+// componentHealth collects the problems the health checks found for a component
+type componentHealth struct {
+	conditionType string
+	// reason of the Degraded condition when the component is unhealthy
+	reason   string
+	problems []string
+}
+
+func (h *componentHealth) addProblem(problem string) {
+	if problem != "" {
+		h.problems = append(h.problems, problem)
+	}
+}
+
+// checkHealth checks the workloads, Services and health endpoints of the components, sets their conditions and
+// the Degraded condition, and reports whether the ControlPlane has been degraded long enough to be redeployed
+func (r *ControlPlaneReconciler) checkHealth(ctx context.Context) (repair bool, err error) {
+	components := []*componentHealth{}
+
+	controller, err := r.checkControllerHealth(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	components = append(components, controller)
+
+	router, err := r.checkRouterHealth(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	components = append(components, router)
+
+	if isNatsEnabled(r.cp) {
+		natsHealth, err := r.checkNatsHealth(ctx)
+		if err != nil {
+			return false, err
+		}
+
+		components = append(components, natsHealth)
+	}
+
+	r.setDependencyConditions()
+
+	reason := ""
+	problems := []string{}
+
+	for _, component := range components {
+		if len(component.problems) == 0 {
+			r.cp.SetComponentCondition(component.conditionType, true, conditionReasonHealthy, "")
+
+			continue
+		}
+
+		r.cp.SetComponentCondition(component.conditionType, false, conditionReasonUnhealthy, strings.Join(component.problems, "; "))
+
+		if reason == "" {
+			reason = component.reason
+		}
+
+		problems = append(problems, component.problems...)
+	}
+
+	wasDegraded := cond.IsStatusConditionTrue(r.cp.Status.Conditions, cpv3.ConditionDegraded)
+
+	if reason == "" {
+		r.cp.SetComponentCondition(cpv3.ConditionDegraded, false, conditionReasonHealthy, "")
+		setDegradedMetric(&r.cp, false)
+
+		if wasDegraded {
+			r.recordEvent(eventReasonRecovered, "ControlPlane recovered")
+		}
+
+		return false, nil
+	}
+
+	message := strings.Join(problems, "; ")
+	r.cp.SetComponentCondition(cpv3.ConditionDegraded, true, reason, message)
+	setDegradedMetric(&r.cp, true)
+
+	if !wasDegraded {
+		r.log.Info(fmt.Sprintf("ControlPlane %s is degraded: %s", r.cp.Name, message))
+		r.recordWarning(eventReasonDegraded, "ControlPlane is degraded: %s", message)
+	}
+
+	// Redeploy once the ControlPlane was degraded for the grace period, a redeploy restarts the period
+	degradedSince := cond.FindStatusCondition(r.cp.Status.Conditions, cpv3.ConditionDegraded).LastTransitionTime.Time
+
+	return time.Since(degradedSince) >= degradedGracePeriod && time.Since(r.cp.ReadySince()) >= degradedGracePeriod, nil
+}
+
+func (r *ControlPlaneReconciler) checkControllerHealth(ctx context.Context) (*componentHealth, error) {
+	health := &componentHealth{conditionType: cpv3.ConditionControllerReady, reason: "ControllerUnavailable"}
+
+	problem, err := r.checkWorkloadAvailable(ctx, &appsv1.Deployment{}, controllerName)
+	if err != nil {
+		return nil, err
+	}
+
+	health.addProblem(problem)
+
+	if strings.EqualFold(r.cp.Spec.Services.Controller.Type, string(corev1.ServiceTypeLoadBalancer)) {
+		problem, err := r.checkLoadBalancer(ctx, controllerName)
+		if err != nil {
+			return nil, err
+		}
+
+		health.addProblem(problem)
+	}
+
+	if _, recon := r.getControllerServiceClient(); recon.IsFinal() {
+		health.addProblem("Controller status API is not reachable")
+	}
+
+	return health, nil
+}
+
+func (r *ControlPlaneReconciler) checkRouterHealth(ctx context.Context) (*componentHealth, error) {
+	health := &componentHealth{conditionType: cpv3.ConditionRouterReady, reason: "RouterUnavailable"}
+
+	for _, name := range getRouterNames(r.cp) {
+		problem, err := r.checkWorkloadAvailable(ctx, &appsv1.Deployment{}, name)
+		if err != nil {
+			return nil, err
+		}
+
+		health.addProblem(problem)
+	}
+
+	if !isRouterGatewayEnabled(r.cp) && strings.EqualFold(r.cp.Spec.Services.Router.Type, string(corev1.ServiceTypeLoadBalancer)) {
+		problem, err := r.checkLoadBalancer(ctx, routerName)
+		if err != nil {
+			return nil, err
+		}
+
+		health.addProblem(problem)
+	}
+
+	problems, err := r.probePods(ctx, "router", r.cp.Spec.Router.Ports.HTTPPort, "/healthz")
+	if err != nil {
+		return nil, err
+	}
+
+	health.problems = append(health.problems, problems...)
+
+	return health, nil
+}
+
+func (r *ControlPlaneReconciler) checkNatsHealth(ctx context.Context) (*componentHealth, error) {
+	health := &componentHealth{conditionType: cpv3.ConditionNatsReady, reason: "NatsUnavailable"}
+
+	problem, err := r.checkWorkloadAvailable(ctx, &appsv1.StatefulSet{}, "nats")
+	if err != nil {
+		return nil, err
+	}
+
+	health.addProblem(problem)
+
+	// JetStream needs a majority of the servers
+	replicas := r.cp.Spec.Replicas.Nats
+	if replicas < cpv3.DefaultNatsReplicas {
+		replicas = cpv3.DefaultNatsReplicas
+	}
+
+	quorum := replicas/2 + 1
+
+	ready, err := r.getReadyReplicas(ctx, &appsv1.StatefulSet{}, "nats")
+	if err != nil {
+		return nil, err
+	}
+
+	if ready < quorum {
+		health.reason = "NatsQuorumLost"
+		health.addProblem(fmt.Sprintf("NATS lost quorum, %d of %d servers are ready", ready, replicas))
+	}
+
+	if !isNatsGatewayEnabled(r.cp) && strings.EqualFold(r.cp.Spec.Services.Nats.Type, string(corev1.ServiceTypeLoadBalancer)) {
+		problem, err := r.checkLoadBalancer(ctx, nats.ClientServiceName)
+		if err != nil {
+			return nil, err
+		}
+
+		health.addProblem(problem)
+	}
+
+	problems, err := r.probePods(ctx, "nats", getNatsPorts(r.cp.Spec.Nats).HttpPort, "/healthz?js-enabled-only=true")
+	if err != nil {
+		return nil, err
+	}
+
+	health.problems = append(health.problems, problems...)
+
+	return health, nil
+}
+
+// checkWorkloadAvailable returns a problem when a Deployment or StatefulSet is missing or has fewer available replicas than desired
+func (r *ControlPlaneReconciler) checkWorkloadAvailable(ctx context.Context, workload client.Object, name string) (string, error) {
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: name, Namespace: r.cp.Namespace}, workload); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return fmt.Sprintf("%s %s not found", getWorkloadKind(workload), name), nil
+		}
+
+		return "", err
+	}
+
+	var desired *int32
+
+	var available int32
+
+	switch w := workload.(type) {
+	case *appsv1.Deployment:
+		desired, available = w.Spec.Replicas, w.Status.AvailableReplicas
+	case *appsv1.StatefulSet:
+		desired, available = w.Spec.Replicas, w.Status.AvailableReplicas
+	}
+
+	want := int32(1)
+	if desired != nil {
+		want = *desired
+	}
+
+	if available < want {
+		return fmt.Sprintf("%s %s has %d of %d replicas available", getWorkloadKind(workload), name, available, want), nil
+	}
+
+	return "", nil
+}
+
+func getWorkloadKind(workload client.Object) string {
+	if _, ok := workload.(*appsv1.StatefulSet); ok {
+		return "StatefulSet"
+	}
+
+	return "Deployment"
+}
+
+// checkLoadBalancer returns a problem when a LoadBalancer Service is missing or lost its address
+func (r *ControlPlaneReconciler) checkLoadBalancer(ctx context.Context, name string) (string, error) {
+	address, err := r.getLoadBalancerAddress(ctx, name)
+	if err != nil {
+		return "", err
+	}
+
+	if address == "" {
+		return fmt.Sprintf("LoadBalancer Service %s has no address", name), nil
+	}
+
+	return "", nil
+}
+
+// probePods requests the health endpoint of the running pods of a component and returns the failed ones
+func (r *ControlPlaneReconciler) probePods(ctx context.Context, component string, port int, path string) ([]string, error) {
+	pods := &corev1.PodList{}
+	if err := r.Client.List(ctx, pods, client.InNamespace(r.cp.Namespace), client.MatchingLabels(getStandardLabels(component, r.cp.Name))); err != nil {
+		return nil, err
+	}
+
+	problems := []string{}
+
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		// Pods that are not running are reported by the workload availability
+		if pod.Status.Phase != corev1.PodRunning || pod.Status.PodIP == "" || pod.DeletionTimestamp != nil || !isPodStarted(pod) {
+			continue
+		}
+
+		url := fmt.Sprintf("http://%s%s", net.JoinHostPort(pod.Status.PodIP, strconv.Itoa(port)), path)
+		if err := probeHealth(url); err != nil {
+			problems = append(problems, fmt.Sprintf("pod %s is unhealthy: %s", pod.Name, err.Error()))
+		}
+	}
+
+	return problems, nil
+}
+
+// isPodStarted reports whether the containers of a pod passed their startup, probing earlier only reports restarts
+func isPodStarted(pod *corev1.Pod) bool {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Started == nil || !*status.Started {
+			return false
+		}
+	}
+
+	return true
+}
+
+func probeHealth(url string) error {
+	httpClient := &http.Client{Timeout: healthProbeTimeout}
+
+	resp, err := httpClient.Get(url) //nolint:noctx
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("health endpoint returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
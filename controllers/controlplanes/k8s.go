@@ -8,19 +8,29 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	iofogclient "github.com/datasance/iofog-go-sdk/v3/pkg/client"
 	cpv3 "github.com/datasance/iofog-operator/v3/apis/controlplanes/v3"
+	"github.com/datasance/iofog-operator/v3/controllers/controlplanes/nats"
+	"github.com/datasance/iofog-operator/v3/controllers/controlplanes/router"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
-	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/types"
-	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+// deploymentDeleteTimeout is how long recreating a Deployment waits for the previous one and its pods to be deleted
+const deploymentDeleteTimeout = 2 * time.Minute
+
 func (r *ControlPlaneReconciler) deploymentExists(ctx context.Context, namespace, name string) (bool, error) {
 	key := types.NamespacedName{
 		Name:      name,
@@ -40,79 +50,110 @@ func (r *ControlPlaneReconciler) deploymentExists(ctx context.Context, namespace
 	return false, err
 }
 
+// restartPodsForDeployment rolls the pods of a Deployment like kubectl rollout restart.
+// The annotation is applied by restartFieldManager, so applying the Deployment keeps it and does not roll the pods again.
 func (r *ControlPlaneReconciler) restartPodsForDeployment(ctx context.Context, deploymentName, namespace string) error {
-	// Check if this resource already exists
-	found := &appsv1.Deployment{}
-	if err := r.Client.Get(ctx, types.NamespacedName{Name: deploymentName, Namespace: namespace}, found); err != nil {
-		return err
-	}
-
-	originValue := int32(1)
-	if found.Spec.Replicas == nil {
-		found.Spec.Replicas = &originValue
-	}
+	dep := &unstructured.Unstructured{}
+	dep.SetGroupVersionKind(appsv1.SchemeGroupVersion.WithKind("Deployment"))
+	dep.SetName(deploymentName)
+	dep.SetNamespace(namespace)
 
-	if err := r.Client.Update(ctx, found); err != nil {
+	annotations := map[string]string{restartedAtAnnotation: time.Now().UTC().Format(time.RFC3339)}
+	if err := unstructured.SetNestedStringMap(dep.Object, annotations, "spec", "template", "metadata", "annotations"); err != nil {
 		return err
 	}
 
-	return r.Client.Update(ctx, found)
+	return r.Client.Patch(ctx, dep, client.Apply, client.FieldOwner(restartFieldManager), client.ForceOwnership)
 }
 
 func (r *ControlPlaneReconciler) createDeployment(ctx context.Context, ms *microservice) error {
 	dep := newDeployment(r.cp.ObjectMeta.Namespace, r.cp.Name, ms)
-	// Set ControlPlane instance as the owner and controller
-	if err := controllerutil.SetControllerReference(&r.cp, dep, r.Scheme); err != nil {
-		return err
-	}
 
-	// Check if this resource already exists
 	found := &appsv1.Deployment{}
 
 	err := r.Client.Get(ctx, types.NamespacedName{Name: dep.Name, Namespace: dep.Namespace}, found)
-	if err != nil && k8serrors.IsNotFound(err) {
-		r.log.Info("Creating a new Deployment", "Deployment.Namespace", dep.Namespace, "Deployment.Name", dep.Name)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return err
+	}
 
-		err = r.Client.Create(ctx, dep)
-		if err != nil {
+	// Keep the replicas of an autoscaled Deployment
+	if err == nil && isScaledByOthers(found) {
+		dep.Spec.Replicas = nil
+	}
+
+	// The selector is immutable, e.g. the primary router selected the pods of every router before
+	if err == nil && !equality.Semantic.DeepEqual(found.Spec.Selector, dep.Spec.Selector) {
+		if err := r.recreateDeployment(ctx, found); err != nil {
 			return err
 		}
-
-		// Resource created successfully - don't requeue
-		return nil
-	} else if err != nil {
-		return err
 	}
 
-	// Resource already exists - update it
-	r.log.Info("Updating existing Deployment", "Deployment.Namespace", found.Namespace, "Deployment.Name", found.Name)
+	_, err = r.applyObject(ctx, dep)
+
+	return err
+}
+
+// recreateDeployment deletes a Deployment so that it can be created with another selector.
+// Its ReplicaSets and pods are deleted with it, the new Deployment would not adopt pods without its selector labels
+// and the Services would keep sending traffic to them.
+func (r *ControlPlaneReconciler) recreateDeployment(ctx context.Context, dep *appsv1.Deployment) error {
+	r.log.Info(fmt.Sprintf("Recreating Deployment %s for ControlPlane %s to change its selector", dep.Name, r.cp.Name))
 
-	if err := r.Client.Update(ctx, dep); err != nil {
+	if err := r.Client.Delete(ctx, dep, client.PropagationPolicy(metav1.DeletePropagationForeground)); err != nil && !k8serrors.IsNotFound(err) {
 		return err
 	}
 
-	return nil
+	return wait.PollUntilContextTimeout(ctx, time.Second, deploymentDeleteTimeout, true, func(ctx context.Context) (bool, error) {
+		err := r.Client.Get(ctx, types.NamespacedName{Name: dep.Name, Namespace: dep.Namespace}, &appsv1.Deployment{})
+		if k8serrors.IsNotFound(err) {
+			return true, nil
+		}
+
+		return false, err
+	})
 }
 
 func (r *ControlPlaneReconciler) createStatefulSet(ctx context.Context, ms *microservice) error {
-	st := newStatefulSet(r.cp.ObjectMeta.Namespace, r.cp.Name, ms)
-	if err := controllerutil.SetControllerReference(&r.cp, st, r.Scheme); err != nil {
+	_, err := r.applyObject(ctx, newStatefulSet(r.cp.ObjectMeta.Namespace, r.cp.Name, ms))
+
+	return err
+}
+
+func (r *ControlPlaneReconciler) createPodDisruptionBudget(ctx context.Context, pdb *policyv1.PodDisruptionBudget) error {
+	_, err := r.applyObject(ctx, pdb)
+
+	return err
+}
+
+func (r *ControlPlaneReconciler) deletePodDisruptionBudget(ctx context.Context, name string) error {
+	pdb := &policyv1.PodDisruptionBudget{}
+	pdb.Name = name
+	pdb.Namespace = r.cp.Namespace
+
+	if err := r.Client.Delete(ctx, pdb); err != nil && !k8serrors.IsNotFound(err) {
 		return err
 	}
-	found := &appsv1.StatefulSet{}
-	err := r.Client.Get(ctx, types.NamespacedName{Name: st.Name, Namespace: st.Namespace}, found)
-	if err != nil && k8serrors.IsNotFound(err) {
-		r.log.Info("Creating a new StatefulSet", "StatefulSet.Namespace", st.Namespace, "StatefulSet.Name", st.Name)
-		return r.Client.Create(ctx, st)
-	}
-	if err != nil {
-		return err
+
+	return nil
+}
+
+// deleteRouterMicroservice removes a router which is no longer part of the ControlPlane (e.g. HA disabled)
+func (r *ControlPlaneReconciler) deleteRouterMicroservice(ctx context.Context, name string) error {
+	meta := metav1.ObjectMeta{Name: name, Namespace: r.cp.Namespace}
+	objs := []client.Object{
+		&appsv1.Deployment{ObjectMeta: meta},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: getRouterConfigMapName(name), Namespace: r.cp.Namespace}},
+		&rbacv1.RoleBinding{ObjectMeta: meta},
+		&rbacv1.Role{ObjectMeta: meta},
+		&corev1.ServiceAccount{ObjectMeta: meta},
 	}
-	r.log.Info("Updating existing StatefulSet", "StatefulSet.Namespace", found.Namespace, "StatefulSet.Name", found.Name)
 
-	if err := r.Client.Update(ctx, st); err != nil {
-		return err
+	for _, obj := range objs {
+		if err := r.Client.Delete(ctx, obj); err != nil && !k8serrors.IsNotFound(err) {
+			return err
+		}
 	}
+
 	return nil
 }
 
@@ -127,7 +168,7 @@ func (r *ControlPlaneReconciler) createPersistentVolumeClaims(ctx context.Contex
 			return err
 		}
 
-		pvc := corev1.PersistentVolumeClaim{
+		pvc := &corev1.PersistentVolumeClaim{
 			Spec: corev1.PersistentVolumeClaimSpec{
 				AccessModes: []corev1.PersistentVolumeAccessMode{
 					corev1.ReadWriteOnce,
@@ -143,31 +184,11 @@ func (r *ControlPlaneReconciler) createPersistentVolumeClaims(ctx context.Contex
 		pvc.ObjectMeta.Name = ms.volumes[i].Name
 		pvc.ObjectMeta.Namespace = r.cp.Namespace
 		pvc.ObjectMeta.Labels = getStandardLabels(getComponentFromMicroservice(ms), r.cp.Name)
-		// Set ControlPlane instance as the owner and controller
-		if err := controllerutil.SetControllerReference(&r.cp, &pvc, r.Scheme); err != nil {
-			return err
-		}
-
-		// Check if this resource already exists
-		found := &corev1.PersistentVolumeClaim{}
-
-		err = r.Client.Get(ctx, types.NamespacedName{Name: pvc.Name, Namespace: pvc.Namespace}, found)
-		if err != nil && k8serrors.IsNotFound(err) {
-			r.log.Info("Creating a new PersistentVolumeClaim", "PersistentVolumeClaim.Namespace", pvc.Namespace, "PersistentVolumeClaim.Name", pvc.Name)
 
-			err = r.Client.Create(ctx, &pvc)
-			if err != nil {
-				return err
-			}
-
-			// Resource created successfully - don't requeue
-			continue
-		} else if err != nil {
+		// The spec of a claim is immutable apart from expanding it, existing claims keep their storage
+		if err := r.createObject(ctx, pvc); err != nil {
 			return err
 		}
-
-		// Resource already exists - don't requeue
-		r.log.Info("Skip reconcile: Secret already exists", "Secret.Namespace", found.Namespace, "Secret.Name", found.Name)
 	}
 
 	return nil
@@ -178,60 +199,24 @@ func (r *ControlPlaneReconciler) createSecrets(ctx context.Context, ms *microser
 }
 
 func (r *ControlPlaneReconciler) createOrUpdateSecrets(ctx context.Context, ms *microservice, update bool) error {
-	defer func() {
-		if recoverResult := recover(); recoverResult != nil {
-			r.log.Info(fmt.Sprintf("Recover result %v for creating secrets for Controlplane %s", recoverResult, r.cp.Name))
-		}
-	}()
-
 	stdLabels := getStandardLabels(getComponentFromMicroservice(ms), r.cp.Name)
 	for i := range ms.secrets {
 		secret := &ms.secrets[i]
 		secret.Labels = mergeLabels(stdLabels, secret.Labels)
-		r.log.Info(fmt.Sprintf("Creating secret %s", secret.ObjectMeta.Name))
-		// Set ControlPlane instance as the owner and controller
-		r.log.Info(fmt.Sprintf("Setting owner reference for secret %s", secret.ObjectMeta.Name))
-
-		if err := controllerutil.SetControllerReference(&r.cp, secret, r.Scheme); err != nil {
-			r.log.Info(fmt.Sprintf("Failed to set owner reference for secret %s: %v", secret.ObjectMeta.Name, err))
-
-			return err
-		}
-
-		// Check if this resource already exists
-		r.log.Info(fmt.Sprintf("Checking if secret %s exists", secret.ObjectMeta.Name))
 
-		found := &corev1.Secret{}
+		// Secrets that are generated once are only created
+		if !update {
+			if err := r.createObject(ctx, secret); err != nil {
+				r.log.Info(fmt.Sprintf("Failed with error %v for secret %s:", err, secret.ObjectMeta.Name))
 
-		err := r.Client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}, found)
-		r.log.Info(fmt.Sprintf("secret %s: Exists: %s Error: %v", secret.ObjectMeta.Name, found.Name, err))
-
-		if err != nil && k8serrors.IsNotFound(err) {
-			r.log.Info("Creating a new Secret", "Secret.Namespace", secret.Namespace, "Service.Name", secret.Name)
-
-			err = r.Client.Create(ctx, secret)
-			if err != nil {
 				return err
 			}
 
-			// Resource created successfully - don't requeue
 			continue
-		} else if err != nil {
-			r.log.Info(fmt.Sprintf("Failed with error %v for secret %s:", err, secret.ObjectMeta.Name))
-
-			return err
 		}
 
-		// Resource already exists - don't requeue
-		if update {
-			r.log.Info("Updating secret...", "Secret.Namespace", found.Namespace, "Secret.Name", found.Name)
-
-			err = r.Client.Update(ctx, secret)
-			if err != nil {
-				return err
-			}
-		} else {
-			r.log.Info("Skip reconciliation: Secret already exists.", "Secret.Namespace", found.Namespace, "Secret.Name", found.Name)
+		if _, err := r.applyObject(ctx, secret); err != nil {
+			return err
 		}
 	}
 
@@ -241,72 +226,19 @@ func (r *ControlPlaneReconciler) createOrUpdateSecrets(ctx context.Context, ms *
 }
 
 func (r *ControlPlaneReconciler) createService(ctx context.Context, ms *microservice) error {
-	svcs := newServices(r.cp.ObjectMeta.Namespace, r.cp.Name, ms)
-	for _, svc := range svcs {
-		// Set ControlPlane instance as the owner and controller
-		if err := controllerutil.SetControllerReference(&r.cp, svc, r.Scheme); err != nil {
-			return err
-		}
-
-		// Check if this resource already exists
-		found := &corev1.Service{}
-
-		err := r.Client.Get(ctx, types.NamespacedName{Name: svc.Name, Namespace: svc.Namespace}, found)
-		if err != nil && k8serrors.IsNotFound(err) {
-			r.log.Info("Creating a new Service", "Service.Namespace", svc.Namespace, "Service.Name", svc.Name)
-
-			err = r.Client.Create(ctx, svc)
-			if err != nil {
-				return err
-			}
-
-			// Resource created successfully - don't requeue
-			continue
-		} else if err != nil {
+	for _, svc := range newServices(r.cp.ObjectMeta.Namespace, r.cp.Name, ms) {
+		if _, err := r.applyObject(ctx, svc); err != nil {
 			return err
 		}
-
-		// Resource already exists - don't requeue
-		r.log.Info("Skip reconcile: Service already exists", "Service.Namespace", found.Namespace, "Service.Name", found.Name)
 	}
 
 	return nil
 }
 
 func (r *ControlPlaneReconciler) createIngress(ctx context.Context, cfg *controllerIngressConfig) error {
-	ingress := newControllerIngress(r.cp.ObjectMeta.Namespace, r.cp.Name, cfg)
-
-	// Set ControlPlane instance as the owner and controller
-	if err := controllerutil.SetControllerReference(&r.cp, ingress, r.Scheme); err != nil {
-		return err
-	}
-
-	// Check if this resource already exists
-	found := &networkingv1.Ingress{}
-
-	err := r.Client.Get(ctx, types.NamespacedName{Name: ingress.Name, Namespace: ingress.Namespace}, found)
-	if err != nil && k8serrors.IsNotFound(err) {
-		r.log.Info("Creating a new Ingress", "Ingress.Namespace", ingress.Namespace, "Ingress.Name", ingress.Name)
-
-		err = r.Client.Create(ctx, ingress)
-		if err != nil {
-			return err
-		}
-
-		// Resource created successfully - don't requeue
-		return nil
-	} else if err != nil {
-		return err
-	}
-
-	// Resource already exists - don't requeue
-	r.log.Info(" Ingress already exists, updating existing Ingress:", "Ingress.Namespace", found.Namespace, "Ingress.Name", found.Name)
-
-	if err := r.Client.Update(ctx, ingress); err != nil {
-		return err
-	}
+	_, err := r.applyObject(ctx, newControllerIngress(r.cp.ObjectMeta.Namespace, r.cp.Name, cfg))
 
-	return nil
+	return err
 }
 
 func (r *ControlPlaneReconciler) createServiceAccount(ctx context.Context, ms *microservice) error {
@@ -334,103 +266,28 @@ func (r *ControlPlaneReconciler) createServiceAccount(ctx context.Context, ms *m
 		}
 	}
 
-	// Set ControlPlane instance as the owner and controller
-	if err := controllerutil.SetControllerReference(&r.cp, svcAcc, r.Scheme); err != nil {
-		return err
-	}
-
-	// Check if this resource already exists
-	found := &corev1.ServiceAccount{}
-
-	err := r.Client.Get(ctx, types.NamespacedName{Name: svcAcc.Name, Namespace: svcAcc.Namespace}, found)
-	if err != nil && k8serrors.IsNotFound(err) {
-		r.log.Info("Creating a new Service Account", "ServiceAccount.Namespace", svcAcc.Namespace, "ServiceAccount.Name", svcAcc.Name)
-		// TODO: Find out why the IsAlreadyExists() check is necessary here. Happens when CP redeployed
-		if err = r.Client.Create(ctx, svcAcc); err != nil && !k8serrors.IsAlreadyExists(err) {
-			return err
-		}
-
-		// Resource created successfully - don't requeue
-		return nil
-	} else if err != nil {
-		return err
-	}
-
-	// Resource already exists - don't requeue
-	r.log.Info("Skip reconcile: Service Account already exists", "ServiceAccount.Namespace", found.Namespace, "ServiceAccount.Name", found.Name)
+	_, err := r.applyObject(ctx, svcAcc)
 
-	return nil
+	return err
 }
 
-func (r *ControlPlaneReconciler) createRole(ctx context.Context, ms *microservice) error { //nolint:dupl
-	role := newRole(r.cp.ObjectMeta.Namespace, r.cp.Name, ms)
-
-	// Set ControlPlane instance as the owner and controller
-	if err := controllerutil.SetControllerReference(&r.cp, role, r.Scheme); err != nil {
-		return err
-	}
-
-	// Check if this resource already exists
-	found := &rbacv1.Role{}
-
-	err := r.Client.Get(ctx, types.NamespacedName{Name: role.Name, Namespace: role.Namespace}, found)
-	if err != nil && k8serrors.IsNotFound(err) {
-		r.log.Info("Creating a new Role ", "Role.Namespace", role.Namespace, "Role.Name", role.Name)
-
-		err = r.Client.Create(ctx, role)
-		if err != nil {
-			return err
-		}
+func (r *ControlPlaneReconciler) createRole(ctx context.Context, ms *microservice) error {
+	_, err := r.applyObject(ctx, newRole(r.cp.ObjectMeta.Namespace, r.cp.Name, ms))
 
-		// Resource created successfully - don't requeue
-		return nil
-	} else if err != nil {
-		return err
-	}
-
-	// Resource already exists - don't requeue
-	r.log.Info("Skip reconcile: Role already exists", "Role.Namespace", found.Namespace, "Role.Name", found.Name)
-
-	return nil
+	return err
 }
 
-func (r *ControlPlaneReconciler) createRoleBinding(ctx context.Context, ms *microservice) error { //nolint:dupl
-	crb := newRoleBinding(r.cp.ObjectMeta.Namespace, r.cp.Name, ms)
+func (r *ControlPlaneReconciler) createRoleBinding(ctx context.Context, ms *microservice) error {
+	_, err := r.applyObject(ctx, newRoleBinding(r.cp.ObjectMeta.Namespace, r.cp.Name, ms))
 
-	// Set ControlPlane instance as the owner and controller
-	if err := controllerutil.SetControllerReference(&r.cp, crb, r.Scheme); err != nil {
-		return err
-	}
-
-	// Check if this resource already exists
-	found := &rbacv1.RoleBinding{}
-
-	err := r.Client.Get(ctx, types.NamespacedName{Name: crb.Name, Namespace: crb.Namespace}, found)
-	if err != nil && k8serrors.IsNotFound(err) {
-		r.log.Info("Creating a new Role Binding", "RoleBinding.Namespace", crb.Namespace, "RoleBinding.Name", crb.Name)
-
-		err = r.Client.Create(ctx, crb)
-		if err != nil {
-			return err
-		}
-
-		// Resource created successfully - don't requeue
-		return nil
-	} else if err != nil {
-		return err
-	}
-
-	// Resource already exists - don't requeue
-	r.log.Info("Skip reconcile: Role Binding already exists", "RoleBinding.Namespace", found.Namespace, "RoleBinding.Name", found.Name)
-
-	return nil
+	return err
 }
 
 func (r *ControlPlaneReconciler) loginIofogClient(iofogClient *iofogclient.Client) error {
-	authURL := r.cp.Spec.Auth.URL
-	realm := r.cp.Spec.Auth.Realm
-	clientID := r.cp.Spec.Auth.ControllerClient
-	clientSecret := r.cp.Spec.Auth.ControllerSecret
+	authURL := r.credentials.auth.URL
+	realm := r.credentials.auth.Realm
+	clientID := r.credentials.auth.ControllerClient
+	clientSecret := r.credentials.auth.ControllerSecret
 
 	type LoginResponse struct {
 		AccessToken string `json:"access_token"`
@@ -493,30 +350,35 @@ func (r *ControlPlaneReconciler) createDefaultRouter(iofogClient *iofogclient.Cl
 		},
 	}
 
-	return iofogClient.PutDefaultRouter(routerConfig)
+	start := time.Now()
+	err = iofogClient.PutDefaultRouter(routerConfig)
+	observeControllerAPI("PutDefaultRouter", start, err)
+
+	return err
 }
 
 // createDefaultNatsHub registers the default NATS hub with the Controller (only when NATS is enabled).
-func (r *ControlPlaneReconciler) createDefaultNatsHub(iofogClient *iofogclient.Client, ing cpv3.NatsIngress) error {
+// Ingress ports that are not set are registered with the ports NATS listens on.
+func (r *ControlPlaneReconciler) createDefaultNatsHub(iofogClient *iofogclient.Client, ing cpv3.NatsIngress, ports nats.Ports) error {
 	serverPort := ing.ServerPort
 	if serverPort == 0 {
-		serverPort = 4222
+		serverPort = ports.ServerPort
 	}
 	clusterPort := ing.ClusterPort
 	if clusterPort == 0 {
-		clusterPort = 6222
+		clusterPort = ports.ClusterPort
 	}
 	leafPort := ing.LeafPort
 	if leafPort == 0 {
-		leafPort = 7422
+		leafPort = ports.LeafPort
 	}
 	mqttPort := ing.MqttPort
 	if mqttPort == 0 {
-		mqttPort = 8883
+		mqttPort = ports.MqttPort
 	}
 	httpPort := ing.HttpPort
 	if httpPort == 0 {
-		httpPort = 8222
+		httpPort = ports.HttpPort
 	}
 	req := &iofogclient.NatsHubRequest{
 		Host:        &ing.Address,
@@ -526,7 +388,10 @@ func (r *ControlPlaneReconciler) createDefaultNatsHub(iofogClient *iofogclient.C
 		MqttPort:    &mqttPort,
 		HttpPort:    &httpPort,
 	}
+	start := time.Now()
 	_, err := iofogClient.UpsertNatsHub(req)
+	observeControllerAPI("UpsertNatsHub", start, err)
+
 	return err
 }
 
@@ -564,64 +429,36 @@ func shouldUpdate(entry ConfigEntry) bool {
 				name == "iofog-router-inter-router"
 		}
 		return false
+	case "connector":
+		// Inter-router links are derived from the HA setting
+		role, _ := data["role"].(string)
+		return role == "inter-router"
 	default:
 		return false
 	}
 }
 
-// getConfigVersion extracts the pot-config version from the router metadata
-func getConfigVersion(config string) (string, error) {
-	var entries []ConfigEntry
-	if err := json.Unmarshal([]byte(config), &entries); err != nil {
-		return "", fmt.Errorf("failed to parse config: %w", err)
+// getConfigEntryKey identifies an entry by its type and name, unnamed entries only by their type
+func getConfigEntryKey(entry ConfigEntry) string {
+	if len(entry) != 2 {
+		return ""
 	}
 
-	for _, entry := range entries {
-		if len(entry) != 2 {
-			continue
-		}
-
-		entryType, ok := entry[0].(string)
-		if !ok || entryType != "router" {
-			continue
-		}
-
-		data, ok := entry[1].(map[string]interface{})
-		if !ok {
-			continue
-		}
+	entryType, _ := entry[0].(string)
 
-		if metadata, ok := data["metadata"].(string); ok {
-			var metadataMap map[string]interface{}
-			if err := json.Unmarshal([]byte(metadata), &metadataMap); err != nil {
-				return "", fmt.Errorf("failed to parse metadata: %w", err)
-			}
-			if version, ok := metadataMap["pot-config"].(string); ok {
-				return version, nil
-			}
+	if data, ok := entry[1].(map[string]interface{}); ok {
+		if name, ok := data["name"].(string); ok {
+			return entryType + "/" + name
 		}
 	}
-	return "", nil
+
+	return entryType
 }
 
-// mergeConfigs merges existing and new router configurations
+// mergeConfigs merges existing and new router configurations.
+// Entries managed by the operator (see shouldUpdate) always come from the new configuration,
+// everything else that was added or changed in the existing configuration is kept.
 func mergeConfigs(existingConfig, newConfig string) (string, error) {
-	// Check versions
-	existingVersion, err := getConfigVersion(existingConfig)
-	if err != nil {
-		return "", fmt.Errorf("failed to get existing config version: %w", err)
-	}
-
-	newVersion, err := getConfigVersion(newConfig)
-	if err != nil {
-		return "", fmt.Errorf("failed to get new config version: %w", err)
-	}
-
-	// If versions are the same, keep existing config
-	if existingVersion != "" && existingVersion == newVersion {
-		return existingConfig, nil
-	}
-
 	var existing, new []ConfigEntry
 
 	// Parse existing config
@@ -637,24 +474,28 @@ func mergeConfigs(existingConfig, newConfig string) (string, error) {
 	// Create map of existing entries for quick lookup
 	existingMap := make(map[string]ConfigEntry)
 	for _, entry := range existing {
-		existingMap[entry[0].(string)] = entry
+		existingMap[getConfigEntryKey(entry)] = entry
 	}
 
 	// Process new config
-	result := make([]ConfigEntry, 0)
+	result := make([]ConfigEntry, 0, len(new))
+	newKeys := make(map[string]bool)
+
 	for _, entry := range new {
-		if shouldUpdate(entry) {
-			// Update specified sections
-			result = append(result, entry)
-		} else if existing, exists := existingMap[entry[0].(string)]; exists {
+		key := getConfigEntryKey(entry)
+		newKeys[key] = true
+
+		if existing, exists := existingMap[key]; exists && !shouldUpdate(entry) {
 			// Keep existing version
 			result = append(result, existing)
+		} else {
+			result = append(result, entry)
 		}
 	}
 
-	// Add any remaining existing entries that weren't in new config
+	// Add any remaining existing entries that weren't in new config, stale managed entries are dropped
 	for _, entry := range existing {
-		if !shouldUpdate(entry) {
+		if !newKeys[getConfigEntryKey(entry)] && !shouldUpdate(entry) {
 			result = append(result, entry)
 		}
 	}
@@ -668,54 +509,58 @@ func mergeConfigs(existingConfig, newConfig string) (string, error) {
 	return string(mergedConfig), nil
 }
 
-func (r *ControlPlaneReconciler) createConfigMap(ctx context.Context) error {
-	configMap := newRouterConfigMap(r.cp.ObjectMeta.Namespace, r.cp.Name)
-
-	// Set owner reference
-	if err := controllerutil.SetControllerReference(&r.cp, configMap, r.Scheme); err != nil {
-		return err
-	}
+// createConfigMap creates or merges the router ConfigMap and returns the skrouterd.json the router will read
+func (r *ControlPlaneReconciler) createConfigMap(ctx context.Context, ms *microservice, cfg router.Config) (string, error) {
+	configMap := newRouterConfigMap(r.cp.ObjectMeta.Namespace, r.cp.Name, ms, cfg)
 
 	// Try to get existing ConfigMap
 	existingConfigMap := &corev1.ConfigMap{}
 	err := r.Client.Get(ctx, types.NamespacedName{Name: configMap.Name, Namespace: configMap.Namespace}, existingConfigMap)
 
-	if err != nil {
-		if k8serrors.IsNotFound(err) {
-			// ConfigMap doesn't exist, create it
-			return r.Client.Create(ctx, configMap)
-		}
-		return err
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return "", err
 	}
 
 	// ConfigMap exists, merge configurations
-	mergedConfig, err := mergeConfigs(existingConfigMap.Data["skrouterd.json"], configMap.Data["skrouterd.json"])
-	if err != nil {
-		return fmt.Errorf("failed to merge configs: %w", err)
+	if err == nil {
+		mergedConfig, err := mergeConfigs(existingConfigMap.Data["skrouterd.json"], configMap.Data["skrouterd.json"])
+		if err != nil {
+			return "", fmt.Errorf("failed to merge configs: %w", err)
+		}
+
+		configMap.Data["skrouterd.json"] = mergedConfig
 	}
 
-	// Update ConfigMap with merged configuration and standard labels
-	existingConfigMap.Data["skrouterd.json"] = mergedConfig
-	existingConfigMap.Labels = mergeLabels(configMap.Labels, existingConfigMap.Labels)
-	return r.Client.Update(ctx, existingConfigMap)
+	if _, err := r.applyObject(ctx, configMap); err != nil {
+		return "", err
+	}
+
+	return configMap.Data["skrouterd.json"], nil
 }
 
-func (r *ControlPlaneReconciler) ImportRouterCACertificate(iofogClient *iofogclient.Client, secretName string) (err error) {
+func (r *ControlPlaneReconciler) ImportRouterCACertificate(iofogClient *iofogclient.Client, name, secretName string) (err error) {
 
 	// Create CA certificate
 	request := iofogclient.CACreateRequest{
-		Name:       secretName,
+		Name:       name,
 		Type:       "k8s-secret",
 		SecretName: secretName,
 	}
 
-	_, err = iofogClient.GetCA(secretName)
+	start := time.Now()
+	_, err = iofogClient.GetCA(name)
+	observeControllerAPI("GetCA", start, err)
+
 	if err != nil {
 		if !strings.Contains(err.Error(), "NotFoundError") {
 			return err
 		}
 
-		return iofogClient.CreateCA(&request)
+		start = time.Now()
+		err = iofogClient.CreateCA(&request)
+		observeControllerAPI("CreateCA", start, err)
+
+		return err
 	}
 
 	return err
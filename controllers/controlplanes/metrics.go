@@ -0,0 +1,144 @@
+package controllers
+
+import (
+	"time"
+
+	op "github.com/datasance/iofog-go-sdk/v3/pkg/k8s/operator"
+	cpv3 "github.com/datasance/iofog-operator/v3/apis/controlplanes/v3"
+	"github.com/prometheus/client_golang/prometheus"
+	cond "k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const metricsNamespace = "iofog_controlplane"
+
+// Conditions a ControlPlane can be in, the condition metric has a series for each of them
+var controlPlaneConditions = []string{"deploying", "updating", "ready", cpv3.ConditionFailed, cpv3.ConditionDegraded}
+
+var (
+	reconcileDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "reconcile_duration_seconds",
+		Help:      "Duration of the ControlPlane component reconciles by component and result.",
+		Buckets:   []float64{0.1, 0.5, 1, 5, 10, 30, 60, 120, 300},
+	}, []string{"component", "result"})
+
+	conditionInfo = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "condition",
+		Help:      "Current condition of a ControlPlane, 1 for the condition it is in and for Failed and Degraded while they are true.",
+	}, []string{"namespace", "name", "condition"})
+
+	loadBalancerWaitDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "load_balancer_wait_duration_seconds",
+		Help:      "Time spent waiting for the address of a LoadBalancer Service.",
+		Buckets:   []float64{0.1, 1, 5, 10, 30, 60, 120, 240},
+	}, []string{"service"})
+
+	controllerAPIDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "controller_api_duration_seconds",
+		Help:      "Latency of the Controller API calls by operation.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"operation"})
+
+	controllerAPIErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "controller_api_errors_total",
+		Help:      "Failed Controller API calls by operation.",
+	}, []string{"operation"})
+
+	natsBootstrapFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "nats_bootstrap_failures_total",
+		Help:      "Failures to bootstrap NATS from the Controller by ControlPlane.",
+	}, []string{"namespace", "name"})
+
+	degradedInfo = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "degraded",
+		Help:      "Whether a ready ControlPlane fails its health checks, 1 while it is degraded.",
+	}, []string{"namespace", "name"})
+
+	certificateExpiryDays = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "certificate_expiry_days",
+		Help:      "Days until the server certificate of a Secret expires.",
+	}, []string{"namespace", "name", "secret"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(
+		reconcileDuration,
+		conditionInfo,
+		loadBalancerWaitDuration,
+		controllerAPIDuration,
+		controllerAPIErrors,
+		natsBootstrapFailures,
+		degradedInfo,
+		certificateExpiryDays,
+	)
+}
+
+// observeReconcile records the duration and result of a component reconcile
+func observeReconcile(component string, start time.Time, recon op.Reconciliation) {
+	result := "success"
+
+	switch {
+	case recon.Err != nil:
+		result = "error"
+	case recon.Requeue:
+		result = "requeue"
+	}
+
+	reconcileDuration.WithLabelValues(component, result).Observe(time.Since(start).Seconds())
+}
+
+// observeControllerAPI records the latency of a Controller API call started at start and counts its failures
+func observeControllerAPI(operation string, start time.Time, err error) {
+	controllerAPIDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+
+	if err != nil {
+		controllerAPIErrors.WithLabelValues(operation).Inc()
+	}
+}
+
+// setConditionMetric exports the current condition of a ControlPlane
+func setConditionMetric(cp *cpv3.ControlPlane) {
+	current := cp.GetCondition()
+
+	for _, condition := range controlPlaneConditions {
+		value := 0.0
+		if condition == current || cond.IsStatusConditionTrue(cp.Status.Conditions, condition) {
+			value = 1
+		}
+
+		conditionInfo.WithLabelValues(cp.Namespace, cp.Name, condition).Set(value)
+	}
+}
+
+// setDegradedMetric exports whether a ControlPlane is degraded
+func setDegradedMetric(cp *cpv3.ControlPlane, degraded bool) {
+	value := 0.0
+	if degraded {
+		value = 1
+	}
+
+	degradedInfo.WithLabelValues(cp.Namespace, cp.Name).Set(value)
+}
+
+// setCertificateExpiryMetric exports the days until the certificate of a Secret expires
+func setCertificateExpiryMetric(cp *cpv3.ControlPlane, secret string, notAfter time.Time) {
+	certificateExpiryDays.WithLabelValues(cp.Namespace, cp.Name, secret).Set(time.Until(notAfter).Hours() / 24) //nolint:gomnd
+}
+
+// deleteControlPlaneMetrics removes the series of a deleted ControlPlane
+func deleteControlPlaneMetrics(namespace, name string) {
+	labels := prometheus.Labels{"namespace": namespace, "name": name}
+
+	conditionInfo.DeletePartialMatch(labels)
+	natsBootstrapFailures.DeletePartialMatch(labels)
+	degradedInfo.DeletePartialMatch(labels)
+	certificateExpiryDays.DeletePartialMatch(labels)
+}
@@ -20,7 +20,6 @@ import (
 
 	cpv3 "github.com/datasance/iofog-operator/v3/apis/controlplanes/v3"
 	"github.com/datasance/iofog-operator/v3/controllers/controlplanes/nats"
-	"github.com/datasance/iofog-operator/v3/controllers/controlplanes/router"
 	"github.com/datasance/iofog-operator/v3/internal/util"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
@@ -32,6 +31,9 @@ import (
 
 const (
 	routerName                                     = "router"
+	routerSecondaryName                            = "router-2"
+	routerInteriorServiceName                      = "router-interior"
+	routerPodLabel                                 = "datasance.com/router"
 	controllerName                                 = "controller"
 	controllerCredentialsSecretName                = "controller-credentials"
 	emailSecretKey                                 = "email"
@@ -64,6 +66,8 @@ type service struct {
 	ports              []corev1.ServicePort
 	// headless when true sets ClusterIP: None (for StatefulSet headless service)
 	headless bool
+	// selector narrows the microservice labels down to a subset of its pods
+	selector map[string]string
 }
 
 type microservice struct {
@@ -85,8 +89,13 @@ type microservice struct {
 	isStatefulSet          bool
 	statefulSetServiceName string // headless service name for StatefulSet (e.g. nats-headless)
 	volumeClaimTemplates   []corev1.PersistentVolumeClaim
-	// podTemplateAnnotations applied to StatefulSet pod template (e.g. kubectl.kubernetes.io/restartedAt for rollout)
+	// podTemplateAnnotations applied to the pod template (e.g. kubectl.kubernetes.io/restartedAt for rollout)
 	podTemplateAnnotations map[string]string
+	// selectorLabels tell the pods of a workload apart from the pods of other workloads with the same labels,
+	// they are set on the pod template and the selector but not on Services
+	selectorLabels map[string]string
+	// scheduling is copied to the pod template (node selector, tolerations, affinity, spread, priority)
+	scheduling cpv3.PodScheduling
 }
 
 type container struct {
@@ -128,6 +137,9 @@ type controllerMicroserviceConfig struct {
 	ecnViewerURL          string
 	logLevel              string
 	vault                 *cpv3.Vault
+	credentialsHash       string
+	resources             corev1.ResourceRequirements
+	scheduling            cpv3.PodScheduling
 }
 
 func buildControllerSecrets(namespace string, cfg *controllerMicroserviceConfig) []corev1.Secret {
@@ -211,7 +223,7 @@ func buildVaultCredentialsSecret(namespace string, v *cpv3.Vault) *corev1.Secret
 
 func filterControllerConfig(cfg *controllerMicroserviceConfig) {
 	if cfg.replicas == 0 {
-		cfg.replicas = 1
+		cfg.replicas = cpv3.DefaultControllerReplicas
 	}
 
 	if cfg.image == "" {
@@ -227,15 +239,15 @@ func filterControllerConfig(cfg *controllerMicroserviceConfig) {
 	}
 
 	if cfg.serviceType == "" {
-		cfg.serviceType = string(corev1.ServiceTypeLoadBalancer)
+		cfg.serviceType = cpv3.DefaultServiceType
 	}
 
 	if cfg.ecnViewerPort == 0 {
-		cfg.ecnViewerPort = 8008
+		cfg.ecnViewerPort = cpv3.DefaultEcnViewerPort
 	}
 
 	if cfg.pidBaseDir == "" {
-		cfg.pidBaseDir = "/home/runner"
+		cfg.pidBaseDir = cpv3.DefaultPidBaseDir
 	}
 
 	if cfg.https == nil || *cfg.https == false {
@@ -245,7 +257,7 @@ func filterControllerConfig(cfg *controllerMicroserviceConfig) {
 	}
 
 	if cfg.logLevel == "" {
-		cfg.logLevel = "info"
+		cfg.logLevel = cpv3.DefaultLogLevel
 	}
 
 }
@@ -321,7 +333,11 @@ func newControllerMicroservice(namespace string, cfg *controllerMicroserviceConf
 			},
 		},
 		secrets: buildControllerSecrets(namespace, cfg),
-		volumes: []corev1.Volume{},
+		podTemplateAnnotations: map[string]string{
+			credentialsHashAnnotation: cfg.credentialsHash,
+		},
+		scheduling: cfg.scheduling,
+		volumes:    []corev1.Volume{},
 		securityContext: &corev1.PodSecurityContext{
 			RunAsUser:  ptr.To[int64](10000), // UID
 			RunAsGroup: ptr.To[int64](10000), // GID
@@ -558,16 +574,7 @@ func newControllerMicroservice(namespace string, cfg *controllerMicroserviceConf
 						Value: cfg.logLevel,
 					},
 				},
-				// resources: corev1.ResourceRequirements{
-				// 	Limits: corev1.ResourceList{
-				// 		"cpu":    resource.MustParse("1800m"),
-				// 		"memory": resource.MustParse("3Gi"),
-				// 	},
-				// 	Requests: corev1.ResourceList{
-				// 		"cpu":    resource.MustParse("400m"),
-				// 		"memory": resource.MustParse("1Gi"),
-				// 	},
-				// },
+				resources: cfg.resources,
 			},
 		},
 	}
@@ -729,6 +736,9 @@ type routerMicroserviceConfig struct {
 	siteSecret            string
 	localSecret           string
 	ha                    bool
+	ports                 cpv3.RouterPorts
+	resources             corev1.ResourceRequirements
+	scheduling            cpv3.PodScheduling
 }
 
 func filterRouterConfig(cfg routerMicroserviceConfig) routerMicroserviceConfig {
@@ -737,7 +747,7 @@ func filterRouterConfig(cfg routerMicroserviceConfig) routerMicroserviceConfig {
 	}
 
 	if cfg.serviceType == "" {
-		cfg.serviceType = string(corev1.ServiceTypeLoadBalancer)
+		cfg.serviceType = cpv3.DefaultServiceType
 	}
 
 	if cfg.siteSecret == "" {
@@ -756,25 +766,43 @@ func filterRouterConfig(cfg routerMicroserviceConfig) routerMicroserviceConfig {
 		cfg.localCA = "default-router-local-ca"
 	}
 
+	if cfg.ports.MessagePort == 0 {
+		cfg.ports.MessagePort = cpv3.DefaultRouterMessagePort
+	}
+
+	if cfg.ports.InteriorPort == 0 {
+		cfg.ports.InteriorPort = cpv3.DefaultRouterInteriorPort
+	}
+
+	if cfg.ports.EdgePort == 0 {
+		cfg.ports.EdgePort = cpv3.DefaultRouterEdgePort
+	}
+
+	if cfg.ports.HTTPPort == 0 {
+		cfg.ports.HTTPPort = cpv3.DefaultRouterHTTPPort
+	}
+
 	return cfg
 }
 
 func newRouterMicroservice(cfg routerMicroserviceConfig) *microservice {
+	return newRouterMicroserviceWithName(cfg, routerName)
+}
+
+// newRouterMicroserviceWithName creates a router microservice with a custom name.
+// Only the primary router exposes Services, the others link to it through routerInteriorServiceName.
+func newRouterMicroserviceWithName(cfg routerMicroserviceConfig, name string) *microservice {
 	cfg = filterRouterConfig(cfg)
 
-	return &microservice{
-		name: routerName,
-		labels: map[string]string{
-			"datasance.com/component": routerName,
-			"application":             "interior-router",
-			"skupper.io/component":    "router",
-			"skupper.io/type":         "site",
-		},
-		annotations: map[string]string{
-			"prometheus.io/port":   "9090",
-			"prometheus.io/scrape": "true",
-		},
-		services: []service{
+	labels := map[string]string{
+		"datasance.com/component": routerName,
+		"application":             "interior-router",
+		"skupper.io/component":    "router",
+		"skupper.io/type":         "site",
+	}
+	var services []service
+	if name == routerName {
+		services = []service{
 			{
 				name:               "router",
 				serviceType:        cfg.serviceType,
@@ -783,40 +811,53 @@ func newRouterMicroservice(cfg routerMicroserviceConfig) *microservice {
 				ports: []corev1.ServicePort{
 					{
 						Name:       "router-message",
-						Port:       router.MessagePort,
-						TargetPort: intstr.FromInt(router.MessagePort),
+						Port:       int32(cfg.ports.MessagePort),
+						TargetPort: intstr.FromInt(cfg.ports.MessagePort),
 						Protocol:   corev1.Protocol("TCP"),
 					},
 					{
 						Name:       "router-interior",
-						Port:       router.InteriorPort,
-						TargetPort: intstr.FromInt(router.InteriorPort),
+						Port:       int32(cfg.ports.InteriorPort),
+						TargetPort: intstr.FromInt(cfg.ports.InteriorPort),
 						Protocol:   corev1.Protocol("TCP"),
 					},
 					{
 						Name:       "router-edge",
-						Port:       router.EdgePort,
-						TargetPort: intstr.FromInt(router.EdgePort),
+						Port:       int32(cfg.ports.EdgePort),
+						TargetPort: intstr.FromInt(cfg.ports.EdgePort),
 						Protocol:   corev1.Protocol("TCP"),
 					},
 				},
 			},
-			// {
-			// 	name:          "router-internal",
-			// 	serviceType:   "ClusterIP",
-			// 	trafficPolicy: getTrafficPolicy("ClusterIP"),
-			// 	ports: []corev1.ServicePort{
-			// 		{
-			// 			Name:       "router-internal",
-			// 			Port:       5672,
-			// 			TargetPort: intstr.FromInt(5672),
-			// 			Protocol:   corev1.Protocol("TCP"),
-			// 		},
-			// 	},
-			// },
+			{
+				name:        routerInteriorServiceName,
+				serviceType: string(corev1.ServiceTypeClusterIP),
+				selector:    map[string]string{routerPodLabel: routerName},
+				ports: []corev1.ServicePort{
+					{
+						Name:       "router-interior",
+						Port:       int32(cfg.ports.InteriorPort),
+						TargetPort: intstr.FromInt(cfg.ports.InteriorPort),
+						Protocol:   corev1.Protocol("TCP"),
+					},
+				},
+			},
+		}
+	}
+
+	return &microservice{
+		name:   name,
+		labels: labels,
+		// The router Service selects the pods of every router, each Deployment only its own
+		selectorLabels: map[string]string{routerPodLabel: name},
+		annotations: map[string]string{
+			"prometheus.io/port":   strconv.Itoa(cfg.ports.HTTPPort),
+			"prometheus.io/scrape": "true",
 		},
+		services:        services,
 		imagePullSecret: cfg.imagePullSecret,
 		replicas:        1,
+		scheduling:      cfg.scheduling,
 		rbacRules: []rbacv1.PolicyRule{
 			{
 				Verbs:     []string{"get", "list", "watch"},
@@ -854,7 +895,7 @@ func newRouterMicroservice(cfg routerMicroserviceConfig) *microservice {
 				Name: "iofog-router-config",
 				VolumeSource: corev1.VolumeSource{
 					ConfigMap: &corev1.ConfigMapVolumeSource{
-						LocalObjectReference: corev1.LocalObjectReference{Name: "iofog-router"},
+						LocalObjectReference: corev1.LocalObjectReference{Name: getRouterConfigMapName(name)},
 						Items: []corev1.KeyToPath{
 							{Key: "skrouterd.json", Path: "skrouterd.json"},
 						},
@@ -883,35 +924,36 @@ func newRouterMicroservice(cfg routerMicroserviceConfig) *microservice {
 				name:            routerName,
 				image:           cfg.image,
 				imagePullPolicy: "Always",
+				resources:       cfg.resources,
 				command: []string{
 					"/home/skrouterd/bin/router",
 				},
 				ports: []corev1.ContainerPort{
 					{
 						Name:          "amqps",
-						ContainerPort: 5671,
+						ContainerPort: int32(cfg.ports.MessagePort),
 						Protocol:      corev1.ProtocolTCP,
 					},
 					{
 						Name:          "http",
-						ContainerPort: 9090,
+						ContainerPort: int32(cfg.ports.HTTPPort),
 						Protocol:      corev1.ProtocolTCP,
 					},
 					{
 						Name:          "inter-router",
-						ContainerPort: 55671,
+						ContainerPort: int32(cfg.ports.InteriorPort),
 						Protocol:      corev1.ProtocolTCP,
 					},
 					{
 						Name:          "edge",
-						ContainerPort: 45671,
+						ContainerPort: int32(cfg.ports.EdgePort),
 						Protocol:      corev1.ProtocolTCP,
 					},
 				},
 				readinessProbe: &corev1.Probe{
 					ProbeHandler: corev1.ProbeHandler{
 						HTTPGet: &corev1.HTTPGetAction{
-							Port:   intstr.FromInt(9090),
+							Port:   intstr.FromInt(cfg.ports.HTTPPort),
 							Path:   "/healthz",
 							Scheme: corev1.URISchemeHTTP,
 						},
@@ -925,7 +967,7 @@ func newRouterMicroservice(cfg routerMicroserviceConfig) *microservice {
 				livenessProbe: &corev1.Probe{
 					ProbeHandler: corev1.ProbeHandler{
 						HTTPGet: &corev1.HTTPGetAction{
-							Port:   intstr.FromInt(9090),
+							Port:   intstr.FromInt(cfg.ports.HTTPPort),
 							Path:   "/healthz",
 							Scheme: corev1.URISchemeHTTP,
 						},
@@ -959,7 +1001,7 @@ func newRouterMicroservice(cfg routerMicroserviceConfig) *microservice {
 					},
 					{
 						Name:  "SKUPPER_SITE_ID",
-						Value: "default-router",
+						Value: getRouterID(name),
 					},
 					{
 						Name:  "SKUPPER_PLATFORM",
@@ -1001,6 +1043,25 @@ func newRouterMicroservice(cfg routerMicroserviceConfig) *microservice {
 	}
 }
 
+// getRouterID returns the router and site ID of a router microservice
+func getRouterID(name string) string {
+	return "default-" + name
+}
+
+// getRouterConfigMapName returns the ConfigMap holding skrouterd.json of a router microservice
+func getRouterConfigMapName(name string) string {
+	return "iofog-" + name
+}
+
+// getRouterNames returns the router microservices of a ControlPlane, the primary router comes first
+func getRouterNames(cp cpv3.ControlPlane) []string {
+	if cp.Spec.Router.HA != nil && *cp.Spec.Router.HA {
+		return []string{routerName, routerSecondaryName}
+	}
+
+	return []string{routerName}
+}
+
 // newRouterMicroservices creates router microservices based on HA configuration
 func newRouterMicroservices(cfg routerMicroserviceConfig) []*microservice {
 	cfg = filterRouterConfig(cfg)
@@ -1013,7 +1074,7 @@ func newRouterMicroservices(cfg routerMicroserviceConfig) []*microservice {
 
 	// Create secondary router if HA is enabled
 	if cfg.ha {
-		secondaryRouter := newRouterMicroserviceWithName(cfg, "router-2")
+		secondaryRouter := newRouterMicroserviceWithName(cfg, routerSecondaryName)
 		microservices = append(microservices, secondaryRouter)
 	}
 
@@ -1021,6 +1082,7 @@ func newRouterMicroservices(cfg routerMicroserviceConfig) []*microservice {
 }
 
 type natsMicroserviceConfig struct {
+	instanceName                string
 	image                       string
 	imagePullSecret             string
 	replicas                    int32
@@ -1033,29 +1095,28 @@ type natsMicroserviceConfig struct {
 	serverServiceType           string
 	serverServiceAnnotations    map[string]string
 	serverExternalTrafficPolicy string
+	ports                       nats.Ports
+	resources                   corev1.ResourceRequirements
+	scheduling                  cpv3.PodScheduling
 }
 
-func newNatsMicroservice(cfg natsMicroserviceConfig) *microservice {
-	// Headless: all ports (StatefulSet pod discovery: nats-0.nats-headless, etc.).
-	headlessPorts := []corev1.ServicePort{
-		{Name: "cluster", Port: int32(nats.DefaultClusterPort), TargetPort: intstr.FromInt(nats.DefaultClusterPort)},
-		{Name: "leaf", Port: int32(nats.DefaultLeafPort), TargetPort: intstr.FromInt(nats.DefaultLeafPort)},
-		{Name: "mqtt", Port: int32(nats.DefaultMqttPort), TargetPort: intstr.FromInt(nats.DefaultMqttPort)},
-		{Name: "client", Port: int32(nats.DefaultServerPort), TargetPort: intstr.FromInt(nats.DefaultServerPort)},
-		{Name: "monitor", Port: int32(nats.DefaultHttpPort), TargetPort: intstr.FromInt(nats.DefaultHttpPort)},
-	}
-	// Client-facing: cluster, leaf, mqtt (LB/ingress and spec.services.nats apply here).
-	clientPorts := []corev1.ServicePort{
-		{Name: "cluster", Port: int32(nats.DefaultClusterPort), TargetPort: intstr.FromInt(nats.DefaultClusterPort)},
-		{Name: "leaf", Port: int32(nats.DefaultLeafPort), TargetPort: intstr.FromInt(nats.DefaultLeafPort)},
-		{Name: "mqtt", Port: int32(nats.DefaultMqttPort), TargetPort: intstr.FromInt(nats.DefaultMqttPort)},
+// getNatsPodLabels returns the labels of the NATS pods of a ControlPlane, the StatefulSet also sets them on the JetStream claims
+func getNatsPodLabels(instanceName string) map[string]string {
+	return map[string]string{
+		"datasance.com/component":    "nats",
+		"app.kubernetes.io/instance": instanceName,
 	}
-	// nats-server: client and monitor only (ClusterIP).
-	serverPorts := []corev1.ServicePort{
-		{Name: "client", Port: int32(nats.DefaultServerPort), TargetPort: intstr.FromInt(nats.DefaultServerPort)},
-		{Name: "monitor", Port: int32(nats.DefaultHttpPort), TargetPort: intstr.FromInt(nats.DefaultHttpPort)},
+}
+
+func newNatsMicroservice(cfg natsMicroserviceConfig) *microservice {
+	ports := cfg.ports
+	if ports == (nats.Ports{}) {
+		ports = nats.DefaultPorts()
 	}
 
+	// The scheduling defaults select the pods by these labels, other ControlPlanes in the namespace run their own NATS
+	labels := getNatsPodLabels(cfg.instanceName)
+
 	storageQuantity := resource.MustParse(cfg.storageSize)
 	if storageQuantity.IsZero() {
 		storageQuantity = resource.MustParse(nats.DefaultStorageSizePVC)
@@ -1075,11 +1136,12 @@ func newNatsMicroservice(cfg natsMicroserviceConfig) *microservice {
 		volumeClaimTemplates:   []corev1.PersistentVolumeClaim{{ObjectMeta: metav1.ObjectMeta{Name: "js-data"}, Spec: pvcSpec}},
 		imagePullSecret:        cfg.imagePullSecret,
 		replicas:               cfg.replicas,
-		labels:                 map[string]string{"datasance.com/component": "nats"},
+		labels:                 labels,
+		scheduling:             defaultNatsScheduling(cfg.scheduling, labels),
 		services: []service{
-			{name: nats.HeadlessServiceName, serviceType: "ClusterIP", headless: true, ports: headlessPorts},
-			{name: nats.ClientServiceName, serviceType: cfg.serviceType, serviceAnnotations: cfg.serviceAnnotations, trafficPolicy: getTrafficPolicy(cfg.serviceType, cfg.externalTrafficPolicy), ports: clientPorts},
-			{name: nats.ServerServiceName, serviceType: cfg.serverServiceType, serviceAnnotations: cfg.serverServiceAnnotations, trafficPolicy: getTrafficPolicy(cfg.serverServiceType, cfg.serverExternalTrafficPolicy), headless: false, ports: serverPorts},
+			{name: nats.HeadlessServiceName, serviceType: "ClusterIP", headless: true, ports: nats.HeadlessServicePorts(ports)},
+			{name: nats.ClientServiceName, serviceType: cfg.serviceType, serviceAnnotations: cfg.serviceAnnotations, trafficPolicy: getTrafficPolicy(cfg.serviceType, cfg.externalTrafficPolicy), ports: nats.ClientServicePorts(ports)},
+			{name: nats.ServerServiceName, serviceType: cfg.serverServiceType, serviceAnnotations: cfg.serverServiceAnnotations, trafficPolicy: getTrafficPolicy(cfg.serverServiceType, cfg.serverExternalTrafficPolicy), headless: false, ports: nats.ServerServicePorts(ports)},
 		},
 		volumes: []corev1.Volume{
 			{Name: "config", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: nats.ConfigMapName}}}},
@@ -1099,12 +1161,13 @@ func newNatsMicroservice(cfg natsMicroserviceConfig) *microservice {
 				name:            "nats",
 				image:           cfg.image,
 				imagePullPolicy: "Always",
+				resources:       cfg.resources,
 				ports: []corev1.ContainerPort{
-					{Name: "client", ContainerPort: nats.DefaultServerPort},
-					{Name: "cluster", ContainerPort: nats.DefaultClusterPort},
-					{Name: "leaf", ContainerPort: nats.DefaultLeafPort},
-					{Name: "mqtt", ContainerPort: nats.DefaultMqttPort},
-					{Name: "monitor", ContainerPort: nats.DefaultHttpPort},
+					{Name: "client", ContainerPort: int32(ports.ServerPort)},
+					{Name: "cluster", ContainerPort: int32(ports.ClusterPort)},
+					{Name: "leaf", ContainerPort: int32(ports.LeafPort)},
+					{Name: "mqtt", ContainerPort: int32(ports.MqttPort)},
+					{Name: "monitor", ContainerPort: int32(ports.HttpPort)},
 				},
 				env: []corev1.EnvVar{
 					{Name: "NATS_CONF", Value: "/etc/nats/config/server.conf"},
@@ -1116,13 +1179,13 @@ func newNatsMicroservice(cfg natsMicroserviceConfig) *microservice {
 					{Name: "NATS_SSL_DIR", Value: "/etc/nats/certs"},
 					{Name: "NATS_CERT_NAME", Value: "nats-site-server"},
 					{Name: "NATS_MQTT_CERT_NAME", Value: "nats-mqtt-server"},
-					{Name: "NATS_SERVER_PORT", Value: "4222"},
-					{Name: "NATS_CLUSTER_PORT", Value: "6222"},
-					{Name: "NATS_LEAF_PORT", Value: "7422"},
-					{Name: "NATS_MQTT_PORT", Value: "8883"},
-					{Name: "NATS_MONITOR_PORT", Value: "8222"},
+					{Name: "NATS_SERVER_PORT", Value: strconv.Itoa(ports.ServerPort)},
+					{Name: "NATS_CLUSTER_PORT", Value: strconv.Itoa(ports.ClusterPort)},
+					{Name: "NATS_LEAF_PORT", Value: strconv.Itoa(ports.LeafPort)},
+					{Name: "NATS_MQTT_PORT", Value: strconv.Itoa(ports.MqttPort)},
+					{Name: "NATS_MONITOR_PORT", Value: strconv.Itoa(ports.HttpPort)},
 					{Name: "NATS_JETSTREAM_STORE_DIR", Value: "/home/runner/data"},
-					{Name: "NATS_HTTP_PORT", Value: "8222"},
+					{Name: "NATS_HTTP_PORT", Value: strconv.Itoa(ports.HttpPort)},
 					{Name: "JETSTREAM_KEY", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: cfg.jetStreamKeySecret}, Key: "jsk"}}},
 					{Name: "JETSTREAM_PREV_KEY", Value: ""},
 					{Name: "SELFNAME", ValueFrom: &corev1.EnvVarSource{FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.name"}}},
@@ -1137,7 +1200,7 @@ func newNatsMicroservice(cfg natsMicroserviceConfig) *microservice {
 					{Name: "js-data", MountPath: "/home/runner/data"},
 				},
 				readinessProbe: &corev1.Probe{
-					ProbeHandler:        corev1.ProbeHandler{HTTPGet: &corev1.HTTPGetAction{Path: "/healthz?js-enabled-only=true", Port: intstr.FromInt32(nats.DefaultHttpPort)}},
+					ProbeHandler:        corev1.ProbeHandler{HTTPGet: &corev1.HTTPGetAction{Path: "/healthz?js-enabled-only=true", Port: intstr.FromInt(ports.HttpPort)}},
 					InitialDelaySeconds: 10,
 					TimeoutSeconds:      5,
 					PeriodSeconds:       10,
@@ -1145,7 +1208,7 @@ func newNatsMicroservice(cfg natsMicroserviceConfig) *microservice {
 					FailureThreshold:    3,
 				},
 				livenessProbe: &corev1.Probe{
-					ProbeHandler:        corev1.ProbeHandler{HTTPGet: &corev1.HTTPGetAction{Path: "/healthz?js-enabled-only=true", Port: intstr.FromInt32(nats.DefaultHttpPort)}},
+					ProbeHandler:        corev1.ProbeHandler{HTTPGet: &corev1.HTTPGetAction{Path: "/healthz?js-enabled-only=true", Port: intstr.FromInt(ports.HttpPort)}},
 					InitialDelaySeconds: 10,
 					TimeoutSeconds:      5,
 					PeriodSeconds:       30,
@@ -1153,7 +1216,7 @@ func newNatsMicroservice(cfg natsMicroserviceConfig) *microservice {
 					SuccessThreshold:    1,
 				},
 				// startupProbe: &corev1.Probe{
-				// 	ProbeHandler:        corev1.ProbeHandler{HTTPGet: &corev1.HTTPGetAction{Path: "/healthz", Port: intstr.FromInt32(nats.DefaultHttpPort)}},
+				// 	ProbeHandler:        corev1.ProbeHandler{HTTPGet: &corev1.HTTPGetAction{Path: "/healthz", Port: intstr.FromInt(ports.HttpPort)}},
 				// 	InitialDelaySeconds: 10,
 				// 	TimeoutSeconds:      5,
 				// 	PeriodSeconds:       10,
@@ -1168,175 +1231,47 @@ func newNatsMicroservice(cfg natsMicroserviceConfig) *microservice {
 	}
 }
 
-// newRouterMicroserviceWithName creates a router microservice with a custom name
-func newRouterMicroserviceWithName(cfg routerMicroserviceConfig, name string) *microservice {
-	cfg = filterRouterConfig(cfg)
+// defaultNatsScheduling spreads NATS replicas across nodes and zones unless the spec configures the placement,
+// so that a single node loss cannot take out the JetStream quorum
+func defaultNatsScheduling(scheduling cpv3.PodScheduling, labels map[string]string) cpv3.PodScheduling {
+	selector := &metav1.LabelSelector{MatchLabels: labels}
 
-	return &microservice{
-		name: name,
-		labels: map[string]string{
-			"datasance.com/component": routerName,
-			"application":             "interior-router",
-			"skupper.io/component":    "router",
-			"skupper.io/type":         "site",
-		},
-		annotations: map[string]string{
-			"prometheus.io/port":   "9090",
-			"prometheus.io/scrape": "true",
-		},
-		services:        []service{}, // Secondary router doesn't create its own service
-		imagePullSecret: cfg.imagePullSecret,
-		replicas:        1,
-		rbacRules: []rbacv1.PolicyRule{
-			{
-				Verbs:     []string{"get", "list", "watch"},
-				APIGroups: []string{""},
-				Resources: []string{"pods", "services", "endpoints"},
-			},
-			{
-				Verbs:     []string{"get", "list", "watch"},
-				APIGroups: []string{"apps"},
-				Resources: []string{"deployments"},
-			},
-		},
-		volumes: []corev1.Volume{
-			{
-				Name: "iofog-router-config",
-				VolumeSource: corev1.VolumeSource{
-					ConfigMap: &corev1.ConfigMapVolumeSource{
-						LocalObjectReference: corev1.LocalObjectReference{Name: "iofog-router"},
-						Items: []corev1.KeyToPath{
-							{Key: "skrouterd.json", Path: "skrouterd.json"},
+	if scheduling.Affinity == nil {
+		scheduling.Affinity = &corev1.Affinity{
+			PodAntiAffinity: &corev1.PodAntiAffinity{
+				PreferredDuringSchedulingIgnoredDuringExecution: []corev1.WeightedPodAffinityTerm{
+					{
+						Weight: 100, //nolint:gomnd
+						PodAffinityTerm: corev1.PodAffinityTerm{
+							LabelSelector: selector,
+							TopologyKey:   corev1.LabelHostname,
 						},
 					},
 				},
 			},
+		}
+	}
+
+	if len(scheduling.TopologySpreadConstraints) == 0 {
+		// Tainted nodes are not counted, otherwise they would block the spread over the remaining nodes
+		scheduling.TopologySpreadConstraints = []corev1.TopologySpreadConstraint{
 			{
-				Name: "router-site-server",
-				VolumeSource: corev1.VolumeSource{
-					Secret: &corev1.SecretVolumeSource{
-						SecretName: "router-site-server",
-					},
-				},
+				MaxSkew:           1,
+				TopologyKey:       corev1.LabelHostname,
+				WhenUnsatisfiable: corev1.DoNotSchedule,
+				LabelSelector:     selector,
+				NodeTaintsPolicy:  ptr.To(corev1.NodeInclusionPolicyHonor),
 			},
 			{
-				Name: "router-local-server",
-				VolumeSource: corev1.VolumeSource{
-					Secret: &corev1.SecretVolumeSource{
-						SecretName: "router-local-server",
-					},
-				},
+				MaxSkew:           1,
+				TopologyKey:       corev1.LabelTopologyZone,
+				WhenUnsatisfiable: corev1.ScheduleAnyway,
+				LabelSelector:     selector,
 			},
-		},
-		containers: []container{
-			{
-				name:            routerName,
-				image:           cfg.image,
-				imagePullPolicy: "Always",
-				command: []string{
-					"/home/skrouterd/bin/launch.sh",
-				},
-				ports: []corev1.ContainerPort{
-					{
-						Name:          "amqps",
-						ContainerPort: 5671,
-						Protocol:      corev1.ProtocolTCP,
-					},
-					{
-						Name:          "http",
-						ContainerPort: 9090,
-						Protocol:      corev1.ProtocolTCP,
-					},
-					{
-						Name:          "inter-router",
-						ContainerPort: 55671,
-						Protocol:      corev1.ProtocolTCP,
-					},
-					{
-						Name:          "edge",
-						ContainerPort: 45671,
-						Protocol:      corev1.ProtocolTCP,
-					},
-				},
-				readinessProbe: &corev1.Probe{
-					ProbeHandler: corev1.ProbeHandler{
-						HTTPGet: &corev1.HTTPGetAction{
-							Port:   intstr.FromInt(9090),
-							Path:   "/healthz",
-							Scheme: corev1.URISchemeHTTP,
-						},
-					},
-					InitialDelaySeconds: 1,
-					TimeoutSeconds:      1,
-					PeriodSeconds:       10,
-					FailureThreshold:    3,
-					SuccessThreshold:    1,
-				},
-				livenessProbe: &corev1.Probe{
-					ProbeHandler: corev1.ProbeHandler{
-						HTTPGet: &corev1.HTTPGetAction{
-							Port:   intstr.FromInt(9090),
-							Path:   "/healthz",
-							Scheme: corev1.URISchemeHTTP,
-						},
-					},
-					InitialDelaySeconds: 60,
-					TimeoutSeconds:      1,
-					PeriodSeconds:       10,
-					FailureThreshold:    3,
-					SuccessThreshold:    1,
-				},
-				env: []corev1.EnvVar{
-					{
-						Name:  "APPLICATION_NAME",
-						Value: routerName,
-					},
-					{
-						Name:  "QDROUTERD_AUTO_MESH_DISCOVERY",
-						Value: "QUERY",
-					},
-					{
-						Name:  "QDROUTERD_CONF",
-						Value: "/tmp/skrouterd.json",
-					},
-					{
-						Name:  "QDROUTERD_CONF_TYPE",
-						Value: "json",
-					},
-					{
-						Name:  "SSL_PROFILE_PATH",
-						Value: "/etc/skupper-router-certs",
-					},
-					{
-						Name:  "SKUPPER_SITE_ID",
-						Value: "default-router",
-					},
-					{
-						Name:  "SKUPPER_SITE_NAME",
-						Value: "default-router",
-					},
-					{
-						Name:  "SKUPPER_PLATFORM",
-						Value: "kubernetes",
-					},
-				},
-				volumeMounts: []corev1.VolumeMount{
-					{
-						Name:      "iofog-router-config",
-						MountPath: "/tmp",
-					},
-					{
-						Name:      "router-site-server",
-						MountPath: "/etc/skupper-router-certs/router-site-server",
-					},
-					{
-						Name:      "router-local-server",
-						MountPath: "/etc/skupper-router-certs/router-local-server",
-					},
-				},
-			},
-		},
+		}
 	}
+
+	return scheduling
 }
 
 func getSSLValue(ssl *bool) string {
@@ -33,10 +33,16 @@ const (
 	NatsMqttServerSecret = "nats-mqtt-server"
 )
 
-// natsSiteServerHosts returns comma-separated SANs for the NATS site server cert (same pattern as router createRouterSecrets):
+// CASecrets name the Secrets of the CAs that sign the server certificates, provided CAs stand in for the generated ones
+type CASecrets struct {
+	Site  string
+	Local string
+}
+
+// SiteServerHosts returns comma-separated SANs for the NATS site server cert (same pattern as router createRouterSecrets):
 // internal: nats-0.<headless>, nats-1.<headless>, ..., *.headless.ns.svc.cluster.local, nats.ns.svc.cluster.local;
 // when address is non-empty (LB host or ingress host), also include it so external clients validate.
-func natsSiteServerHosts(headlessName, namespace string, replicas int, address string) string {
+func SiteServerHosts(headlessName, namespace string, replicas int, address string) string {
 	hosts := ""
 	for i := 0; i < replicas; i++ {
 		if i > 0 {
@@ -56,23 +62,25 @@ func natsSiteServerHosts(headlessName, namespace string, replicas int, address s
 }
 
 // EnsureNatsSecrets ensures NATS TLS secrets exist in the namespace. Creates nats-site-ca, nats-site-server,
-// default-nats-local-ca, nats-mqtt-server. Uses same pattern as router (util.GenerateSecret).
+// default-nats-local-ca, nats-mqtt-server. Uses same pattern as router (util.Issue).
 // headlessName is typically "nats-headless". address is the external host (LB or ingress) for SANs, like createRouterSecrets.
-func EnsureNatsSecrets(ctx context.Context, getSecret func(context.Context, types.NamespacedName, *corev1.Secret) error, namespace, instanceName, headlessName string, replicas int, address string, labels map[string]string) ([]corev1.Secret, error) {
+// The server certificates are signed by the CAs of cas, only the generated CAs are created.
+// The hosts of the server certificates are recorded in util.HostsAnnotation.
+func EnsureNatsSecrets(ctx context.Context, getSecret func(context.Context, types.NamespacedName, *corev1.Secret) error, namespace, instanceName, headlessName string, replicas int, address string, labels map[string]string, cas CASecrets) ([]corev1.Secret, error) {
 	siteCA := &corev1.Secret{}
 	localCA := &corev1.Secret{}
 	siteServer := &corev1.Secret{}
 	mqttServer := &corev1.Secret{}
 
-	err := getSecret(ctx, types.NamespacedName{Name: NatsSiteCASecret, Namespace: namespace}, siteCA)
+	err := getSecret(ctx, types.NamespacedName{Name: cas.Site, Namespace: namespace}, siteCA)
 	siteCAExists := err == nil
-	if err != nil && !k8serrors.IsNotFound(err) {
+	if err != nil && (!k8serrors.IsNotFound(err) || cas.Site != NatsSiteCASecret) {
 		return nil, err
 	}
 
-	err = getSecret(ctx, types.NamespacedName{Name: NatsLocalCASecret, Namespace: namespace}, localCA)
+	err = getSecret(ctx, types.NamespacedName{Name: cas.Local, Namespace: namespace}, localCA)
 	localCAExists := err == nil
-	if err != nil && !k8serrors.IsNotFound(err) {
+	if err != nil && (!k8serrors.IsNotFound(err) || cas.Local != NatsLocalCASecret) {
 		return nil, err
 	}
 
@@ -90,8 +98,13 @@ func EnsureNatsSecrets(ctx context.Context, getSecret func(context.Context, type
 
 	var out []corev1.Secret
 
+	hosts := SiteServerHosts(headlessName, namespace, replicas, address)
+
 	if !siteCAExists {
-		s := util.GenerateSecret(NatsSiteCASecret, NatsSiteCASecret, NatsSiteCASecret, 0, nil)
+		s, err := util.IssueCA(NatsSiteCASecret, util.Options{})
+		if err != nil {
+			return nil, err
+		}
 		s.Namespace = namespace
 		s.Labels = labels
 		out = append(out, s)
@@ -99,7 +112,10 @@ func EnsureNatsSecrets(ctx context.Context, getSecret func(context.Context, type
 	}
 
 	if !localCAExists {
-		s := util.GenerateSecret(NatsLocalCASecret, NatsLocalCASecret, NatsLocalCASecret, 0, nil)
+		s, err := util.IssueCA(NatsLocalCASecret, util.Options{})
+		if err != nil {
+			return nil, err
+		}
 		s.Namespace = namespace
 		s.Labels = labels
 		out = append(out, s)
@@ -107,27 +123,38 @@ func EnsureNatsSecrets(ctx context.Context, getSecret func(context.Context, type
 	}
 
 	if !siteServerExists {
-		hosts := natsSiteServerHosts(headlessName, namespace, replicas, address)
-		s := util.GenerateSecret(NatsSiteServerSecret, "iofog-nats", hosts, 0, siteCA)
+		dnsNames, ipAddresses := util.ParseHosts(hosts)
+		s, err := util.Issue(NatsSiteServerSecret, util.Options{CommonName: "iofog-nats", DNSNames: dnsNames, IPAddresses: ipAddresses}, siteCA)
+		if err != nil {
+			return nil, err
+		}
 		s.Namespace = namespace
 		s.Labels = labels
+		// ca.crt of the CA also trusts the previous CA during a rotation
+		s.Data["ca.crt"] = util.CABundle(siteCA)
 		if s.Annotations == nil {
 			s.Annotations = make(map[string]string)
 		}
 		s.Annotations["datasance.com/nats-replicas"] = strconv.Itoa(replicas)
+		s.Annotations[util.HostsAnnotation] = hosts
 		out = append(out, s)
 	}
 
 	if !mqttServerExists {
 		// MQTT server cert: same SANs as site server for NATS client connectivity
-		hosts := natsSiteServerHosts(headlessName, namespace, replicas, address)
-		s := util.GenerateSecret(NatsMqttServerSecret, "iofog-nats-mqtt", hosts, 0, localCA)
+		dnsNames, ipAddresses := util.ParseHosts(hosts)
+		s, err := util.Issue(NatsMqttServerSecret, util.Options{CommonName: "iofog-nats-mqtt", DNSNames: dnsNames, IPAddresses: ipAddresses}, localCA)
+		if err != nil {
+			return nil, err
+		}
 		s.Namespace = namespace
 		s.Labels = labels
+		s.Data["ca.crt"] = util.CABundle(localCA)
 		if s.Annotations == nil {
 			s.Annotations = make(map[string]string)
 		}
 		s.Annotations["datasance.com/nats-replicas"] = strconv.Itoa(replicas)
+		s.Annotations[util.HostsAnnotation] = hosts
 		out = append(out, s)
 	}
 
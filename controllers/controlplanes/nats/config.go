@@ -28,6 +28,26 @@ const (
 	DefaultHttpPort    = 8222
 )
 
+// Ports are the NATS listener ports. The server.conf, the container, the probes and the Services all use them.
+type Ports struct {
+	ServerPort  int
+	ClusterPort int
+	LeafPort    int
+	MqttPort    int
+	HttpPort    int
+}
+
+// DefaultPorts returns the ports NATS listens on when none are configured.
+func DefaultPorts() Ports {
+	return Ports{
+		ServerPort:  DefaultServerPort,
+		ClusterPort: DefaultClusterPort,
+		LeafPort:    DefaultLeafPort,
+		MqttPort:    DefaultMqttPort,
+		HttpPort:    DefaultHttpPort,
+	}
+}
+
 // Default JetStream storage sizes for NATS server.conf (max_file_store, max_memory_store).
 // NATS uses decimal units: G, M, T, K (not Gi, Mi, Ti, Ki).
 const (
@@ -206,10 +226,10 @@ func BuildServerConf(p ServerConfParams) string {
 
 // ClusterRoutesFormat returns the NATS cluster routes as a YAML array string for server.conf.
 // NATS expects routes to be an array, not a comma-separated string (avoids "interface {} is string, not []interface {}").
-func ClusterRoutesFormat(headlessName string, replicas int) string {
+func ClusterRoutesFormat(headlessName string, replicas int, clusterPort int) string {
 	parts := make([]string, replicas)
 	for i := 0; i < replicas; i++ {
-		parts[i] = fmt.Sprintf(`"nats://nats-%d.%s:%d"`, i, headlessName, DefaultClusterPort)
+		parts[i] = fmt.Sprintf(`"nats://nats-%d.%s:%d"`, i, headlessName, clusterPort)
 	}
 	return "[" + strings.Join(parts, ", ") + "]"
 }
@@ -250,28 +270,38 @@ func parseRoutesFromServerConf(serverConf string) []string {
 }
 
 // isK8sOrdinalRoute returns true if the route URL is a K8s StatefulSet ordinal route we generate.
-func isK8sOrdinalRoute(route, headlessName string, clusterPort int) bool {
+// Any port matches so that routes generated before a cluster port change are replaced as well.
+func isK8sOrdinalRoute(route, headlessName string) bool {
 	prefix := "nats://nats-"
-	suffix := fmt.Sprintf(".%s:%d", headlessName, clusterPort)
-	if !strings.HasPrefix(route, prefix) || !strings.HasSuffix(route, suffix) {
+	if !strings.HasPrefix(route, prefix) {
+		return false
+	}
+	host, port, found := strings.Cut(strings.TrimPrefix(route, prefix), ":")
+	if !found {
+		return false
+	}
+	ordinal, found := strings.CutSuffix(host, "."+headlessName)
+	if !found {
+		return false
+	}
+	if _, err := strconv.Atoi(ordinal); err != nil {
 		return false
 	}
-	mid := strings.TrimPrefix(strings.TrimSuffix(route, suffix), prefix)
-	_, err := strconv.Atoi(mid)
+	_, err := strconv.Atoi(port)
 	return err == nil
 }
 
 // ClusterRoutesMerge returns cluster routes for server.conf: K8s ordinal routes for 0..replicas-1
 // plus any existing non-K8s routes (e.g. controller-added agent nodes). If existingServerConf is
-// empty or parsing fails, returns ClusterRoutesFormat(headlessName, replicas).
+// empty or parsing fails, returns ClusterRoutesFormat(headlessName, replicas, clusterPort).
 // Operator-managed routes are replaced (not appended); other routes are deduplicated.
 func ClusterRoutesMerge(headlessName string, replicas int, clusterPort int, existingServerConf string) string {
 	if existingServerConf == "" {
-		return ClusterRoutesFormat(headlessName, replicas)
+		return ClusterRoutesFormat(headlessName, replicas, clusterPort)
 	}
 	existing := parseRoutesFromServerConf(existingServerConf)
 	if existing == nil {
-		return ClusterRoutesFormat(headlessName, replicas)
+		return ClusterRoutesFormat(headlessName, replicas, clusterPort)
 	}
 	// Build desired K8s routes (replaces any existing K8s ordinal routes)
 	k8sRoutes := make([]string, 0, replicas)
@@ -290,7 +320,7 @@ func ClusterRoutesMerge(headlessName string, replicas int, clusterPort int, exis
 		if norm == "" {
 			continue
 		}
-		if isK8sOrdinalRoute(norm, headlessName, clusterPort) {
+		if isK8sOrdinalRoute(norm, headlessName) {
 			continue
 		}
 		if _, ok := k8sSet[norm]; ok {
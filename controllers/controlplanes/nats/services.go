@@ -19,15 +19,40 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
-// NewNatsHeadlessService creates the headless Service for the NATS StatefulSet (all ports).
-func NewNatsHeadlessService(namespace string, labels map[string]string) *corev1.Service {
-	ports := []corev1.ServicePort{
-		{Name: "cluster", Port: int32(DefaultClusterPort), TargetPort: intstr.FromInt(DefaultClusterPort)},
-		{Name: "leaf", Port: int32(DefaultLeafPort), TargetPort: intstr.FromInt(DefaultLeafPort)},
-		{Name: "mqtt", Port: int32(DefaultMqttPort), TargetPort: intstr.FromInt(DefaultMqttPort)},
-		{Name: "client", Port: int32(DefaultServerPort), TargetPort: intstr.FromInt(DefaultServerPort)},
-		{Name: "monitor", Port: int32(DefaultHttpPort), TargetPort: intstr.FromInt(DefaultHttpPort)},
+// HeadlessServicePorts are all NATS ports (StatefulSet pod discovery: nats-0.nats-headless, etc.).
+func HeadlessServicePorts(p Ports) []corev1.ServicePort {
+	return []corev1.ServicePort{
+		servicePort("cluster", p.ClusterPort),
+		servicePort("leaf", p.LeafPort),
+		servicePort("mqtt", p.MqttPort),
+		servicePort("client", p.ServerPort),
+		servicePort("monitor", p.HttpPort),
+	}
+}
+
+// ClientServicePorts are the client-facing ports: cluster, leaf and mqtt.
+func ClientServicePorts(p Ports) []corev1.ServicePort {
+	return []corev1.ServicePort{
+		servicePort("cluster", p.ClusterPort),
+		servicePort("leaf", p.LeafPort),
+		servicePort("mqtt", p.MqttPort),
+	}
+}
+
+// ServerServicePorts are the client and monitor ports.
+func ServerServicePorts(p Ports) []corev1.ServicePort {
+	return []corev1.ServicePort{
+		servicePort("client", p.ServerPort),
+		servicePort("monitor", p.HttpPort),
 	}
+}
+
+func servicePort(name string, port int) corev1.ServicePort {
+	return corev1.ServicePort{Name: name, Port: int32(port), TargetPort: intstr.FromInt(port)}
+}
+
+// NewNatsHeadlessService creates the headless Service for the NATS StatefulSet (all ports).
+func NewNatsHeadlessService(namespace string, labels map[string]string, p Ports) *corev1.Service {
 	return &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      HeadlessServiceName,
@@ -37,18 +62,13 @@ func NewNatsHeadlessService(namespace string, labels map[string]string) *corev1.
 		Spec: corev1.ServiceSpec{
 			ClusterIP: corev1.ClusterIPNone,
 			Selector:  labels,
-			Ports:     ports,
+			Ports:     HeadlessServicePorts(p),
 		},
 	}
 }
 
 // NewNatsClientService creates the client-facing Service for NATS (cluster, leaf, mqtt).
-func NewNatsClientService(namespace string, labels map[string]string, serviceType corev1.ServiceType, annotations map[string]string) *corev1.Service {
-	ports := []corev1.ServicePort{
-		{Name: "cluster", Port: int32(DefaultClusterPort), TargetPort: intstr.FromInt(DefaultClusterPort)},
-		{Name: "leaf", Port: int32(DefaultLeafPort), TargetPort: intstr.FromInt(DefaultLeafPort)},
-		{Name: "mqtt", Port: int32(DefaultMqttPort), TargetPort: intstr.FromInt(DefaultMqttPort)},
-	}
+func NewNatsClientService(namespace string, labels map[string]string, serviceType corev1.ServiceType, annotations map[string]string, p Ports) *corev1.Service {
 	return &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:        ClientServiceName,
@@ -59,17 +79,13 @@ func NewNatsClientService(namespace string, labels map[string]string, serviceTyp
 		Spec: corev1.ServiceSpec{
 			Type:     serviceType,
 			Selector: labels,
-			Ports:    ports,
+			Ports:    ClientServicePorts(p),
 		},
 	}
 }
 
 // NewNatsServerService creates the nats-server Service (client and monitor ports only, ClusterIP).
-func NewNatsServerService(namespace string, labels map[string]string) *corev1.Service {
-	ports := []corev1.ServicePort{
-		{Name: "client", Port: int32(DefaultServerPort), TargetPort: intstr.FromInt(DefaultServerPort)},
-		{Name: "monitor", Port: int32(DefaultHttpPort), TargetPort: intstr.FromInt(DefaultHttpPort)},
-	}
+func NewNatsServerService(namespace string, labels map[string]string, p Ports) *corev1.Service {
 	return &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      ServerServiceName,
@@ -78,7 +94,7 @@ func NewNatsServerService(namespace string, labels map[string]string) *corev1.Se
 		},
 		Spec: corev1.ServiceSpec{
 			Selector: labels,
-			Ports:    ports,
+			Ports:    ServerServicePorts(p),
 		},
 	}
 }
@@ -2,9 +2,12 @@ package controllers
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"net/url"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -22,12 +25,17 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/types"
-	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 const (
+	// routerCertsHashAnnotation is set on the router pod templates so new certificates roll the routers
+	routerCertsHashAnnotation = "datasance.com/router-certs-hash"
+	// routerConfigHashAnnotation does the same for skrouterd.json
+	routerConfigHashAnnotation = "datasance.com/router-config-hash"
+
 	loadBalancerTimeout   = 360
 	errProxyRouterMissing = "missing Proxy.Router data for non LoadBalancer Router service"
 	errParseControllerURL = "failed to parse Controller endpoint as URL (%s): %s"
@@ -54,8 +62,14 @@ func getVaultIfConfigured(spec cpv3.ControlPlaneSpec) *cpv3.Vault {
 	return nil
 }
 
-func reconcileRoutine(ctx context.Context, recon func(context.Context) op.Reconciliation, reconChan chan op.Reconciliation) {
-	reconChan <- recon(ctx)
+func (r *ControlPlaneReconciler) reconcileRoutine(ctx context.Context, component, conditionType string, recon func(context.Context) op.Reconciliation, reconChan chan op.Reconciliation) {
+	start := time.Now()
+	result := recon(ctx)
+
+	observeReconcile(component, start, result)
+	r.setComponentResult(conditionType, result)
+
+	reconChan <- result
 }
 
 func (r *ControlPlaneReconciler) reconcileDBCredentialsSecret(ctx context.Context, ms *microservice) (shouldRestartPod bool, err error) {
@@ -65,32 +79,9 @@ func (r *ControlPlaneReconciler) reconcileDBCredentialsSecret(ctx context.Contex
 
 		if secret.Name == controllerDBCredentialsSecretName {
 			secret.Labels = mergeLabels(stdLabels, secret.Labels)
-			if setErr := controllerutil.SetControllerReference(&r.cp, secret, r.Scheme); setErr != nil {
-				return false, setErr
-			}
-			found := &corev1.Secret{}
 
-			err := r.Client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}, found)
-			if err != nil {
-				if !k8serrors.IsNotFound(err) {
-					return false, err
-				}
-				// Create secret
-				err = r.Client.Create(ctx, secret)
-				if err != nil {
-					return false, err
-				}
-
-				return false, nil
-			}
-			// Secret already exists
-			// Update secret
-			err = r.Client.Update(ctx, secret)
-			if err != nil {
-				return false, err
-			}
-			// Restart pod
-			return true, nil
+			// Restart pod when the credentials changed
+			return r.applyObject(ctx, secret)
 		}
 	}
 
@@ -105,24 +96,7 @@ func (r *ControlPlaneReconciler) reconcileVaultCredentialsSecret(ctx context.Con
 			continue
 		}
 		secret.Labels = mergeLabels(stdLabels, secret.Labels)
-		if setErr := controllerutil.SetControllerReference(&r.cp, secret, r.Scheme); setErr != nil {
-			return false, setErr
-		}
-		found := &corev1.Secret{}
-		getErr := r.Client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}, found)
-		if getErr != nil {
-			if !k8serrors.IsNotFound(getErr) {
-				return false, getErr
-			}
-			if createErr := r.Client.Create(ctx, secret); createErr != nil {
-				return false, createErr
-			}
-			return false, nil
-		}
-		if updateErr := r.Client.Update(ctx, secret); updateErr != nil {
-			return false, updateErr
-		}
-		return true, nil
+		return r.applyObject(ctx, secret)
 	}
 	return false, nil
 }
@@ -137,8 +111,9 @@ func (r *ControlPlaneReconciler) reconcileIofogController(ctx context.Context) o
 		routerImage:           r.cp.Spec.Images.Router,
 		natsImage:             r.cp.Spec.Images.Nats,
 		natsEnabled:           isNatsEnabled(r.cp),
-		db:                    &r.cp.Spec.Database,
-		auth:                  &r.cp.Spec.Auth,
+		db:                    &r.credentials.db,
+		auth:                  &r.credentials.auth,
+		credentialsHash:       r.credentials.hash,
 		serviceType:           r.cp.Spec.Services.Controller.Type,
 		serviceAnnotations:    r.cp.Spec.Services.Controller.Annotations,
 		externalTrafficPolicy: r.cp.Spec.Services.Controller.ExternalTrafficPolicy,
@@ -151,7 +126,9 @@ func (r *ControlPlaneReconciler) reconcileIofogController(ctx context.Context) o
 		ecnViewerURL:          r.cp.Spec.Controller.EcnViewerURL,
 		logLevel:              r.cp.Spec.Controller.LogLevel,
 		events:                getEventsIfConfigured(r.cp.Spec.Events),
-		vault:                 getVaultIfConfigured(r.cp.Spec),
+		vault:                 r.credentials.vault,
+		resources:             r.cp.Spec.Resources.Controller,
+		scheduling:            r.cp.Spec.Scheduling.Controller,
 	}
 
 	ingressConfig := &controllerIngressConfig{
@@ -214,8 +191,12 @@ func (r *ControlPlaneReconciler) reconcileIofogController(ctx context.Context) o
 		return op.ReconcileWithError(err)
 	}
 
-	// Ingress
-	if strings.EqualFold(r.cp.Spec.Services.Controller.Type, string(corev1.ServiceTypeClusterIP)) {
+	// Ingress, a Gateway HTTPRoute takes its place when configured
+	if err := r.reconcileControllerRoute(ctx, ms); err != nil {
+		return op.ReconcileWithError(err)
+	}
+
+	if strings.EqualFold(r.cp.Spec.Services.Controller.Type, string(corev1.ServiceTypeClusterIP)) && !isControllerGatewayEnabled(r.cp) {
 		if err := r.createIngress(ctx, ingressConfig); err != nil {
 			return op.ReconcileWithError(err)
 		}
@@ -236,6 +217,11 @@ func (r *ControlPlaneReconciler) reconcileIofogController(ctx context.Context) o
 		return op.ReconcileWithError(err)
 	}
 
+	// PodDisruptionBudget
+	if err := r.reconcilePodDisruptionBudget(ctx, controllerName, ms.replicas, r.cp.Spec.PodDisruptionBudgets.Controller); err != nil {
+		return op.ReconcileWithError(err)
+	}
+
 	// The deployment was just created, requeue to hide latency
 	if !alreadyExists {
 		return op.ReconcileWithRequeue(time.Second * 5) //nolint:gomnd
@@ -256,6 +242,7 @@ func (r *ControlPlaneReconciler) reconcileIofogController(ctx context.Context) o
 	if err := r.loginIofogClient(iofogClient); err != nil {
 		if !strings.Contains(strings.ToLower(err.Error()), "invalid credentials") {
 			r.log.Info(fmt.Sprintf("Could not login to ControlPlane %s: %s", r.cp.Name, err.Error()))
+			r.recordWarning(eventReasonLoginFailed, "Could not login to the Controller: %s", err.Error())
 			return op.ReconcileWithError(err)
 		}
 	}
@@ -269,19 +256,19 @@ func (r *ControlPlaneReconciler) reconcileIofogController(ctx context.Context) o
 	// Get Router or Router Proxy
 	var routerProxy cpv3.RouterIngress
 
-	if strings.EqualFold(r.cp.Spec.Services.Router.Type, string(corev1.ServiceTypeLoadBalancer)) {
+	if isRouterGatewayEnabled(r.cp) {
+		routerProxy, err = r.getRouterGatewayIngress(ctx)
+		if err != nil {
+			return op.ReconcileWithError(err)
+		}
+	} else if strings.EqualFold(r.cp.Spec.Services.Router.Type, string(corev1.ServiceTypeLoadBalancer)) {
 		//nolint:contextcheck // k8sClient unfortunately does not accept context
-		routerAddr, err := k8sClient.WaitForLoadBalancer(r.cp.Namespace, routerName, loadBalancerTimeout)
+		routerAddr, err := r.waitForLoadBalancer(k8sClient, r.cp.Namespace, routerName, r.getEndpoint(getRouterEndpoint))
 		if err != nil {
 			return op.ReconcileWithError(err)
 		}
 
-		routerProxy = cpv3.RouterIngress{
-			Address:      routerAddr,
-			MessagePort:  router.MessagePort,
-			InteriorPort: router.InteriorPort,
-			EdgePort:     router.EdgePort,
-		}
+		routerProxy = cpv3.RouterIngress{Address: routerAddr}
 	} else if r.cp.Spec.Ingresses.Router.Address != "" {
 		routerProxy = r.cp.Spec.Ingresses.Router
 	} else {
@@ -290,6 +277,36 @@ func (r *ControlPlaneReconciler) reconcileIofogController(ctx context.Context) o
 		return op.ReconcileWithError(err)
 	}
 
+	// Ports that are not advertised explicitly are the ones the routers listen on
+	if routerProxy.MessagePort == 0 {
+		routerProxy.MessagePort = r.cp.Spec.Router.Ports.MessagePort
+	}
+
+	if routerProxy.InteriorPort == 0 {
+		routerProxy.InteriorPort = r.cp.Spec.Router.Ports.InteriorPort
+	}
+
+	if routerProxy.EdgePort == 0 {
+		routerProxy.EdgePort = r.cp.Spec.Router.Ports.EdgePort
+	}
+
+	// The router Service only sends traffic to available routers, with HA the default router stays reachable
+	// through the remaining router while the other one is down
+	available, err := r.getAvailableRouters(ctx)
+	if err != nil {
+		return op.ReconcileWithError(err)
+	}
+
+	if len(available) == 0 {
+		r.log.Info(fmt.Sprintf("Waiting for a router to become available to register the default router for ControlPlane %s", r.cp.Name))
+
+		return op.ReconcileWithRequeue(time.Second * 5) //nolint:gomnd
+	}
+
+	if len(available) < len(getRouterNames(r.cp)) {
+		r.log.Info(fmt.Sprintf("Registering the default router of ControlPlane %s through router %s, the other router is unavailable", r.cp.Name, available[0]))
+	}
+
 	if err := r.createDefaultRouter(iofogClient, routerProxy); err != nil {
 		return op.ReconcileWithError(err)
 	}
@@ -297,24 +314,31 @@ func (r *ControlPlaneReconciler) reconcileIofogController(ctx context.Context) o
 	// Register default NATS hub when NATS is enabled
 	if isNatsEnabled(r.cp) {
 		natsIngress := r.cp.Spec.Ingresses.Nats
-		if strings.EqualFold(r.cp.Spec.Services.Nats.Type, string(corev1.ServiceTypeLoadBalancer)) {
+		if isNatsGatewayEnabled(r.cp) {
+			natsIngress, err = r.getNatsGatewayIngress(ctx)
+			if err != nil {
+				return op.ReconcileWithError(err)
+			}
+		} else if strings.EqualFold(r.cp.Spec.Services.Nats.Type, string(corev1.ServiceTypeLoadBalancer)) {
 			//nolint:contextcheck // k8sClient does not accept context
-			natsAddr, err := k8sClient.WaitForLoadBalancer(r.cp.Namespace, nats.ClientServiceName, loadBalancerTimeout)
+			natsAddr, err := r.waitForLoadBalancer(k8sClient, r.cp.Namespace, nats.ClientServiceName, r.getEndpoint(getNatsEndpoint))
 			if err != nil {
 				return op.ReconcileWithError(err)
 			}
-			natsIngress.Address = natsAddr
+			// The LoadBalancer exposes the listener ports
+			natsIngress = cpv3.NatsIngress{Address: natsAddr}
 		}
 		if natsIngress.Address != "" {
-			if err := r.createDefaultNatsHub(iofogClient, natsIngress); err != nil {
+			if err := r.createDefaultNatsHub(iofogClient, natsIngress, getNatsPorts(r.cp.Spec.Nats)); err != nil {
 				r.log.Info(fmt.Sprintf("Failed to register NATS hub for ControlPlane %s: %s", r.cp.Name, err.Error()))
+				r.recordWarning(eventReasonNatsHubRegistrationFailed, "Failed to register NATS hub %s: %s", natsIngress.Address, err.Error())
 				return op.ReconcileWithRequeue(time.Second * 10)
 			}
 		}
 	}
 
 	// Import router and NATS certificates
-	if recon := r.ImportCertificates(iofogClient); recon.IsFinal() {
+	if recon := r.ImportCertificates(ctx, iofogClient); recon.IsFinal() {
 		return recon
 	}
 
@@ -325,7 +349,7 @@ func (r *ControlPlaneReconciler) reconcileIofogController(ctx context.Context) o
 
 	if strings.EqualFold(r.cp.Spec.Services.Controller.Type, string(corev1.ServiceTypeLoadBalancer)) {
 		//nolint:contextcheck // k8sClient unfortunately does not accept context
-		host, err := k8sClient.WaitForLoadBalancer(r.cp.Namespace, controllerName, loadBalancerTimeout)
+		host, err := r.waitForLoadBalancer(k8sClient, r.cp.Namespace, controllerName, r.getEndpoint(getControllerEndpointHost))
 		if err != nil {
 			return op.ReconcileWithError(err)
 		}
@@ -338,7 +362,14 @@ func (r *ControlPlaneReconciler) reconcileIofogController(ctx context.Context) o
 		viewerEndpoint = fmt.Sprintf("%s://%s", scheme, host)
 	}
 
-	if strings.EqualFold(r.cp.Spec.Services.Controller.Type, string(corev1.ServiceTypeClusterIP)) {
+	if isControllerGatewayEnabled(r.cp) {
+		gw, err := r.getGatewayStatus(ctx)
+		if err != nil {
+			return op.ReconcileWithError(err)
+		}
+
+		viewerEndpoint = fmt.Sprintf("%s://%s", gw.getHTTPScheme(r.cp.Spec.Gateway.Controller.Listener), gw.getAdvertisedAddress(r.cp.Spec.Gateway.Controller.Hostnames))
+	} else if strings.EqualFold(r.cp.Spec.Services.Controller.Type, string(corev1.ServiceTypeClusterIP)) {
 		// Retrieve the Ingress resource
 		ingress := &networkingv1.Ingress{}
 		err := r.Client.Get(ctx, types.NamespacedName{Name: "pot-controller", Namespace: r.cp.Namespace}, ingress)
@@ -367,12 +398,19 @@ func (r *ControlPlaneReconciler) reconcileIofogController(ctx context.Context) o
 	// Update ECN Viewer Client Root URL
 	if viewerEndpoint != "" {
 		r.log.Info(fmt.Sprintf("Updating ECN Viewer Client Root URL for ControlPlane %s to %s", r.cp.Name, viewerEndpoint))
-		if err := openidutil.UpdateECNViewerClientRootURL(r.cp.Spec.Auth, viewerEndpoint); err != nil {
+		if err := openidutil.UpdateECNViewerClientRootURL(r.credentials.auth, viewerEndpoint); err != nil {
 			r.log.Info(fmt.Sprintf("Failed to update ECN Viewer Client Root URL for ControlPlane %s: %s", r.cp.Name, err.Error()))
 			// Continue even if update fails, as it's not critical for the reconcile process
 		}
 	}
 
+	controllerEndpoint := viewerEndpoint
+	if controllerEndpoint == "" {
+		controllerEndpoint = fmt.Sprintf("%s://%s:%d", scheme, host, ctrlPort) //nolint:nosprintfhostport
+	}
+
+	r.setEndpoint(func(endpoints *cpv3.EndpointsStatus) { endpoints.Controller = controllerEndpoint })
+
 	r.log.Info(fmt.Sprintf("op.Continue in iofog-controller reconcile for ControlPlane %s", r.cp.Name))
 
 	return op.Continue()
@@ -391,34 +429,46 @@ func (r *ControlPlaneReconciler) getIofogClient(scheme string, host string, port
 		Timeout: 10,
 	})
 
-	if _, err = iofogClient.GetStatus(); err != nil {
+	start := time.Now()
+	status, err := iofogClient.GetStatus()
+	observeControllerAPI("GetStatus", start, err)
+
+	if err != nil {
 		r.log.Info(fmt.Sprintf("Could not get Controller status for ControlPlane %s: %s", r.cp.Name, err.Error()))
 
 		return nil, op.ReconcileWithRequeue(time.Second * 3) //nolint:gomnd
 	}
 
+	if status != nil {
+		r.setControllerVersion(status.Versions.Controller)
+	}
+
 	return iofogClient, op.Continue()
 }
 
-func (r *ControlPlaneReconciler) ImportCertificates(iofogClient *iofogclient.Client) op.Reconciliation {
+func (r *ControlPlaneReconciler) ImportCertificates(ctx context.Context, iofogClient *iofogclient.Client) op.Reconciliation {
 	r.log.Info(fmt.Sprintf("Importing certificates for ControlPlane %s", r.cp.Name))
-	if err := r.ImportRouterCACertificate(iofogClient, "router-site-ca"); err != nil {
+	if err := r.importCACertificate(ctx, iofogClient, "router-site-ca"); err != nil {
 		r.log.Info(fmt.Sprintf("Failed to import certificates for ControlPlane %s: %s", r.cp.Name, err.Error()))
+		r.recordWarning(eventReasonCAImportFailed, "Failed to import CA router-site-ca into the Controller: %s", err.Error())
 		return op.ReconcileWithRequeue(time.Second * 10)
 	}
 
-	if err := r.ImportRouterCACertificate(iofogClient, "default-router-local-ca"); err != nil {
+	if err := r.importCACertificate(ctx, iofogClient, "default-router-local-ca"); err != nil {
 		r.log.Info(fmt.Sprintf("Failed to import certificates for ControlPlane %s: %s", r.cp.Name, err.Error()))
+		r.recordWarning(eventReasonCAImportFailed, "Failed to import CA default-router-local-ca into the Controller: %s", err.Error())
 		return op.ReconcileWithRequeue(time.Second * 10)
 	}
 
 	if isNatsEnabled(r.cp) {
-		if err := r.ImportRouterCACertificate(iofogClient, nats.NatsSiteCASecret); err != nil {
+		if err := r.importCACertificate(ctx, iofogClient, nats.NatsSiteCASecret); err != nil {
 			r.log.Info(fmt.Sprintf("Failed to import NATS site CA for ControlPlane %s: %s", r.cp.Name, err.Error()))
+			r.recordWarning(eventReasonCAImportFailed, "Failed to import CA %s into the Controller: %s", nats.NatsSiteCASecret, err.Error())
 			return op.ReconcileWithRequeue(time.Second * 10)
 		}
-		if err := r.ImportRouterCACertificate(iofogClient, nats.NatsLocalCASecret); err != nil {
+		if err := r.importCACertificate(ctx, iofogClient, nats.NatsLocalCASecret); err != nil {
 			r.log.Info(fmt.Sprintf("Failed to import NATS local CA for ControlPlane %s: %s", r.cp.Name, err.Error()))
+			r.recordWarning(eventReasonCAImportFailed, "Failed to import CA %s into the Controller: %s", nats.NatsLocalCASecret, err.Error())
 			return op.ReconcileWithRequeue(time.Second * 10)
 		}
 	}
@@ -426,9 +476,9 @@ func (r *ControlPlaneReconciler) ImportCertificates(iofogClient *iofogclient.Cli
 	return op.Continue()
 }
 
-// getControllerClientForNats returns an iofog client for the ControlPlane's controller (in-cluster DNS).
-// Used to call GET /api/v3/nats/bootstrap. Requeues if the controller is not reachable yet.
-func (r *ControlPlaneReconciler) getControllerClientForNats() (*iofogclient.Client, op.Reconciliation) {
+// getControllerServiceClient returns an iofog client for the ControlPlane's controller (in-cluster DNS).
+// Used to call GET /api/v3/nats/bootstrap and by the health checks. Requeues if the controller is not reachable yet.
+func (r *ControlPlaneReconciler) getControllerServiceClient() (*iofogclient.Client, op.Reconciliation) {
 	scheme := "http"
 	if r.cp.Spec.Controller.Https != nil && *r.cp.Spec.Controller.Https {
 		scheme = "https"
@@ -446,12 +496,59 @@ func isNatsEnabled(cp cpv3.ControlPlane) bool {
 	return true
 }
 
+// getNatsResources returns the resources of the NATS container.
+// NATS keeps the JetStream memory store in the container, so the default memory limit grows with it.
+func getNatsResources(cp cpv3.ControlPlane) corev1.ResourceRequirements {
+	res := cp.Spec.Resources.Nats
+	if len(res.Requests) != 0 || len(res.Limits) != 0 {
+		return res
+	}
+
+	memoryStoreSize := cpv3.DefaultNatsMemoryStoreSize
+	if cp.Spec.Nats != nil && cp.Spec.Nats.JetStream.MemoryStoreSize != "" {
+		memoryStoreSize = cp.Spec.Nats.JetStream.MemoryStoreSize
+	}
+
+	memoryLimit := resource.MustParse(cpv3.DefaultNatsMemoryHeadroom)
+	if memoryStore, err := resource.ParseQuantity(memoryStoreSize); err == nil {
+		memoryLimit.Add(memoryStore)
+	}
+
+	return corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse(cpv3.DefaultNatsCPURequest),
+			corev1.ResourceMemory: resource.MustParse(cpv3.DefaultNatsMemoryRequest),
+		},
+		Limits: corev1.ResourceList{
+			corev1.ResourceMemory: memoryLimit,
+		},
+	}
+}
+
+// getAvailableRouters returns the routers of the ControlPlane that have an available replica
+func (r *ControlPlaneReconciler) getAvailableRouters(ctx context.Context) ([]string, error) {
+	available := []string{}
+
+	for _, name := range getRouterNames(r.cp) {
+		problem, err := r.checkWorkloadAvailable(ctx, &appsv1.Deployment{}, name)
+		if err != nil {
+			return nil, err
+		}
+
+		if problem == "" {
+			available = append(available, name)
+		}
+	}
+
+	return available, nil
+}
+
 func (r *ControlPlaneReconciler) reconcileRouter(ctx context.Context) op.Reconciliation {
 	// Check if HA is enabled (default to false if not specified)
 	haEnabled := false
-	// if r.cp.Spec.Router.HA != nil {
-	// 	haEnabled = *r.cp.Spec.Router.HA
-	// }
+	if r.cp.Spec.Router.HA != nil {
+		haEnabled = *r.cp.Spec.Router.HA
+	}
 
 	routerMicroservices := newRouterMicroservices(routerMicroserviceConfig{
 		image:                 r.cp.Spec.Images.Router,
@@ -460,22 +557,27 @@ func (r *ControlPlaneReconciler) reconcileRouter(ctx context.Context) op.Reconci
 		serviceAnnotations:    r.cp.Spec.Services.Router.Annotations,
 		externalTrafficPolicy: r.cp.Spec.Services.Router.ExternalTrafficPolicy,
 		ha:                    haEnabled,
+		ports:                 r.cp.Spec.Router.Ports,
+		resources:             r.cp.Spec.Resources.Router,
+		scheduling:            r.cp.Spec.Scheduling.Router,
 	})
 
 	// Use the primary router for service creation and IP resolution
 	ms := routerMicroservices[0]
 
-	// Service Account, Role, and Role Binding (only for primary router)
-	if err := r.createServiceAccount(ctx, ms); err != nil {
-		return op.ReconcileWithError(err)
-	}
+	// Service Account, Role, and Role Binding
+	for _, routerMS := range routerMicroservices {
+		if err := r.createServiceAccount(ctx, routerMS); err != nil {
+			return op.ReconcileWithError(err)
+		}
 
-	if err := r.createRole(ctx, ms); err != nil {
-		return op.ReconcileWithError(err)
-	}
+		if err := r.createRole(ctx, routerMS); err != nil {
+			return op.ReconcileWithError(err)
+		}
 
-	if err := r.createRoleBinding(ctx, ms); err != nil {
-		return op.ReconcileWithError(err)
+		if err := r.createRoleBinding(ctx, routerMS); err != nil {
+			return op.ReconcileWithError(err)
+		}
 	}
 
 	// Service
@@ -483,6 +585,11 @@ func (r *ControlPlaneReconciler) reconcileRouter(ctx context.Context) op.Reconci
 		return op.ReconcileWithError(err)
 	}
 
+	// Gateway routes
+	if err := r.reconcileRouterRoutes(ctx); err != nil {
+		return op.ReconcileWithError(err)
+	}
+
 	// Wait for IP
 	k8sClient, err := newK8sClient()
 	if err != nil {
@@ -495,9 +602,16 @@ func (r *ControlPlaneReconciler) reconcileRouter(ctx context.Context) op.Reconci
 
 	var address string
 
-	if strings.EqualFold(r.cp.Spec.Services.Router.Type, string(corev1.ServiceTypeLoadBalancer)) {
+	if isRouterGatewayEnabled(r.cp) {
+		routerIngress, err := r.getRouterGatewayIngress(ctx)
+		if err != nil {
+			return op.ReconcileWithError(err)
+		}
+
+		address = routerIngress.Address
+	} else if strings.EqualFold(r.cp.Spec.Services.Router.Type, string(corev1.ServiceTypeLoadBalancer)) {
 		//nolint:contextcheck // k8sClient unfortunately does not accept context
-		address, err = k8sClient.WaitForLoadBalancer(r.cp.ObjectMeta.Namespace, ms.name, loadBalancerTimeout)
+		address, err = r.waitForLoadBalancer(k8sClient, r.cp.ObjectMeta.Namespace, ms.name, r.getEndpoint(getRouterEndpoint))
 		if err != nil {
 			return op.ReconcileWithError(err)
 		}
@@ -510,10 +624,35 @@ func (r *ControlPlaneReconciler) reconcileRouter(ctx context.Context) op.Reconci
 	}
 
 	r.log.Info(fmt.Sprintf("Found address %s for router reconcile for Controlplane %s", address, r.cp.Name))
+	r.setEndpoint(func(endpoints *cpv3.EndpointsStatus) { endpoints.Router = address })
 
-	if err := r.createRouterSecrets(r.cp.ObjectMeta.Namespace, ms, address); err != nil {
-		return op.ReconcileWithError(err)
+	if isCertManagerEnabled(r.cp) {
+		wait, err := r.createRouterCertificates(ctx, ms, address)
+		if err != nil {
+			return op.ReconcileWithError(err)
+		}
+
+		if wait > 0 {
+			r.log.Info(fmt.Sprintf("Waiting for router certificates to be issued for Controlplane %s", r.cp.Name))
+
+			return op.ReconcileWithRequeue(wait)
+		}
+	} else {
+		if err := r.validateProvidedCAs(ctx, routerCASecrets); err != nil {
+			return op.ReconcileWithError(err)
+		}
+
+		if err := r.createRouterSecrets(r.cp.ObjectMeta.Namespace, ms, address, haEnabled); err != nil {
+			return op.ReconcileWithError(err)
+		}
+	}
+
+	// Routers only read their certificates on startup, changed certificates roll them
+	certsHash := getRouterCertsHash(ms.secrets)
+	for _, routerMS := range routerMicroservices {
+		routerMS.podTemplateAnnotations = map[string]string{routerCertsHashAnnotation: certsHash}
 	}
+
 	// Create secrets
 	r.log.Info(fmt.Sprintf("Creating secrets for router reconcile for Controlplane %s", r.cp.Name))
 
@@ -523,12 +662,30 @@ func (r *ControlPlaneReconciler) reconcileRouter(ctx context.Context) op.Reconci
 		return op.ReconcileWithError(err)
 	}
 
-	// Router ConfigMap
+	// Router ConfigMaps, every router but the primary links to the primary
 	r.log.Info(fmt.Sprintf("Creating configmap for router reconcile for Controlplane %s", r.cp.Name))
 
-	if err := r.createConfigMap(ctx); err != nil {
-		r.log.Info(fmt.Sprintf("Failed to create configmap %v for router reconcile for Controlplane %s", err, r.cp.Name))
-		return op.ReconcileWithError(err)
+	for _, routerMS := range routerMicroservices {
+		routerConfig := router.Config{
+			Namespace:    r.cp.Namespace,
+			ID:           getRouterID(routerMS.name),
+			MessagePort:  r.cp.Spec.Router.Ports.MessagePort,
+			HTTPPort:     r.cp.Spec.Router.Ports.HTTPPort,
+			InteriorPort: r.cp.Spec.Router.Ports.InteriorPort,
+			EdgePort:     r.cp.Spec.Router.Ports.EdgePort,
+		}
+		if routerMS.name != routerName {
+			routerConfig.InterRouterHosts = []string{getServiceHost(routerInteriorServiceName, r.cp.Namespace)}
+		}
+
+		config, err := r.createConfigMap(ctx, routerMS, routerConfig)
+		if err != nil {
+			r.log.Info(fmt.Sprintf("Failed to create configmap %v for router reconcile for Controlplane %s", err, r.cp.Name))
+			return op.ReconcileWithError(err)
+		}
+
+		// skrouterd.json is only read on startup as well
+		routerMS.podTemplateAnnotations[routerConfigHashAnnotation] = getHash(config)
 	}
 
 	// Deployments
@@ -541,6 +698,17 @@ func (r *ControlPlaneReconciler) reconcileRouter(ctx context.Context) op.Reconci
 		}
 	}
 
+	if !haEnabled {
+		if err := r.deleteRouterMicroservice(ctx, routerSecondaryName); err != nil {
+			return op.ReconcileWithError(err)
+		}
+	}
+
+	// Every router Deployment runs a single replica, the budget spans all of them
+	if err := r.reconcilePodDisruptionBudget(ctx, routerName, int32(len(routerMicroservices)), r.cp.Spec.PodDisruptionBudgets.Router); err != nil {
+		return op.ReconcileWithError(err)
+	}
+
 	r.log.Info(fmt.Sprintf("op.Continue for router reconcile for Controlplane %s", r.cp.Name))
 
 	return op.Continue()
@@ -548,50 +716,52 @@ func (r *ControlPlaneReconciler) reconcileRouter(ctx context.Context) op.Reconci
 
 func (r *ControlPlaneReconciler) reconcileNats(ctx context.Context) op.Reconciliation {
 	if !isNatsEnabled(r.cp) {
+		if err := r.deletePodDisruptionBudget(ctx, "nats"); err != nil {
+			return op.ReconcileWithError(err)
+		}
+
+		if err := r.deleteGatewayL4Routes(ctx, natsLeafRouteName); err != nil {
+			return op.ReconcileWithError(err)
+		}
+
+		if err := r.deleteGatewayL4Routes(ctx, natsMqttRouteName); err != nil {
+			return op.ReconcileWithError(err)
+		}
+
 		return op.Continue()
 	}
 	namespace := r.cp.Namespace
 	instanceName := r.cp.Name
 	natsLabels := getStandardLabels("nats", instanceName)
 	replicas := r.cp.Spec.Replicas.Nats
-	if replicas < 2 {
-		replicas = 2
+	if replicas < cpv3.DefaultNatsReplicas {
+		replicas = cpv3.DefaultNatsReplicas
 	}
 
 	// Bootstrap from Controller API (GET /api/v3/nats/bootstrap). Controller performs bootstrap; operator only saves secrets (creds come base64 in response).
-	iofogClient, recon := r.getControllerClientForNats()
+	iofogClient, recon := r.getControllerServiceClient()
 	if recon.IsFinal() {
 		return recon
 	}
 	if err := r.loginIofogClient(iofogClient); err != nil {
 		if !strings.Contains(strings.ToLower(err.Error()), "invalid credentials") {
 			r.log.Info(fmt.Sprintf("Could not login for NATS bootstrap ControlPlane %s: %s", r.cp.Name, err.Error()))
+			r.recordWarning(eventReasonLoginFailed, "Could not login to the Controller for NATS bootstrap: %s", err.Error())
 			return op.ReconcileWithError(err)
 		}
 	}
+	start := time.Now()
 	bootstrapResp, err := iofogClient.GetNatsBootstrap()
+	observeControllerAPI("GetNatsBootstrap", start, err)
+
 	if err != nil {
+		natsBootstrapFailures.WithLabelValues(r.cp.Namespace, r.cp.Name).Inc()
 		r.log.Error(err, "NATS bootstrap API failed")
 		return op.ReconcileWithError(fmt.Errorf("get NATS bootstrap from Controller: %w", err))
 	}
 	createOrUpdateSecret := func(ctx context.Context, s *corev1.Secret) error {
-		if err := controllerutil.SetControllerReference(&r.cp, s, r.Scheme); err != nil {
-			return err
-		}
-		err := r.Client.Create(ctx, s)
-		if err == nil {
-			return nil
-		}
-		if !k8serrors.IsAlreadyExists(err) {
-			return err
-		}
-		existing := &corev1.Secret{}
-		if err := r.Client.Get(ctx, types.NamespacedName{Name: s.Name, Namespace: s.Namespace}, existing); err != nil {
-			return err
-		}
-		existing.Data = s.Data
-		existing.Labels = mergeLabels(natsLabels, existing.Labels)
-		return r.Client.Update(ctx, existing)
+		_, err := r.applyObject(ctx, s)
+		return err
 	}
 	bootstrap, err := nats.EnsureNatsBootstrapFromController(ctx, createOrUpdateSecret, namespace, natsLabels, &nats.BootstrapFromAPI{
 		OperatorJwt:            bootstrapResp.OperatorJwt,
@@ -602,6 +772,7 @@ func (r *ControlPlaneReconciler) reconcileNats(ctx context.Context) op.Reconcili
 		SysUserCredsBase64:     bootstrapResp.SysUserCredsBase64,
 	})
 	if err != nil {
+		natsBootstrapFailures.WithLabelValues(r.cp.Namespace, r.cp.Name).Inc()
 		r.log.Error(err, "NATS bootstrap save secrets failed")
 		return op.ReconcileWithError(err)
 	}
@@ -612,10 +783,7 @@ func (r *ControlPlaneReconciler) reconcileNats(ctx context.Context) op.Reconcili
 			return r.Client.Get(ctx, nn, s)
 		},
 		func(ctx context.Context, s *corev1.Secret) error {
-			if err := controllerutil.SetControllerReference(&r.cp, s, r.Scheme); err != nil {
-				return err
-			}
-			return r.Client.Create(ctx, s)
+			return r.createObject(ctx, s)
 		},
 		namespace, instanceName, natsLabels)
 	if err != nil {
@@ -643,7 +811,9 @@ func (r *ControlPlaneReconciler) reconcileNats(ctx context.Context) op.Reconcili
 	if r.cp.Spec.Services.NatsServer.Type != "" {
 		natsServerSvcType = corev1.ServiceType(r.cp.Spec.Services.NatsServer.Type)
 	}
+	natsPorts := getNatsPorts(r.cp.Spec.Nats)
 	natsMs := newNatsMicroservice(natsMicroserviceConfig{
+		instanceName:                instanceName,
 		image:                       openidutil.GetNatsImage(),
 		imagePullSecret:             r.cp.Spec.Images.PullSecret,
 		replicas:                    replicas,
@@ -656,6 +826,9 @@ func (r *ControlPlaneReconciler) reconcileNats(ctx context.Context) op.Reconcili
 		serverServiceType:           string(natsServerSvcType),
 		serverServiceAnnotations:    r.cp.Spec.Services.NatsServer.Annotations,
 		serverExternalTrafficPolicy: r.cp.Spec.Services.NatsServer.ExternalTrafficPolicy,
+		ports:                       natsPorts,
+		resources:                   getNatsResources(r.cp),
+		scheduling:                  r.cp.Spec.Scheduling.Nats,
 	})
 	if r.cp.Spec.Images.Nats != "" {
 		natsMs.containers[0].image = r.cp.Spec.Images.Nats
@@ -677,16 +850,26 @@ func (r *ControlPlaneReconciler) reconcileNats(ctx context.Context) op.Reconcili
 	if err := r.createService(ctx, natsMs); err != nil {
 		return op.ReconcileWithError(err)
 	}
+	if err := r.reconcileNatsRoutes(ctx, natsPorts); err != nil {
+		return op.ReconcileWithError(err)
+	}
 
-	// Resolve NATS address (LB or ingress) for TLS cert SANs and hub registration, same pattern as router
+	// Resolve NATS address (Gateway, LB or ingress) for TLS cert SANs and hub registration, same pattern as router
 	var natsAddress string
-	if strings.EqualFold(r.cp.Spec.Services.Nats.Type, string(corev1.ServiceTypeLoadBalancer)) {
+	var natsGatewayIngress cpv3.NatsIngress
+	if isNatsGatewayEnabled(r.cp) {
+		natsGatewayIngress, err = r.getNatsGatewayIngress(ctx)
+		if err != nil {
+			return op.ReconcileWithError(err)
+		}
+		natsAddress = natsGatewayIngress.Address
+	} else if strings.EqualFold(r.cp.Spec.Services.Nats.Type, string(corev1.ServiceTypeLoadBalancer)) {
 		k8sClient, k8sErr := newK8sClient()
 		if k8sErr != nil {
 			return op.ReconcileWithError(k8sErr)
 		}
 		//nolint:contextcheck // k8sClient does not accept context
-		natsAddress, err = k8sClient.WaitForLoadBalancer(namespace, nats.ClientServiceName, loadBalancerTimeout)
+		natsAddress, err = r.waitForLoadBalancer(k8sClient, namespace, nats.ClientServiceName, r.getEndpoint(getNatsEndpoint))
 		if err != nil {
 			return op.ReconcileWithError(err)
 		}
@@ -694,37 +877,30 @@ func (r *ControlPlaneReconciler) reconcileNats(ctx context.Context) op.Reconcili
 		natsAddress = r.cp.Spec.Ingresses.Nats.Address
 	}
 
-	// When replica count changes, nats-site-server (and nats-mqtt-server) cert SANs are stale;
-	// delete them so EnsureNatsSecrets recreates them with hosts for all current replicas.
-	desiredReplicasStr := fmt.Sprintf("%d", replicas)
-	existingSiteServer := &corev1.Secret{}
-	err = r.Client.Get(ctx, types.NamespacedName{Name: nats.NatsSiteServerSecret, Namespace: namespace}, existingSiteServer)
-	if err == nil {
-		annotatedReplicas := existingSiteServer.Annotations["datasance.com/nats-replicas"]
-		if annotatedReplicas != desiredReplicasStr {
-			for _, name := range []string{nats.NatsSiteServerSecret, nats.NatsMqttServerSecret} {
-				delErr := r.Client.Delete(ctx, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}})
-				if delErr != nil && !k8serrors.IsNotFound(delErr) {
-					return op.ReconcileWithError(delErr)
-				}
-			}
+	r.setEndpoint(func(endpoints *cpv3.EndpointsStatus) { endpoints.Nats = natsAddress })
+
+	if isCertManagerEnabled(r.cp) {
+		// cert-manager reissues the certificates itself when the SANs change with the replica count
+		hosts := nats.SiteServerHosts(nats.HeadlessServiceName, namespace, int(replicas), natsAddress)
+		_, wait, err := r.createIssuedSecrets(ctx, natsLabels, []issuedCertificate{
+			{secretName: nats.NatsSiteServerSecret, caSecretName: nats.NatsSiteCASecret, commonName: "iofog-nats", hosts: hosts},
+			{secretName: nats.NatsMqttServerSecret, caSecretName: nats.NatsLocalCASecret, commonName: "iofog-nats-mqtt", hosts: hosts},
+		})
+		if err != nil {
+			return op.ReconcileWithError(err)
 		}
-	}
 
-	// NATS TLS secrets (with external address in SANs like createRouterSecrets)
-	tlsSecrets, err := nats.EnsureNatsSecrets(ctx,
-		func(ctx context.Context, nn types.NamespacedName, s *corev1.Secret) error {
-			return r.Client.Get(ctx, nn, s)
-		},
-		namespace, instanceName, nats.HeadlessServiceName, int(replicas), natsAddress, natsLabels)
-	if err != nil {
-		return op.ReconcileWithError(err)
-	}
-	for i := range tlsSecrets {
-		if err := controllerutil.SetControllerReference(&r.cp, &tlsSecrets[i], r.Scheme); err != nil {
+		if wait > 0 {
+			r.log.Info(fmt.Sprintf("Waiting for NATS certificates to be issued for Controlplane %s", r.cp.Name))
+
+			return op.ReconcileWithRequeue(wait)
+		}
+	} else {
+		if err := r.validateProvidedCAs(ctx, natsCASecrets); err != nil {
 			return op.ReconcileWithError(err)
 		}
-		if err := r.Client.Create(ctx, &tlsSecrets[i]); err != nil && !k8serrors.IsAlreadyExists(err) {
+
+		if err := r.createNatsSecrets(ctx, int(replicas), natsAddress, natsLabels); err != nil {
 			return op.ReconcileWithError(err)
 		}
 	}
@@ -746,9 +922,11 @@ func (r *ControlPlaneReconciler) reconcileNats(ctx context.Context) op.Reconcili
 		}
 	}
 
-	// Leaf advertise: same host as natsIngress (createDefaultNatsHub), port = ingress leaf port or 7422
-	leafPort := nats.DefaultLeafPort
-	if r.cp.Spec.Ingresses.Nats.LeafPort > 0 {
+	// Leaf advertise: same host as natsIngress (createDefaultNatsHub), port = ingress leaf port or the leaf listener port
+	leafPort := natsPorts.LeafPort
+	if isNatsGatewayEnabled(r.cp) {
+		leafPort = natsGatewayIngress.LeafPort
+	} else if !strings.EqualFold(string(natsSvcType), string(corev1.ServiceTypeLoadBalancer)) && r.cp.Spec.Ingresses.Nats.LeafPort > 0 {
 		leafPort = r.cp.Spec.Ingresses.Nats.LeafPort
 	}
 	leafAdvertise := ""
@@ -758,53 +936,35 @@ func (r *ControlPlaneReconciler) reconcileNats(ctx context.Context) op.Reconcili
 
 	// JETSTREAM_DOMAIN = controlplane namespace (Controller: CONTROLLER_NAMESPACE / app.namespace)
 	serverConf := nats.BuildServerConf(nats.ServerConfParams{
-		ServerPort:      nats.DefaultServerPort,
-		HttpPort:        nats.DefaultHttpPort,
+		ServerPort:      natsPorts.ServerPort,
+		HttpPort:        natsPorts.HttpPort,
 		OperatorJWT:     bootstrap.OperatorJWT,
 		SystemAccount:   bootstrap.SystemAccountPubKey,
 		JetStreamDomain: namespace,
 		JetStreamKey:    jetStreamKey,
 		JetStreamPrev:   jetStreamPrev,
-		ClusterRoutes:   nats.ClusterRoutesMerge(nats.HeadlessServiceName, int(replicas), nats.DefaultClusterPort, existingServerConf),
+		ClusterRoutes:   nats.ClusterRoutesMerge(nats.HeadlessServiceName, int(replicas), natsPorts.ClusterPort, existingServerConf),
 		SSLDir:          "/etc/nats/certs",
 		CertName:        nats.NatsSiteServerSecret,
 		MqttCertName:    nats.NatsMqttServerSecret,
-		LeafPort:        nats.DefaultLeafPort,
+		LeafPort:        natsPorts.LeafPort,
 		LeafAdvertise:   leafAdvertise,
-		ClusterPort:     nats.DefaultClusterPort,
-		MqttPort:        nats.DefaultMqttPort,
+		ClusterPort:     natsPorts.ClusterPort,
+		MqttPort:        natsPorts.MqttPort,
 		JWTDir:          "/home/runner/nats/jwt",
 		ControllerName:  instanceName,
 		MaxMemoryStore:  memoryStoreSizeNats,
 		MaxFileStore:    storageSizeNats,
 	})
 
+	// Always apply the ConfigMap so replica count / routes stay in sync when CR is updated.
 	configMap := nats.NewNatsConfigMap(namespace, instanceName, natsLabels, serverConf)
-	if err := controllerutil.SetControllerReference(&r.cp, configMap, r.Scheme); err != nil {
-		return op.ReconcileWithError(err)
-	}
-	// Always create or update ConfigMap so replica count / routes stay in sync when CR is updated.
-	existingCM := &corev1.ConfigMap{}
-	err = r.Client.Get(ctx, types.NamespacedName{Name: configMap.Name, Namespace: configMap.Namespace}, existingCM)
-	if err != nil && k8serrors.IsNotFound(err) {
-		if err = r.Client.Create(ctx, configMap); err != nil {
-			return op.ReconcileWithError(err)
-		}
-	} else if err != nil {
+	if _, err := r.applyObject(ctx, configMap); err != nil {
 		return op.ReconcileWithError(err)
-	} else {
-		existingCM.Data = configMap.Data
-		existingCM.Labels = mergeLabels(natsLabels, existingCM.Labels)
-		if err = r.Client.Update(ctx, existingCM); err != nil {
-			return op.ReconcileWithError(err)
-		}
 	}
 
 	jwtBundle := nats.NewJWTBundleConfigMap(namespace, natsLabels, map[string]string{bootstrap.SystemAccountPubKey: bootstrap.SystemAccountJWT})
-	if err := controllerutil.SetControllerReference(&r.cp, jwtBundle, r.Scheme); err != nil {
-		return op.ReconcileWithError(err)
-	}
-	if err := r.Client.Create(ctx, jwtBundle); err != nil && !k8serrors.IsAlreadyExists(err) {
+	if err := r.createObject(ctx, jwtBundle); err != nil {
 		return op.ReconcileWithError(err)
 	}
 
@@ -825,7 +985,7 @@ func (r *ControlPlaneReconciler) reconcileNats(ctx context.Context) op.Reconcili
 					ann[k] = v
 				}
 			}
-			ann["kubectl.kubernetes.io/restartedAt"] = time.Now().UTC().Format(time.RFC3339)
+			ann[restartedAtAnnotation] = time.Now().UTC().Format(time.RFC3339)
 			natsMs.podTemplateAnnotations = ann
 		} else {
 			// Preserve existing annotations so we don't remove restartedAt and trigger another rollout.
@@ -833,27 +993,152 @@ func (r *ControlPlaneReconciler) reconcileNats(ctx context.Context) op.Reconcili
 		}
 	}
 
+	// NATS servers only read their certificates on startup, reissued certificates roll them
+	natsCertsHash, err := r.getSecretsCertsHash(ctx, natsServerSecrets)
+	if err != nil {
+		return op.ReconcileWithError(err)
+	}
+	natsAnnotations := map[string]string{natsCertsHashAnnotation: natsCertsHash}
+	for k, v := range natsMs.podTemplateAnnotations {
+		if k != natsCertsHashAnnotation {
+			natsAnnotations[k] = v
+		}
+	}
+	natsMs.podTemplateAnnotations = natsAnnotations
+
 	// Create StatefulSet via shared microservice flow (same as Deployment for controller/router but with isStatefulSet flag)
 	if err := r.createStatefulSet(ctx, natsMs); err != nil {
 		return op.ReconcileWithError(err)
 	}
 
+	if err := r.reconcilePodDisruptionBudget(ctx, natsMs.name, replicas, r.cp.Spec.PodDisruptionBudgets.Nats); err != nil {
+		return op.ReconcileWithError(err)
+	}
+
 	return op.Continue()
 }
 
+// getNatsPorts returns the NATS listener ports of the spec, unset ports keep their defaults
+func getNatsPorts(spec *cpv3.Nats) nats.Ports {
+	ports := nats.DefaultPorts()
+	if spec == nil {
+		return ports
+	}
+
+	if spec.Ports.ServerPort != 0 {
+		ports.ServerPort = spec.Ports.ServerPort
+	}
+
+	if spec.Ports.ClusterPort != 0 {
+		ports.ClusterPort = spec.Ports.ClusterPort
+	}
+
+	if spec.Ports.LeafPort != 0 {
+		ports.LeafPort = spec.Ports.LeafPort
+	}
+
+	if spec.Ports.MqttPort != 0 {
+		ports.MqttPort = spec.Ports.MqttPort
+	}
+
+	if spec.Ports.HttpPort != 0 {
+		ports.HttpPort = spec.Ports.HttpPort
+	}
+
+	return ports
+}
+
+// reconcilePodDisruptionBudget keeps the budget of a component in line with its replicas and the spec overrides.
+// Without overrides, components with more than one replica get a budget and the others have theirs removed.
+func (r *ControlPlaneReconciler) reconcilePodDisruptionBudget(ctx context.Context, component string, replicas int32, override cpv3.PodDisruptionBudget) error {
+	enabled := replicas > 1
+	if override.Enabled != nil {
+		enabled = *override.Enabled
+	}
+
+	if !enabled {
+		return r.deletePodDisruptionBudget(ctx, component)
+	}
+
+	minAvailable, maxUnavailable := override.MinAvailable, override.MaxUnavailable
+	if minAvailable == nil && maxUnavailable == nil {
+		computed := intstr.FromInt32(getMaxUnavailable(replicas))
+		maxUnavailable = &computed
+	}
+
+	return r.createPodDisruptionBudget(ctx, newPodDisruptionBudget(r.cp.Namespace, r.cp.Name, component, minAvailable, maxUnavailable))
+}
+
+// getMaxUnavailable returns how many replicas can be evicted while a majority stays available.
+// At least one eviction is always allowed so that node drains are never blocked.
+func getMaxUnavailable(replicas int32) int32 {
+	if maxUnavailable := (replicas - 1) / 2; maxUnavailable > 1 { //nolint:gomnd
+		return maxUnavailable
+	}
+
+	return 1
+}
+
+// createNatsSecrets creates the NATS TLS secrets with the external address in the SANs like createRouterSecrets.
+// When the replica count or the address changes the server certificate SANs are stale, they are deleted so EnsureNatsSecrets
+// recreates them with hosts for all current replicas. Server certificates due for renewal or issued by a rotated CA
+// are recreated the same way.
+func (r *ControlPlaneReconciler) createNatsSecrets(ctx context.Context, replicas int, address string, labels map[string]string) error {
+	namespace := r.cp.Namespace
+
+	renewBefore := getRenewBefore(r.cp)
+	hosts := nats.SiteServerHosts(nats.HeadlessServiceName, namespace, replicas, address)
+
+	for _, name := range natsServerSecrets {
+		existing := &corev1.Secret{}
+		if err := r.Client.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, existing); err != nil {
+			continue
+		}
+
+		// Certificates of a rotated CA are reissued
+		issuedByCA := true
+		ca := &corev1.Secret{}
+		if err := r.Client.Get(ctx, types.NamespacedName{Name: getCASource(r.cp, serverCASecrets[name]), Namespace: namespace}, ca); err == nil {
+			issuedByCA = certificateIssuedBy(existing, ca)
+		}
+
+		if existing.Annotations["datasance.com/nats-replicas"] != strconv.Itoa(replicas) || serverHostsChanged(existing, hosts) ||
+			certificateExpiring(existing, renewBefore) || !issuedByCA {
+			r.log.Info(fmt.Sprintf("Reissuing NATS server certificate %s for Controlplane %s", name, r.cp.Name))
+
+			delErr := r.Client.Delete(ctx, existing)
+			if delErr != nil && !k8serrors.IsNotFound(delErr) {
+				return delErr
+			}
+		}
+	}
+
+	tlsSecrets, err := nats.EnsureNatsSecrets(ctx,
+		func(ctx context.Context, nn types.NamespacedName, s *corev1.Secret) error {
+			return r.Client.Get(ctx, nn, s)
+		},
+		namespace, r.cp.Name, nats.HeadlessServiceName, replicas, address, labels,
+		nats.CASecrets{Site: getCASource(r.cp, nats.NatsSiteCASecret), Local: getCASource(r.cp, nats.NatsLocalCASecret)})
+	if err != nil {
+		return err
+	}
+	for i := range tlsSecrets {
+		if err := r.createObject(ctx, &tlsSecrets[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // createRouterSecrets creates the secrets for the router.
 // It generates the CA and secrets for the router.
 // It also appends the secrets to the microservice.secrets slice.
-func (r *ControlPlaneReconciler) createRouterSecrets(namespace string, ms *microservice, address string) (err error) {
+// With HA the site server certificate also has to be valid for routerInteriorServiceName, it is regenerated when it is not.
+// Server certificates are also regenerated from the existing CAs when they are due for renewal or the address changed.
+func (r *ControlPlaneReconciler) createRouterSecrets(namespace string, ms *microservice, address string, ha bool) (err error) {
 	r.log.Info(fmt.Sprintf("Creating routerSecrets definition for router reconcile for Controlplane %s", r.cp.Name))
 
-	defer func() {
-		if recoverResult := recover(); recoverResult != nil {
-			r.log.Info(fmt.Sprintf("Recover result %v for creating secrets for router reconcile for Controlplane %s", recoverResult, r.cp.Name))
-			err = fmt.Errorf("createRouterSecrets failed: %v", recoverResult)
-		}
-	}()
-
 	const (
 		LocalClientSecret        string = "skupper-local-client"
 		LocalServerSecret        string = "router-local-server"
@@ -873,21 +1158,42 @@ func (r *ControlPlaneReconciler) createRouterSecrets(namespace string, ms *micro
 	existingLocalCA := &corev1.Secret{}
 	existingSiteServer := &corev1.Secret{}
 	existingLocalServer := &corev1.Secret{}
-	siteSecretAddress := fmt.Sprintf("%s.%s.svc.cluster.local,%s", ms.name, namespace, address)
-	localSecretAddress := fmt.Sprintf("%s.%s.svc.cluster.local,%s", ms.name, namespace, address)
+	interiorHost := getServiceHost(routerInteriorServiceName, namespace)
+	siteSecretAddress, localSecretAddress := getRouterServerHosts(ms.name, namespace, address)
 	siteSecretSubject := fmt.Sprintf("iofog-router")
 	localSecretSubject := fmt.Sprintf("iofog-router-local")
 
+	// Provided CAs sign the server certificates in place of the operator CAs
+	siteCASource := getCASource(r.cp, SiteCaSecret)
+	localCASource := getCASource(r.cp, LocalCaSecret)
+
 	// Try to get existing secrets
-	err = r.Client.Get(context.Background(), types.NamespacedName{Name: SiteCaSecret, Namespace: namespace}, existingSiteCA)
+	err = r.Client.Get(context.Background(), types.NamespacedName{Name: siteCASource, Namespace: namespace}, existingSiteCA)
 	siteCAExists := err == nil
-	err = r.Client.Get(context.Background(), types.NamespacedName{Name: LocalCaSecret, Namespace: namespace}, existingLocalCA)
+	err = r.Client.Get(context.Background(), types.NamespacedName{Name: localCASource, Namespace: namespace}, existingLocalCA)
 	localCAExists := err == nil
 	err = r.Client.Get(context.Background(), types.NamespacedName{Name: SiteServerSecret, Namespace: namespace}, existingSiteServer)
 	siteServerExists := err == nil
 	err = r.Client.Get(context.Background(), types.NamespacedName{Name: LocalServerSecret, Namespace: namespace}, existingLocalServer)
 	localServerExists := err == nil
 
+	// Site server certificates from before HA do not cover the inter-router link,
+	// certificates of a rotated or regenerated CA or for another address are reissued as well
+	renewBefore := getRenewBefore(r.cp)
+	siteServerStale := siteServerExists && ((ha && !certificateCoversHost(existingSiteServer, interiorHost)) ||
+		serverHostsChanged(existingSiteServer, siteSecretAddress) || certificateExpiring(existingSiteServer, renewBefore) ||
+		!siteCAExists || !certificateIssuedBy(existingSiteServer, existingSiteCA))
+	if siteServerStale {
+		siteServerExists = false
+	}
+
+	localServerStale := localServerExists && (serverHostsChanged(existingLocalServer, localSecretAddress) ||
+		certificateExpiring(existingLocalServer, renewBefore) ||
+		!localCAExists || !certificateIssuedBy(existingLocalServer, existingLocalCA))
+	if localServerStale {
+		localServerExists = false
+	}
+
 	// If all secrets exist, use them
 	if siteCAExists && localCAExists && siteServerExists && localServerExists {
 		r.log.Info(fmt.Sprintf("Using existing secrets for Controlplane %s", r.cp.Name))
@@ -900,11 +1206,18 @@ func (r *ControlPlaneReconciler) createRouterSecrets(namespace string, ms *micro
 
 	var siteCA, localCA corev1.Secret
 
-	// Generate CA certificates if they don't exist
+	// Generate CA certificates if they don't exist, provided CAs are validated before
 	routerLabels := getStandardLabels("router", r.cp.Name)
+	if !siteCAExists && siteCASource != SiteCaSecret || !localCAExists && localCASource != LocalCaSecret {
+		return fmt.Errorf("createRouterSecrets failed: provided CA %s or %s not found", siteCASource, localCASource)
+	}
+
 	if !siteCAExists {
 		r.log.Info(fmt.Sprintf("Generating site CA Secret for Controlplane %s", r.cp.Name))
-		siteCA = util.GenerateSecret(SiteCaSecret, SiteCaSecret, SiteCaSecret, 0, nil)
+		siteCA, err = util.IssueCA(SiteCaSecret, util.Options{})
+		if err != nil {
+			return fmt.Errorf("createRouterSecrets failed: %w", err)
+		}
 		siteCA.Namespace = namespace
 		siteCA.Labels = routerLabels
 		ms.secrets = append(ms.secrets, siteCA)
@@ -914,7 +1227,10 @@ func (r *ControlPlaneReconciler) createRouterSecrets(namespace string, ms *micro
 
 	if !localCAExists {
 		r.log.Info(fmt.Sprintf("Generating local CA Secret for Controlplane %s", r.cp.Name))
-		localCA = util.GenerateSecret(LocalCaSecret, LocalCaSecret, LocalCaSecret, 0, nil)
+		localCA, err = util.IssueCA(LocalCaSecret, util.Options{})
+		if err != nil {
+			return fmt.Errorf("createRouterSecrets failed: %w", err)
+		}
 		localCA.Namespace = namespace
 		localCA.Labels = routerLabels
 		ms.secrets = append(ms.secrets, localCA)
@@ -925,9 +1241,25 @@ func (r *ControlPlaneReconciler) createRouterSecrets(namespace string, ms *micro
 	// Generate server certificates if they don't exist
 	if !siteServerExists {
 		r.log.Info(fmt.Sprintf("Generating site server certificate for Controlplane %s with address %s", r.cp.Name, address))
-		siteSecret := util.GenerateSecret(SiteServerSecret, siteSecretSubject, siteSecretAddress, 0, &siteCA)
+		dnsNames, ipAddresses := util.ParseHosts(siteSecretAddress)
+		siteSecret, err := util.Issue(SiteServerSecret, util.Options{CommonName: siteSecretSubject, DNSNames: dnsNames, IPAddresses: ipAddresses}, &siteCA)
+		if err != nil {
+			return fmt.Errorf("createRouterSecrets failed: %w", err)
+		}
 		siteSecret.Namespace = namespace
 		siteSecret.Labels = routerLabels
+		siteSecret.Annotations = map[string]string{util.HostsAnnotation: siteSecretAddress}
+		siteSecret.Data["ca.crt"] = util.CABundle(&siteCA) // previous CAs stay trusted during a rotation
+
+		// createSecrets does not update existing Secrets
+		if siteServerStale {
+			r.log.Info(fmt.Sprintf("Replacing site server certificate for Controlplane %s", r.cp.Name))
+
+			if _, err := r.applyGeneratedObject(context.Background(), &siteSecret); err != nil {
+				return err
+			}
+		}
+
 		ms.secrets = append(ms.secrets, siteSecret)
 	} else {
 		ms.secrets = append(ms.secrets, *existingSiteServer)
@@ -935,9 +1267,24 @@ func (r *ControlPlaneReconciler) createRouterSecrets(namespace string, ms *micro
 
 	if !localServerExists {
 		r.log.Info(fmt.Sprintf("Generating local server certificate for Controlplane %s with address %s", r.cp.Name, address))
-		localSecret := util.GenerateSecret(LocalServerSecret, localSecretSubject, localSecretAddress, 0, &localCA)
+		dnsNames, ipAddresses := util.ParseHosts(localSecretAddress)
+		localSecret, err := util.Issue(LocalServerSecret, util.Options{CommonName: localSecretSubject, DNSNames: dnsNames, IPAddresses: ipAddresses}, &localCA)
+		if err != nil {
+			return fmt.Errorf("createRouterSecrets failed: %w", err)
+		}
 		localSecret.Namespace = namespace
 		localSecret.Labels = routerLabels
+		localSecret.Annotations = map[string]string{util.HostsAnnotation: localSecretAddress}
+		localSecret.Data["ca.crt"] = util.CABundle(&localCA)
+
+		if localServerStale {
+			r.log.Info(fmt.Sprintf("Replacing local server certificate for Controlplane %s", r.cp.Name))
+
+			if _, err := r.applyGeneratedObject(context.Background(), &localSecret); err != nil {
+				return err
+			}
+		}
+
 		ms.secrets = append(ms.secrets, localSecret)
 	} else {
 		ms.secrets = append(ms.secrets, *existingLocalServer)
@@ -948,6 +1295,109 @@ func (r *ControlPlaneReconciler) createRouterSecrets(namespace string, ms *micro
 	return nil
 }
 
+// createRouterCertificates has cert-manager issue the router server certificates with the SANs of createRouterSecrets.
+// The site server certificate always covers routerInteriorServiceName, so enabling HA does not reissue it.
+// It returns how long to wait while the certificates are not issued yet.
+func (r *ControlPlaneReconciler) createRouterCertificates(ctx context.Context, ms *microservice, address string) (time.Duration, error) {
+	siteHosts, localHosts := getRouterServerHosts(ms.name, r.cp.Namespace, address)
+
+	secrets, wait, err := r.createIssuedSecrets(ctx, getStandardLabels("router", r.cp.Name), []issuedCertificate{
+		{secretName: "router-site-server", caSecretName: "router-site-ca", commonName: "iofog-router", hosts: siteHosts},
+		{secretName: "router-local-server", caSecretName: "default-router-local-ca", commonName: "iofog-router-local", hosts: localHosts},
+	})
+	if err != nil || wait > 0 {
+		return wait, err
+	}
+
+	ms.secrets = append(ms.secrets, secrets...)
+
+	return 0, nil
+}
+
+// getRouterServerHosts returns the SANs of the router site and local server certificates
+func getRouterServerHosts(name, namespace, address string) (siteHosts, localHosts string) {
+	siteHosts = fmt.Sprintf("%s,%s,%s", getServiceHost(name, namespace), getServiceHost(routerInteriorServiceName, namespace), address)
+	localHosts = fmt.Sprintf("%s,%s", getServiceHost(name, namespace), address)
+
+	return siteHosts, localHosts
+}
+
+// certificateCoversHost reports whether the tls.crt of a Secret is valid for host
+func certificateCoversHost(secret *corev1.Secret, host string) bool {
+	cert, err := util.DecodeCertificate(secret.Data["tls.crt"])
+	if err != nil {
+		return false
+	}
+
+	return cert.VerifyHostname(host) == nil
+}
+
+// getRouterCertsHash identifies the router server certificates, it changes whenever one is regenerated
+func getRouterCertsHash(secrets []corev1.Secret) string {
+	hash := sha256.New()
+
+	for i := range secrets {
+		if secrets[i].Name == "router-site-server" || secrets[i].Name == "router-local-server" {
+			hash.Write(secrets[i].Data["tls.crt"])
+		}
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func getHash(value string) string {
+	hash := sha256.Sum256([]byte(value))
+
+	return hex.EncodeToString(hash[:])
+}
+
+func getServiceHost(name, namespace string) string {
+	return fmt.Sprintf("%s.%s.svc.cluster.local", name, namespace)
+}
+
+// waitForLoadBalancer waits for the address of a LoadBalancer Service and records the time spent waiting.
+// previous is the address recorded in the status, events are only recorded when the address changes.
+func (r *ControlPlaneReconciler) waitForLoadBalancer(k8sClient *k8sclient.Client, namespace, name, previous string) (string, error) {
+	if previous == "" {
+		r.recordEvent(eventReasonWaitingForLoadBalancer, "Waiting for the address of LoadBalancer Service %s", name)
+	}
+
+	start := time.Now()
+	address, err := k8sClient.WaitForLoadBalancer(namespace, name, loadBalancerTimeout)
+
+	loadBalancerWaitDuration.WithLabelValues(name).Observe(time.Since(start).Seconds())
+
+	if err != nil {
+		r.recordWarning(eventReasonLoadBalancerTimeout, "LoadBalancer Service %s has no address: %s", name, err.Error())
+
+		return "", err
+	}
+
+	if address != previous {
+		r.recordEvent(eventReasonLoadBalancerReady, "LoadBalancer Service %s has address %s", name, address)
+	}
+
+	return address, nil
+}
+
+func getRouterEndpoint(endpoints cpv3.EndpointsStatus) string {
+	return endpoints.Router
+}
+
+func getNatsEndpoint(endpoints cpv3.EndpointsStatus) string {
+	return endpoints.Nats
+}
+
+// getControllerEndpointHost returns the host of the Controller URL
+func getControllerEndpointHost(endpoints cpv3.EndpointsStatus) string {
+	u, err := url.Parse(endpoints.Controller)
+	if err != nil {
+		return ""
+	}
+
+	return u.Hostname()
+}
+
 func newK8sClient() (*k8sclient.Client, error) {
 	kubeConf := os.Getenv("KUBECONFIG")
 	if kubeConf == "" {
@@ -18,6 +18,7 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -78,6 +79,9 @@ func newServices(namespace, instanceName string, ms *microservice) (svcs []*core
 				Selector:       labels,
 			},
 		}
+		if msvcSvc.selector != nil {
+			svc.Spec.Selector = mergeLabels(msvcSvc.selector, labels)
+		}
 		if msvcSvc.trafficPolicy != "" {
 			svc.Spec.ExternalTrafficPolicy = corev1.ServiceExternalTrafficPolicyType(msvcSvc.trafficPolicy)
 		}
@@ -183,6 +187,7 @@ func newDeployment(namespace, instanceName string, ms *microservice) *appsv1.Dep
 	}
 
 	labels := mergeLabels(getStandardLabels(getComponentFromMicroservice(ms), instanceName), ms.labels)
+	selector := mergeLabels(ms.selectorLabels, labels)
 
 	dep := &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
@@ -194,17 +199,23 @@ func newDeployment(namespace, instanceName string, ms *microservice) *appsv1.Dep
 			MinReadySeconds: ms.availableDelay,
 			Replicas:        &ms.replicas,
 			Selector: &metav1.LabelSelector{
-				MatchLabels: labels,
+				MatchLabels: selector,
 			},
 			Strategy: strategy,
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
-					Labels: labels,
+					Labels:      selector,
+					Annotations: ms.podTemplateAnnotations,
 				},
 				Spec: corev1.PodSpec{
-					ServiceAccountName: ms.name,
-					Volumes:            ms.volumes,
-					SecurityContext:    ms.securityContext,
+					ServiceAccountName:        ms.name,
+					Volumes:                   ms.volumes,
+					SecurityContext:           ms.securityContext,
+					NodeSelector:              ms.scheduling.NodeSelector,
+					Tolerations:               ms.scheduling.Tolerations,
+					Affinity:                  ms.scheduling.Affinity,
+					TopologySpreadConstraints: ms.scheduling.TopologySpreadConstraints,
+					PriorityClassName:         ms.scheduling.PriorityClassName,
 				},
 			},
 		},
@@ -258,6 +269,7 @@ func newDeployment(namespace, instanceName string, ms *microservice) *appsv1.Dep
 // newStatefulSet builds a StatefulSet from a microservice (used when ms.isStatefulSet is true, e.g. NATS).
 func newStatefulSet(namespace, instanceName string, ms *microservice) *appsv1.StatefulSet {
 	labels := mergeLabels(getStandardLabels(getComponentFromMicroservice(ms), instanceName), ms.labels)
+	selector := mergeLabels(ms.selectorLabels, labels)
 
 	st := &appsv1.StatefulSet{
 		ObjectMeta: metav1.ObjectMeta{
@@ -269,13 +281,18 @@ func newStatefulSet(namespace, instanceName string, ms *microservice) *appsv1.St
 			ServiceName: ms.statefulSetServiceName,
 			Replicas:    &ms.replicas,
 			Selector: &metav1.LabelSelector{
-				MatchLabels: labels,
+				MatchLabels: selector,
 			},
 			Template: corev1.PodTemplateSpec{
-				ObjectMeta: metav1.ObjectMeta{Labels: labels, Annotations: ms.podTemplateAnnotations},
+				ObjectMeta: metav1.ObjectMeta{Labels: selector, Annotations: ms.podTemplateAnnotations},
 				Spec: corev1.PodSpec{
-					Volumes:         ms.volumes,
-					SecurityContext: ms.securityContext,
+					Volumes:                   ms.volumes,
+					SecurityContext:           ms.securityContext,
+					NodeSelector:              ms.scheduling.NodeSelector,
+					Tolerations:               ms.scheduling.Tolerations,
+					Affinity:                  ms.scheduling.Affinity,
+					TopologySpreadConstraints: ms.scheduling.TopologySpreadConstraints,
+					PriorityClassName:         ms.scheduling.PriorityClassName,
 				},
 			},
 			VolumeClaimTemplates: ms.volumeClaimTemplates,
@@ -327,6 +344,25 @@ func newStatefulSet(namespace, instanceName string, ms *microservice) *appsv1.St
 	return st
 }
 
+// newPodDisruptionBudget selects the pods of every Deployment or StatefulSet of a component.
+func newPodDisruptionBudget(namespace, instanceName, component string, minAvailable, maxUnavailable *intstr.IntOrString) *policyv1.PodDisruptionBudget {
+	labels := getStandardLabels(component, instanceName)
+	return &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      component,
+			Namespace: namespace,
+			Labels:    labels,
+		},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			MinAvailable:   minAvailable,
+			MaxUnavailable: maxUnavailable,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: labels,
+			},
+		},
+	}
+}
+
 func newServiceAccount(namespace, instanceName string, ms *microservice) *corev1.ServiceAccount {
 	labels := mergeLabels(getStandardLabels(getComponentFromMicroservice(ms), instanceName), ms.labels)
 	return &corev1.ServiceAccount{
@@ -372,16 +408,16 @@ func newRole(namespace, instanceName string, ms *microservice) *rbacv1.Role {
 	}
 }
 
-func newRouterConfigMap(namespace, instanceName string) *corev1.ConfigMap {
+func newRouterConfigMap(namespace, instanceName string, ms *microservice, cfg router.Config) *corev1.ConfigMap {
 	labels := getStandardLabels("router", instanceName)
 	return &corev1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "iofog-router",
+			Name:      getRouterConfigMapName(ms.name),
 			Namespace: namespace,
 			Labels:    labels,
 		},
 		Data: map[string]string{
-			"skrouterd.json": router.GetConfig(namespace),
+			"skrouterd.json": router.GetConfig(cfg),
 		},
 	}
 }
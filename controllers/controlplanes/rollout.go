@@ -0,0 +1,185 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	op "github.com/datasance/iofog-go-sdk/v3/pkg/k8s/operator"
+	cpv3 "github.com/datasance/iofog-operator/v3/apis/controlplanes/v3"
+	appsv1 "k8s.io/api/apps/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	cond "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// rolloutCheckDelay is how often the workloads are checked until their rollout completed
+	rolloutCheckDelay = 5 * time.Second
+	// failedRetryInterval is how often a Failed ControlPlane is reconciled again, it recovers once it is ready
+	failedRetryInterval = 5 * time.Minute
+
+	conditionReasonRolledOut                = "RolledOut"
+	conditionReasonProgressDeadlineExceeded = "ProgressDeadlineExceeded"
+
+	eventReasonFailed = "Failed"
+)
+
+func getProgressDeadline(cp cpv3.ControlPlane) time.Duration {
+	if cp.Spec.ProgressDeadline == nil {
+		return cpv3.DefaultProgressDeadline
+	}
+
+	return cp.Spec.ProgressDeadline.Duration
+}
+
+// startProgress records when deploying or updating the current generation started, the progress deadline counts from it
+func (r *ControlPlaneReconciler) startProgress() {
+	if !r.cp.ProgressingSince().IsZero() {
+		return
+	}
+
+	if r.cp.IsDeploying() {
+		r.cp.SetConditionDeploying(&r.log)
+	} else {
+		r.cp.SetConditionUpdating(&r.log)
+	}
+}
+
+// reconcileRollout holds the ControlPlane back from ready until the rollout of its workloads completed.
+// Once the progress deadline passed the ControlPlane is Failed, without errors it is only retried at the failed retry interval.
+func (r *ControlPlaneReconciler) reconcileRollout(ctx context.Context, recon op.Reconciliation) op.Reconciliation {
+	if recon.End && recon.Err == nil {
+		return recon
+	}
+
+	problem := ""
+
+	switch {
+	case recon.Err != nil:
+		problem = recon.Err.Error()
+	case recon.Requeue:
+		problem = "Waiting for the components to become ready"
+	default:
+		pending, err := r.getPendingRollouts(ctx)
+		if err != nil {
+			return op.ReconcileWithError(err)
+		}
+
+		if len(pending) == 0 {
+			r.cp.SetComponentCondition(cpv3.ConditionFailed, false, conditionReasonRolledOut, "")
+
+			return recon
+		}
+
+		problem = strings.Join(pending, "; ")
+		recon = op.ReconcileWithRequeue(rolloutCheckDelay)
+	}
+
+	deadline := getProgressDeadline(r.cp)
+	if time.Since(r.cp.ProgressingSince()) < deadline {
+		r.cp.SetComponentCondition(cpv3.ConditionFailed, false, conditionReasonProgressing, "")
+
+		return recon
+	}
+
+	wasFailed := cond.IsStatusConditionTrue(r.cp.Status.Conditions, cpv3.ConditionFailed)
+	message := fmt.Sprintf("Not ready within the progress deadline of %s: %s", deadline, problem)
+	r.cp.SetComponentCondition(cpv3.ConditionFailed, true, conditionReasonProgressDeadlineExceeded, message)
+
+	if !wasFailed {
+		r.log.Info(fmt.Sprintf("ControlPlane %s failed: %s", r.cp.Name, message))
+		r.recordWarning(eventReasonFailed, "%s", message)
+	}
+
+	// Errors are still returned for their backoff and to be reported
+	if recon.Err != nil {
+		return op.ReconcileWithError(recon.Err)
+	}
+
+	return op.ReconcileWithRequeue(failedRetryInterval)
+}
+
+// getPendingRollouts returns the workloads that did not roll out their current spec to all desired replicas yet
+func (r *ControlPlaneReconciler) getPendingRollouts(ctx context.Context) ([]string, error) {
+	pending := []string{}
+
+	for _, name := range append([]string{controllerName}, getRouterNames(r.cp)...) {
+		dep := &appsv1.Deployment{}
+		if err := r.Client.Get(ctx, types.NamespacedName{Name: name, Namespace: r.cp.Namespace}, dep); err != nil {
+			if k8serrors.IsNotFound(err) {
+				pending = append(pending, fmt.Sprintf("Deployment %s not found", name))
+
+				continue
+			}
+
+			return nil, err
+		}
+
+		if problem := getDeploymentRollout(dep); problem != "" {
+			pending = append(pending, problem)
+		}
+	}
+
+	if isNatsEnabled(r.cp) {
+		st := &appsv1.StatefulSet{}
+		if err := r.Client.Get(ctx, types.NamespacedName{Name: "nats", Namespace: r.cp.Namespace}, st); err != nil {
+			if !k8serrors.IsNotFound(err) {
+				return nil, err
+			}
+
+			pending = append(pending, "StatefulSet nats not found")
+		} else if problem := getStatefulSetRollout(st); problem != "" {
+			pending = append(pending, problem)
+		}
+	}
+
+	return pending, nil
+}
+
+// getDeploymentRollout describes why the rollout of a Deployment is not complete, like kubectl rollout status
+func getDeploymentRollout(dep *appsv1.Deployment) string {
+	desired := int32(1)
+	if dep.Spec.Replicas != nil {
+		desired = *dep.Spec.Replicas
+	}
+
+	status := dep.Status
+
+	switch {
+	case status.ObservedGeneration < dep.Generation:
+		return fmt.Sprintf("Deployment %s did not observe its spec yet", dep.Name)
+	case status.UpdatedReplicas < desired:
+		return fmt.Sprintf("Deployment %s has %d of %d replicas updated", dep.Name, status.UpdatedReplicas, desired)
+	case status.Replicas > status.UpdatedReplicas:
+		return fmt.Sprintf("Deployment %s has %d old replicas pending termination", dep.Name, status.Replicas-status.UpdatedReplicas)
+	case status.AvailableReplicas < desired:
+		return fmt.Sprintf("Deployment %s has %d of %d updated replicas available", dep.Name, status.AvailableReplicas, desired)
+	default:
+		return ""
+	}
+}
+
+// getStatefulSetRollout describes why the rollout of a StatefulSet is not complete, like kubectl rollout status
+func getStatefulSetRollout(st *appsv1.StatefulSet) string {
+	desired := int32(1)
+	if st.Spec.Replicas != nil {
+		desired = *st.Spec.Replicas
+	}
+
+	status := st.Status
+
+	switch {
+	case status.ObservedGeneration < st.Generation:
+		return fmt.Sprintf("StatefulSet %s did not observe its spec yet", st.Name)
+	case status.UpdatedReplicas < desired:
+		return fmt.Sprintf("StatefulSet %s has %d of %d replicas updated", st.Name, status.UpdatedReplicas, desired)
+	case st.Spec.UpdateStrategy.Type != appsv1.OnDeleteStatefulSetStrategyType && status.UpdateRevision != status.CurrentRevision:
+		return fmt.Sprintf("StatefulSet %s is rolling out revision %s", st.Name, status.UpdateRevision)
+	case status.AvailableReplicas < desired:
+		return fmt.Sprintf("StatefulSet %s has %d of %d updated replicas available", st.Name, status.AvailableReplicas, desired)
+	default:
+		return ""
+	}
+}
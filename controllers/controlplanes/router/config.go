@@ -5,41 +5,58 @@ import (
 	"strings"
 )
 
-func GetConfig(namespace string) string {
-	// Default values for parameters
+// Config holds the per-router values of skrouterd.json
+type Config struct {
+	Namespace string
+	// ID identifies the router and its site in the interior network, every router of a ControlPlane needs its own
+	ID string
+	// InterRouterHosts are the interior routers this router opens inter-router links to
+	InterRouterHosts []string
+	// Listener ports, the inter-router links use the InteriorPort of the other routers as well
+	MessagePort  int
+	HTTPPort     int
+	InteriorPort int
+	EdgePort     int
+}
+
+func GetConfig(cfg Config) string {
+	connectors := ""
+	for _, host := range cfg.InterRouterHosts {
+		connectors += strings.NewReplacer(
+			"<NAME>", host,
+			"<HOST>", host,
+			"<INTERIOR_PORT>", strconv.Itoa(cfg.InteriorPort),
+		).Replace(rawConnectorConfig)
+	}
+
 	replacer := strings.NewReplacer(
-		"<MESSAGE_PORT>", strconv.Itoa(MessagePort),
-		"<HTTP_PORT>", strconv.Itoa(HTTPPort),
-		"<INTERIOR_PORT>", strconv.Itoa(InteriorPort),
-		"<EDGE_PORT>", strconv.Itoa(EdgePort),
-		"<NAMESPACE>", namespace,
+		"<MESSAGE_PORT>", strconv.Itoa(cfg.MessagePort),
+		"<HTTP_PORT>", strconv.Itoa(cfg.HTTPPort),
+		"<INTERIOR_PORT>", strconv.Itoa(cfg.InteriorPort),
+		"<EDGE_PORT>", strconv.Itoa(cfg.EdgePort),
+		"<NAMESPACE>", cfg.Namespace,
+		"<ROUTER_ID>", cfg.ID,
+		"<CONNECTORS>", connectors,
 	)
 
 	return replacer.Replace(rawRouterConfig)
 }
 
-const (
-	MessagePort  = 5671
-	HTTPPort     = 9090
-	InteriorPort = 55671
-	EdgePort     = 45671
-)
-
 const rawRouterConfig = `
 [
     [
         "router",
         {
-            "id": "default-router",
+            "id": "<ROUTER_ID>",
             "mode": "interior",
             "helloMaxAgeSeconds": "3",
-            "metadata": "{\"id\":\"default-router\",\"version\":\"pot\",\"platform\":\"kubernetes\",\"pot-config\":\"1.0.0\"}"
+            "metadata": "{\"id\":\"<ROUTER_ID>\",\"version\":\"pot\",\"platform\":\"kubernetes\",\"pot-config\":\"1.0.0\"}"
         }
     ],
     [
         "site",
         {
-            "name": "default-router",
+            "name": "<ROUTER_ID>",
             "platform": "kubernetes",
             "namespace": "<NAMESPACE>",
             "version": "pot"
@@ -122,7 +139,7 @@ const rawRouterConfig = `
             "authenticatePeer": true
         }
     ],
-    [
+    <CONNECTORS>[
         "address",
         {
             "prefix": "mc",
@@ -138,3 +155,17 @@ const rawRouterConfig = `
     ]
 ]
 `
+
+// rawConnectorConfig is an inter-router link, it is inserted before the address entry of rawRouterConfig
+const rawConnectorConfig = `[
+        "connector",
+        {
+            "name": "<NAME>",
+            "role": "inter-router",
+            "host": "<HOST>",
+            "port": <INTERIOR_PORT>,
+            "sslProfile": "router-site-server",
+            "saslMechanisms": "EXTERNAL"
+        }
+    ],
+    `
@@ -19,8 +19,11 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	op "github.com/datasance/iofog-go-sdk/v3/pkg/k8s/operator"
+	cpv3 "github.com/datasance/iofog-operator/v3/apis/controlplanes/v3"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 type reconcileFunc = func(ctx context.Context) op.Reconciliation
@@ -48,27 +51,116 @@ func (r *ControlPlaneReconciler) getReconcileFunc(ctx context.Context) (reconcil
 }
 
 func (r *ControlPlaneReconciler) reconcileReady(ctx context.Context) op.Reconciliation {
-	// Do nothing
 	r.log.Info(fmt.Sprintf("reconcileReady() ControlPlane %s", r.cp.Name))
 
-	return op.Reconcile()
+	previousStatus := r.cp.Status.DeepCopy()
+
+	// A referenced Secret changed, ready -> updating to roll the Controller
+	changed, err := r.credentialsChanged(ctx)
+	if err != nil {
+		return op.ReconcileWithError(err)
+	}
+
+	if changed {
+		r.log.Info(fmt.Sprintf("reconcileReady() ControlPlane %s credentials changed", r.cp.Name))
+		r.cp.SetConditionUpdating(&r.log)
+		r.recordEvent(eventReasonUpdating, "Credentials changed, updating the Controller")
+
+		if err := r.Status().Update(ctx, &r.cp); err != nil {
+			return op.ReconcileWithError(err)
+		}
+
+		return op.Reconcile()
+	}
+
+	// Owned objects were changed or deleted, ready -> updating to restore them
+	drifted, err := r.getDriftedObjects(ctx)
+	if err != nil {
+		return op.ReconcileWithError(err)
+	}
+
+	if len(drifted) > 0 {
+		r.log.Info(fmt.Sprintf("reconcileReady() ControlPlane %s drifted: %s", r.cp.Name, strings.Join(drifted, ", ")))
+		r.cp.SetConditionUpdating(&r.log)
+		r.recordWarning(eventReasonDrifted, "Restoring %s", strings.Join(drifted, ", "))
+
+		if err := r.Status().Update(ctx, &r.cp); err != nil {
+			return op.ReconcileWithError(err)
+		}
+
+		return op.Reconcile()
+	}
+
+	// A CA rotation started or the previous CAs retired, ready -> updating to reissue the server certificates
+	rotated, err := r.reconcileCARotation(ctx)
+	if err != nil {
+		return op.ReconcileWithError(err)
+	}
+
+	// Server certificates due for renewal or reissued by cert-manager, ready -> updating to roll the pods
+	if _, err := r.setCertificatesStatus(ctx); err != nil {
+		return op.ReconcileWithError(err)
+	}
+
+	if err := r.setReplicasStatus(ctx); err != nil {
+		return op.ReconcileWithError(err)
+	}
+
+	// Components failing their health checks for the grace period, ready -> deploying to repair them
+	repair, err := r.checkHealth(ctx)
+	if err != nil {
+		return op.ReconcileWithError(err)
+	}
+
+	changed, err = r.certificatesChanged(ctx)
+	if err != nil {
+		return op.ReconcileWithError(err)
+	}
+
+	changed = changed || rotated
+
+	if changed {
+		r.log.Info(fmt.Sprintf("reconcileReady() ControlPlane %s certificates changed", r.cp.Name))
+		r.cp.SetConditionUpdating(&r.log)
+		r.recordEvent(eventReasonUpdating, "Certificates changed, updating the router and NATS")
+	} else if repair {
+		r.log.Info(fmt.Sprintf("reconcileReady() ControlPlane %s degraded, redeploying", r.cp.Name))
+		r.cp.SetConditionDeploying(&r.log)
+		r.recordWarning(eventReasonRepairing, "ControlPlane is degraded, redeploying it")
+
+		changed = true
+	}
+
+	if err := r.updateStatusIfChanged(ctx, previousStatus); err != nil {
+		return op.ReconcileWithError(err)
+	}
+
+	if changed {
+		return op.Reconcile()
+	}
+
+	return op.ReconcileWithRequeue(min(r.getCertificateCheckDelay(), healthCheckInterval))
 }
 
 func (r *ControlPlaneReconciler) reconcileDeploying(ctx context.Context) op.Reconciliation {
 	r.log.Info(fmt.Sprintf("reconcileDeploying() ControlPlane %s", r.cp.Name))
 
+	previousStatus := r.cp.Status.DeepCopy()
+
+	r.startProgress()
+
 	// Error chan for reconcile routines
 	reconcilerCount := 3
 	reconChan := make(chan op.Reconciliation, reconcilerCount)
 
 	// Reconcile Router
-	go reconcileRoutine(ctx, r.reconcileRouter, reconChan)
+	go r.reconcileRoutine(ctx, "reconcileRouter", cpv3.ConditionRouterReady, r.reconcileRouter, reconChan)
 
 	// Reconcile NATS (when enabled)
-	go reconcileRoutine(ctx, r.reconcileNats, reconChan)
+	go r.reconcileRoutine(ctx, "reconcileNats", cpv3.ConditionNatsReady, r.reconcileNats, reconChan)
 
 	// Reconcile Iofog Controller
-	go reconcileRoutine(ctx, r.reconcileIofogController, reconChan)
+	go r.reconcileRoutine(ctx, "reconcileIofogController", cpv3.ConditionControllerReady, r.reconcileIofogController, reconChan)
 
 	// Wait for all parallel recons and evaluate results
 	finRecon := op.Reconciliation{}
@@ -97,9 +189,20 @@ func (r *ControlPlaneReconciler) reconcileDeploying(ctx context.Context) op.Reco
 		}
 	}
 
+	if err := r.reconcileStatus(ctx, finRecon); err != nil {
+		return op.ReconcileWithError(err)
+	}
+
+	// Ready once the workloads rolled out, Failed when that takes longer than the progress deadline
+	finRecon = r.reconcileRollout(ctx, finRecon)
+
 	if finRecon.IsFinal() {
 		r.log.Info(fmt.Sprintf("reconcileDeploying() ControlPlane %s isFinal", r.cp.Name))
 
+		if err := r.updateStatusIfChanged(ctx, previousStatus); err != nil {
+			return op.ReconcileWithError(err)
+		}
+
 		return finRecon
 	}
 
@@ -107,6 +210,11 @@ func (r *ControlPlaneReconciler) reconcileDeploying(ctx context.Context) op.Reco
 	if r.cp.IsDeploying() {
 		r.log.Info(fmt.Sprintf("reconcileDeploying() ControlPlane %s setReady", r.cp.Name))
 		r.cp.SetConditionReady(&r.log) // temporary logger
+
+		if _, err := r.setCertificatesStatus(ctx); err != nil {
+			return op.ReconcileWithError(err)
+		}
+
 		r.log.Info(fmt.Sprintf("reconcileDeploying() ControlPlane %s -- write status update, new conditions %v", r.cp.Name, r.cp.Status.Conditions))
 
 		if err := r.Status().Update(ctx, &r.cp); err != nil {
@@ -119,29 +227,40 @@ func (r *ControlPlaneReconciler) reconcileDeploying(ctx context.Context) op.Reco
 			return op.ReconcileWithError(err)
 		}
 
+		r.forgetDeletedObjects(client.ObjectKeyFromObject(&r.cp))
+
 		r.log.Info(fmt.Sprintf("Control Plane %s is ready", r.cp.Name))
+		r.recordEvent(eventReasonReady, "Deployed, ControlPlane is ready")
 
 		return op.Reconcile()
 	}
 
+	if err := r.updateStatusIfChanged(ctx, previousStatus); err != nil {
+		return op.ReconcileWithError(err)
+	}
+
 	return op.Continue()
 }
 
 func (r *ControlPlaneReconciler) reconcileUpdating(ctx context.Context) op.Reconciliation {
 	r.log.Info(fmt.Sprintf("reconcileUpdating() ControlPlane %s", r.cp.Name))
 
+	previousStatus := r.cp.Status.DeepCopy()
+
+	r.startProgress()
+
 	// Error chan for reconcile routines
 	reconcilerCount := 3
 	reconChan := make(chan op.Reconciliation, reconcilerCount)
 
 	// Reconcile Router
-	go reconcileRoutine(ctx, r.reconcileRouter, reconChan)
+	go r.reconcileRoutine(ctx, "reconcileRouter", cpv3.ConditionRouterReady, r.reconcileRouter, reconChan)
 
 	// Reconcile NATS (when enabled)
-	go reconcileRoutine(ctx, r.reconcileNats, reconChan)
+	go r.reconcileRoutine(ctx, "reconcileNats", cpv3.ConditionNatsReady, r.reconcileNats, reconChan)
 
 	// Reconcile Iofog Controller
-	go reconcileRoutine(ctx, r.reconcileIofogController, reconChan)
+	go r.reconcileRoutine(ctx, "reconcileIofogController", cpv3.ConditionControllerReady, r.reconcileIofogController, reconChan)
 
 	// Wait for all parallel recons and evaluate results
 	finRecon := op.Reconciliation{}
@@ -170,9 +289,20 @@ func (r *ControlPlaneReconciler) reconcileUpdating(ctx context.Context) op.Recon
 		}
 	}
 
+	if err := r.reconcileStatus(ctx, finRecon); err != nil {
+		return op.ReconcileWithError(err)
+	}
+
+	// Ready once the workloads rolled out, Failed when that takes longer than the progress deadline
+	finRecon = r.reconcileRollout(ctx, finRecon)
+
 	if finRecon.IsFinal() {
 		r.log.Info(fmt.Sprintf("reconcileUpdating() ControlPlane %s isFinal", r.cp.Name))
 
+		if err := r.updateStatusIfChanged(ctx, previousStatus); err != nil {
+			return op.ReconcileWithError(err)
+		}
+
 		return finRecon
 	}
 
@@ -180,6 +310,11 @@ func (r *ControlPlaneReconciler) reconcileUpdating(ctx context.Context) op.Recon
 	if r.cp.IsUpdating() {
 		r.log.Info(fmt.Sprintf("reconcileUpdating() ControlPlane %s setReady", r.cp.Name))
 		r.cp.SetConditionReady(&r.log) // temporary logger
+
+		if _, err := r.setCertificatesStatus(ctx); err != nil {
+			return op.ReconcileWithError(err)
+		}
+
 		r.log.Info(fmt.Sprintf("reconcileUpdating() ControlPlane %s -- write status update, new conditions %v", r.cp.Name, r.cp.Status.Conditions))
 
 		if err := r.Status().Update(ctx, &r.cp); err != nil {
@@ -192,10 +327,17 @@ func (r *ControlPlaneReconciler) reconcileUpdating(ctx context.Context) op.Recon
 			return op.ReconcileWithError(err)
 		}
 
+		r.forgetDeletedObjects(client.ObjectKeyFromObject(&r.cp))
+
 		r.log.Info(fmt.Sprintf("Control Plane %s is ready", r.cp.Name))
+		r.recordEvent(eventReasonReady, "Updated, ControlPlane is ready")
 
 		return op.Reconcile()
 	}
 
+	if err := r.updateStatusIfChanged(ctx, previousStatus); err != nil {
+		return op.ReconcileWithError(err)
+	}
+
 	return op.Continue()
 }
@@ -0,0 +1,189 @@
+package controllers
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"time"
+
+	op "github.com/datasance/iofog-go-sdk/v3/pkg/k8s/operator"
+	cpv3 "github.com/datasance/iofog-operator/v3/apis/controlplanes/v3"
+	openidutil "github.com/datasance/iofog-operator/v3/internal/util"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Reasons of the component conditions
+const (
+	conditionReasonReconciled      = "Reconciled"
+	conditionReasonProgressing     = "Progressing"
+	conditionReasonReconcileFailed = "ReconcileFailed"
+	conditionReasonReachable       = "Reachable"
+	conditionReasonUnreachable     = "Unreachable"
+	conditionReasonEmbedded        = "Embedded"
+
+	databaseDialTimeout = 5 * time.Second
+)
+
+// mutateStatus changes the status of the ControlPlane, the component reconciles run in parallel and share it
+func (r *ControlPlaneReconciler) mutateStatus(mutate func(status *cpv3.ControlPlaneStatus)) {
+	r.statusLock.Lock()
+	defer r.statusLock.Unlock()
+
+	mutate(&r.cp.Status)
+}
+
+// setComponentResult sets the condition of a component from the result of its reconcile
+func (r *ControlPlaneReconciler) setComponentResult(conditionType string, recon op.Reconciliation) {
+	r.statusLock.Lock()
+	defer r.statusLock.Unlock()
+
+	switch {
+	case recon.Err != nil:
+		r.cp.SetComponentCondition(conditionType, false, conditionReasonReconcileFailed, recon.Err.Error())
+	case recon.Requeue:
+		r.cp.SetComponentCondition(conditionType, false, conditionReasonProgressing, "Waiting for the component to become ready")
+	default:
+		r.cp.SetComponentCondition(conditionType, true, conditionReasonReconciled, "")
+	}
+}
+
+func (r *ControlPlaneReconciler) setEndpoint(set func(endpoints *cpv3.EndpointsStatus)) {
+	r.mutateStatus(func(status *cpv3.ControlPlaneStatus) {
+		if status.Endpoints == nil {
+			status.Endpoints = &cpv3.EndpointsStatus{}
+		}
+
+		set(status.Endpoints)
+	})
+}
+
+// getEndpoint returns a recorded endpoint, empty until it is resolved
+func (r *ControlPlaneReconciler) getEndpoint(get func(endpoints cpv3.EndpointsStatus) string) string {
+	r.statusLock.Lock()
+	defer r.statusLock.Unlock()
+
+	if r.cp.Status.Endpoints == nil {
+		return ""
+	}
+
+	return get(*r.cp.Status.Endpoints)
+}
+
+func (r *ControlPlaneReconciler) setControllerVersion(version string) {
+	r.mutateStatus(func(status *cpv3.ControlPlaneStatus) {
+		status.ControllerVersion = version
+	})
+}
+
+// reconcileStatus records the reachability of the external dependencies, the ready replicas and the last error
+// once the component reconciles finished
+func (r *ControlPlaneReconciler) reconcileStatus(ctx context.Context, recon op.Reconciliation) error {
+	r.setDependencyConditions()
+
+	if !isNatsEnabled(r.cp) {
+		r.cp.RemoveComponentCondition(cpv3.ConditionNatsReady)
+
+		if r.cp.Status.Endpoints != nil {
+			r.cp.Status.Endpoints.Nats = ""
+		}
+	}
+
+	if recon.Err != nil {
+		r.cp.Status.LastError = recon.Err.Error()
+	} else {
+		r.cp.Status.LastError = ""
+	}
+
+	return r.setReplicasStatus(ctx)
+}
+
+// updateStatusIfChanged writes the status when it differs from the previous one
+func (r *ControlPlaneReconciler) updateStatusIfChanged(ctx context.Context, previous *cpv3.ControlPlaneStatus) error {
+	if equality.Semantic.DeepEqual(previous, &r.cp.Status) {
+		return nil
+	}
+
+	return r.Status().Update(ctx, &r.cp)
+}
+
+// setDependencyConditions checks the database and Keycloak the Controller depends on are reachable from the cluster
+func (r *ControlPlaneReconciler) setDependencyConditions() {
+	db := r.credentials.db
+	if db.Host == "" {
+		r.cp.SetComponentCondition(cpv3.ConditionDatabaseReachable, true, conditionReasonEmbedded, "The Controller uses its embedded SQLite database")
+	} else if err := checkDatabaseReachable(db); err != nil {
+		r.cp.SetComponentCondition(cpv3.ConditionDatabaseReachable, false, conditionReasonUnreachable, err.Error())
+	} else {
+		r.cp.SetComponentCondition(cpv3.ConditionDatabaseReachable, true, conditionReasonReachable, "")
+	}
+
+	if err := openidutil.CheckAuthReachable(r.credentials.auth); err != nil {
+		r.cp.SetComponentCondition(cpv3.ConditionAuthReachable, false, conditionReasonUnreachable, err.Error())
+	} else {
+		r.cp.SetComponentCondition(cpv3.ConditionAuthReachable, true, conditionReasonReachable, "")
+	}
+}
+
+func checkDatabaseReachable(db cpv3.Database) error {
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(db.Host, strconv.Itoa(db.Port)), databaseDialTimeout)
+	if err != nil {
+		return err
+	}
+
+	return conn.Close()
+}
+
+// setReplicasStatus records the ready replicas of the Controller, router and NATS workloads
+func (r *ControlPlaneReconciler) setReplicasStatus(ctx context.Context) error {
+	replicas := &cpv3.ReplicasStatus{}
+
+	controller, err := r.getReadyReplicas(ctx, &appsv1.Deployment{}, controllerName)
+	if err != nil {
+		return err
+	}
+
+	replicas.Controller = controller
+
+	for _, name := range getRouterNames(r.cp) {
+		ready, err := r.getReadyReplicas(ctx, &appsv1.Deployment{}, name)
+		if err != nil {
+			return err
+		}
+
+		replicas.Router += ready
+	}
+
+	if isNatsEnabled(r.cp) {
+		if replicas.Nats, err = r.getReadyReplicas(ctx, &appsv1.StatefulSet{}, "nats"); err != nil {
+			return err
+		}
+	}
+
+	r.cp.Status.Replicas = replicas
+
+	return nil
+}
+
+// getReadyReplicas returns the ready replicas of a Deployment or StatefulSet, none when it does not exist
+func (r *ControlPlaneReconciler) getReadyReplicas(ctx context.Context, workload client.Object, name string) (int32, error) {
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: name, Namespace: r.cp.Namespace}, workload); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return 0, nil
+		}
+
+		return 0, err
+	}
+
+	switch w := workload.(type) {
+	case *appsv1.Deployment:
+		return w.Status.ReadyReplicas, nil
+	case *appsv1.StatefulSet:
+		return w.Status.ReadyReplicas, nil
+	default:
+		return 0, nil
+	}
+}
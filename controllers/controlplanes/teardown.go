@@ -0,0 +1,365 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	op "github.com/datasance/iofog-go-sdk/v3/pkg/k8s/operator"
+	cpv3 "github.com/datasance/iofog-operator/v3/apis/controlplanes/v3"
+	openidutil "github.com/datasance/iofog-operator/v3/internal/util"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	// controlPlaneFinalizer holds the deletion of a ControlPlane until its teardown finished
+	controlPlaneFinalizer = "datasance.com/controlplane-teardown"
+
+	// The Job and Secret dropping an external database are not owned by the ControlPlane,
+	// garbage collection must not remove them while the teardown waits for the Job
+	databaseTeardownName = "controller-db-teardown"
+	// natsVolumeClaimPrefix prefixes the JetStream claims of the NATS StatefulSet volumeClaimTemplates
+	natsVolumeClaimPrefix = "js-data-nats-"
+
+	eventReasonTeardown       = "Teardown"
+	eventReasonTeardownFailed = "TeardownFailed"
+)
+
+func isExternalStateDeleted(cp cpv3.ControlPlane) bool {
+	return cp.Spec.DeletionPolicy != nil && cp.Spec.DeletionPolicy.ExternalState == cpv3.DeletionPolicyDelete
+}
+
+func isVolumeClaimsRetained(cp cpv3.ControlPlane) bool {
+	return cp.Spec.DeletionPolicy != nil && cp.Spec.DeletionPolicy.PersistentVolumeClaims == cpv3.DeletionPolicyRetain
+}
+
+// ensureFinalizer adds the teardown finalizer to the ControlPlane
+func (r *ControlPlaneReconciler) ensureFinalizer(ctx context.Context) error {
+	if controllerutil.ContainsFinalizer(&r.cp, controlPlaneFinalizer) {
+		return nil
+	}
+
+	controllerutil.AddFinalizer(&r.cp, controlPlaneFinalizer)
+
+	return r.Update(ctx, &r.cp)
+}
+
+// reconcileDeletion tears down what garbage collection does not remove according to the deletion policy
+// and releases the ControlPlane afterwards. External state is removed first, the ControlPlane stays until
+// that succeeded; setting the external state policy to Retain releases a ControlPlane stuck in its teardown.
+func (r *ControlPlaneReconciler) reconcileDeletion(ctx context.Context) op.Reconciliation {
+	if !controllerutil.ContainsFinalizer(&r.cp, controlPlaneFinalizer) {
+		return op.Reconcile()
+	}
+
+	r.log.Info(fmt.Sprintf("reconcileDeletion() ControlPlane %s", r.cp.Name))
+
+	if isExternalStateDeleted(r.cp) {
+		if recon := r.deleteExternalState(ctx); recon.IsFinal() {
+			return recon
+		}
+	}
+
+	if err := r.reconcileVolumeClaimsDeletion(ctx); err != nil {
+		return op.ReconcileWithError(err)
+	}
+
+	// Only the finalizer is patched, the spec is defaulted
+	base := r.cp.DeepCopy()
+	controllerutil.RemoveFinalizer(&r.cp, controlPlaneFinalizer)
+
+	if err := r.Patch(ctx, &r.cp, client.MergeFrom(base)); err != nil {
+		return op.ReconcileWithError(err)
+	}
+
+	r.log.Info(fmt.Sprintf("Teardown of ControlPlane %s finished", r.cp.Name))
+
+	return op.Reconcile()
+}
+
+// deleteExternalState resets the ECN Viewer client root URL and drops the external database of the Controller
+func (r *ControlPlaneReconciler) deleteExternalState(ctx context.Context) op.Reconciliation {
+	creds, err := r.resolveCredentials(ctx)
+	if err != nil {
+		r.recordWarning(eventReasonTeardownFailed, "Could not resolve credentials: %s", err.Error())
+
+		return op.ReconcileWithError(err)
+	}
+
+	r.credentials = creds
+
+	if r.credentials.auth.ViewerClient != "" {
+		if err := openidutil.ResetECNViewerClientRootURL(r.credentials.auth); err != nil {
+			r.recordWarning(eventReasonTeardownFailed, "Could not reset the ECN Viewer client root URL: %s", err.Error())
+
+			return op.ReconcileWithError(err)
+		}
+	}
+
+	// The embedded SQLite database lives in the Controller volume
+	if r.credentials.db.Host == "" {
+		return op.Continue()
+	}
+
+	return r.dropDatabase(ctx)
+}
+
+// dropDatabase runs a Job dropping the external database once the Controller is stopped
+func (r *ControlPlaneReconciler) dropDatabase(ctx context.Context) op.Reconciliation {
+	stopped, err := r.stopController(ctx)
+	if err != nil {
+		return op.ReconcileWithError(err)
+	}
+
+	if !stopped {
+		r.log.Info(fmt.Sprintf("Waiting for the Controller of ControlPlane %s to stop before dropping its database", r.cp.Name))
+
+		return op.ReconcileWithRequeue(time.Second * 5) //nolint:gomnd
+	}
+
+	job := &batchv1.Job{}
+
+	err = r.Client.Get(ctx, types.NamespacedName{Name: databaseTeardownName, Namespace: r.cp.Namespace}, job)
+	if k8serrors.IsNotFound(err) {
+		if err := r.createDatabaseTeardownJob(ctx); err != nil {
+			r.recordWarning(eventReasonTeardownFailed, "Could not drop database %s: %s", r.credentials.db.DatabaseName, err.Error())
+
+			return op.ReconcileWithError(err)
+		}
+
+		r.recordEvent(eventReasonTeardown, "Dropping database %s", r.credentials.db.DatabaseName)
+
+		return op.ReconcileWithRequeue(time.Second * 5) //nolint:gomnd
+	}
+
+	if err != nil {
+		return op.ReconcileWithError(err)
+	}
+
+	switch {
+	case job.Status.Succeeded > 0:
+		r.recordEvent(eventReasonTeardown, "Dropped database %s", r.credentials.db.DatabaseName)
+
+		if err := r.deleteDatabaseTeardownJob(ctx); err != nil {
+			return op.ReconcileWithError(err)
+		}
+
+		return op.Continue()
+	case isJobFailed(job):
+		// Start over with a new Job, e.g. after the credentials were fixed
+		r.recordWarning(eventReasonTeardownFailed, "Job %s could not drop database %s", databaseTeardownName, r.credentials.db.DatabaseName)
+
+		if err := r.deleteDatabaseTeardownJob(ctx); err != nil {
+			return op.ReconcileWithError(err)
+		}
+
+		return op.ReconcileWithRequeue(time.Minute)
+	default:
+		return op.ReconcileWithRequeue(time.Second * 5) //nolint:gomnd
+	}
+}
+
+// stopController removes the Controller Deployment and reports whether its pods are gone
+func (r *ControlPlaneReconciler) stopController(ctx context.Context) (bool, error) {
+	dep := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: controllerName, Namespace: r.cp.Namespace}}
+	if err := r.Client.Delete(ctx, dep, client.PropagationPolicy(metav1.DeletePropagationForeground)); err != nil && !k8serrors.IsNotFound(err) {
+		return false, err
+	}
+
+	pods := &corev1.PodList{}
+	if err := r.Client.List(ctx, pods, client.InNamespace(r.cp.Namespace), client.MatchingLabels(getStandardLabels(controllerName, r.cp.Name))); err != nil {
+		return false, err
+	}
+
+	return len(pods.Items) == 0, nil
+}
+
+// createDatabaseTeardownJob creates the Job and the Secret with the password it connects with
+func (r *ControlPlaneReconciler) createDatabaseTeardownJob(ctx context.Context) error {
+	db := r.credentials.db
+	if strings.ContainsAny(db.DatabaseName, "\"`") {
+		return fmt.Errorf("database name %s contains quotes", db.DatabaseName)
+	}
+
+	image, command, err := getDatabaseTeardownCommand(db)
+	if err != nil {
+		return err
+	}
+
+	labels := getStandardLabels(databaseTeardownName, r.cp.Name)
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      databaseTeardownName,
+			Namespace: r.cp.Namespace,
+			Labels:    labels,
+		},
+		StringData: map[string]string{controllerDBPasswordSecretKey: db.Password},
+	}
+	if err := r.Client.Create(ctx, secret); err != nil && !k8serrors.IsAlreadyExists(err) {
+		return err
+	}
+
+	ssl := "false"
+	if db.SSL != nil && *db.SSL {
+		ssl = "true"
+	}
+
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      databaseTeardownName,
+			Namespace: r.cp.Namespace,
+			Labels:    labels,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: ptr.To(int32(3)), //nolint:gomnd
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					Containers: []corev1.Container{
+						{
+							Name:    "drop-database",
+							Image:   image,
+							Command: []string{"sh", "-c", command},
+							Env: []corev1.EnvVar{
+								{Name: "DB_HOST", Value: db.Host},
+								{Name: "DB_PORT", Value: strconv.Itoa(db.Port)},
+								{Name: "DB_USER", Value: db.User},
+								{Name: "DB_NAME", Value: db.DatabaseName},
+								{Name: "DB_SSL", Value: ssl},
+								{
+									Name: "DB_PASSWORD",
+									ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+										LocalObjectReference: corev1.LocalObjectReference{Name: databaseTeardownName},
+										Key:                  controllerDBPasswordSecretKey,
+									}},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	if r.cp.Spec.Images.PullSecret != "" {
+		job.Spec.Template.Spec.ImagePullSecrets = []corev1.LocalObjectReference{{Name: r.cp.Spec.Images.PullSecret}}
+	}
+
+	return r.Client.Create(ctx, job)
+}
+
+func (r *ControlPlaneReconciler) deleteDatabaseTeardownJob(ctx context.Context) error {
+	meta := metav1.ObjectMeta{Name: databaseTeardownName, Namespace: r.cp.Namespace}
+
+	if err := r.Client.Delete(ctx, &batchv1.Job{ObjectMeta: meta}, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil && !k8serrors.IsNotFound(err) {
+		return err
+	}
+
+	if err := r.Client.Delete(ctx, &corev1.Secret{ObjectMeta: meta}); err != nil && !k8serrors.IsNotFound(err) {
+		return err
+	}
+
+	return nil
+}
+
+// getDatabaseTeardownCommand returns the client image and the shell command dropping the database of a provider
+func getDatabaseTeardownCommand(db cpv3.Database) (image, command string, err error) {
+	switch strings.ToLower(db.Provider) {
+	case "postgres", "postgresql":
+		return openidutil.GetPostgresClientImage(),
+			`if [ "$DB_SSL" = "true" ]; then export PGSSLMODE=require; fi; ` +
+				`PGPASSWORD="$DB_PASSWORD" psql -v ON_ERROR_STOP=1 -h "$DB_HOST" -p "$DB_PORT" -U "$DB_USER" -d postgres ` +
+				`-c "DROP DATABASE IF EXISTS \"$DB_NAME\""`, nil
+	case "mysql", "mariadb":
+		return openidutil.GetMysqlClientImage(),
+			`SSL_MODE=PREFERRED; if [ "$DB_SSL" = "true" ]; then SSL_MODE=REQUIRED; fi; ` +
+				`MYSQL_PWD="$DB_PASSWORD" mysql --ssl-mode="$SSL_MODE" -h "$DB_HOST" -P "$DB_PORT" -u "$DB_USER" ` +
+				"-e \"DROP DATABASE IF EXISTS \\`$DB_NAME\\`\"", nil
+	default:
+		return "", "", fmt.Errorf("dropping %s databases is not supported", db.Provider)
+	}
+}
+
+func isJobFailed(job *batchv1.Job) bool {
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == batchv1.JobFailed && condition.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+
+	return false
+}
+
+// reconcileVolumeClaimsDeletion deletes the JetStream claims the StatefulSet leaves behind, or keeps the Controller
+// claim from being garbage collected with the ControlPlane when claims are retained
+func (r *ControlPlaneReconciler) reconcileVolumeClaimsDeletion(ctx context.Context) error {
+	claims := &corev1.PersistentVolumeClaimList{}
+	if err := r.Client.List(ctx, claims, client.InNamespace(r.cp.Namespace)); err != nil {
+		return err
+	}
+
+	retain := isVolumeClaimsRetained(r.cp)
+
+	// Claims of other ControlPlanes in the namespace are kept, claims from before the instance label are matched by name
+	natsLabels := labels.SelectorFromSet(getNatsPodLabels(r.cp.Name))
+	natsClaims := map[string]bool{}
+
+	replicas := r.cp.Spec.Replicas.Nats
+	if replicas < cpv3.DefaultNatsReplicas {
+		replicas = cpv3.DefaultNatsReplicas
+	}
+
+	for i := int32(0); i < replicas; i++ {
+		natsClaims[fmt.Sprintf("%s%d", natsVolumeClaimPrefix, i)] = true
+	}
+
+	for i := range claims.Items {
+		claim := &claims.Items[i]
+
+		switch {
+		case retain && metav1.IsControlledBy(claim, &r.cp):
+			r.log.Info(fmt.Sprintf("Retaining PersistentVolumeClaim %s of ControlPlane %s", claim.Name, r.cp.Name))
+
+			claim.OwnerReferences = removeOwnerReference(claim.OwnerReferences, r.cp.UID)
+			if err := r.Client.Update(ctx, claim); err != nil {
+				return err
+			}
+		case !retain && strings.HasPrefix(claim.Name, natsVolumeClaimPrefix) &&
+			(natsClaims[claim.Name] || natsLabels.Matches(labels.Set(claim.Labels))):
+			r.log.Info(fmt.Sprintf("Deleting PersistentVolumeClaim %s of ControlPlane %s", claim.Name, r.cp.Name))
+
+			if err := r.Client.Delete(ctx, claim); err != nil && !k8serrors.IsNotFound(err) {
+				return err
+			}
+
+			r.recordEvent(eventReasonTeardown, "Deleted PersistentVolumeClaim %s", claim.Name)
+		}
+	}
+
+	return nil
+}
+
+func removeOwnerReference(refs []metav1.OwnerReference, uid types.UID) []metav1.OwnerReference {
+	kept := make([]metav1.OwnerReference, 0, len(refs))
+
+	for _, ref := range refs {
+		if ref.UID != uid {
+			kept = append(kept, ref)
+		}
+	}
+
+	return kept
+}
@@ -1,13 +1,17 @@
 package util
 
 import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/pem"
+	"errors"
 	"fmt"
-	"log"
 	"math/big"
 	"net"
 	"strings"
@@ -17,123 +21,192 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-func publicKey(priv interface{}) interface{} {
-	switch k := priv.(type) {
-	case *rsa.PrivateKey:
-		return &k.PublicKey
-	default:
-		return nil
-	}
-}
+// KeyAlgorithm is the algorithm of the private key of an issued certificate
+type KeyAlgorithm string
 
-func pemBlockForKey(priv interface{}) *pem.Block {
-	switch k := priv.(type) {
-	case *rsa.PrivateKey:
-		return &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(k)}
-	default:
-		return nil
-	}
+const (
+	KeyAlgorithmRSA       KeyAlgorithm = "RSA"
+	KeyAlgorithmECDSAP256 KeyAlgorithm = "ECDSA-P256"
+	KeyAlgorithmEd25519   KeyAlgorithm = "Ed25519"
+
+	// DefaultValidity of issued certificates
+	DefaultValidity = 5 * 365 * 24 * time.Hour
+	// DefaultRSABits is the size of RSA keys
+	DefaultRSABits = 2048
+
+	// HostsAnnotation records the comma separated hosts a generated server certificate was issued for
+	HostsAnnotation = "datasance.com/certificate-hosts"
+)
+
+// Options configures an issued certificate, the zero value issues an RSA server and client certificate valid for DefaultValidity
+type Options struct {
+	// CommonName is the subject of the certificate
+	CommonName string
+	// DNSNames and IPAddresses are the subject alternative names
+	DNSNames    []string
+	IPAddresses []net.IP
+	// Validity defaults to DefaultValidity
+	Validity time.Duration
+	// KeyAlgorithm defaults to RSA
+	KeyAlgorithm KeyAlgorithm
+	// RSABits defaults to DefaultRSABits
+	RSABits int
+	// KeyUsage defaults to digital signature, and key encipherment for RSA keys. CAs can also sign certificates.
+	KeyUsage x509.KeyUsage
+	// ExtKeyUsage defaults to server and client auth, CAs have none
+	ExtKeyUsage []x509.ExtKeyUsage
+	// IsCA issues a CA certificate
+	IsCA bool
+	// PKCS8 encodes the private key as PKCS#8, RSA keys are PKCS#1 and ECDSA keys SEC 1 otherwise.
+	// Ed25519 keys are always PKCS#8.
+	PKCS8 bool
 }
 
+// CertificateAuthority signs issued certificates
 type CertificateAuthority struct {
 	Certificate *x509.Certificate
-	Key         interface{}
+	Key         crypto.Signer
 	CrtData     []byte
 }
 
-type CertificateData map[string][]byte
+// ParseHosts splits comma separated hosts into DNS names and IP addresses
+func ParseHosts(hosts string) (dnsNames []string, ipAddresses []net.IP) {
+	for _, h := range strings.Split(hosts, ",") {
+		// Remove leading and trailing whitespaces from the string
+		h = strings.TrimSpace(h)
+		if h == "" {
+			continue
+		}
 
-func decodeDataElement(in []byte, name string) []byte {
-	block, _ := pem.Decode(in)
-	if block == nil {
-		log.Fatal("failed to decode PEM block of type " + name)
+		if ip := net.ParseIP(h); ip != nil {
+			ipAddresses = append(ipAddresses, ip)
+		} else {
+			dnsNames = append(dnsNames, h)
+		}
 	}
-	return block.Bytes
+
+	return dnsNames, ipAddresses
 }
 
-func getCAFromSecret(secret *corev1.Secret) *CertificateAuthority {
+// LoadCA reads the certificate and key of a CA Secret. Keys can be PKCS#1, SEC 1 or PKCS#8 encoded,
+// the first certificate of tls.crt has to belong to the key and be allowed to sign certificates.
+func LoadCA(secret *corev1.Secret) (*CertificateAuthority, error) {
 	if secret == nil || secret.Data == nil {
-		return nil
+		return nil, errors.New("CA secret has no data")
 	}
-	cert, err := x509.ParseCertificate(decodeDataElement(secret.Data["tls.crt"], "certificate"))
+
+	cert, err := DecodeCertificate(secret.Data["tls.crt"])
 	if err != nil {
-		log.Fatal("failed to get CA certificate from secret")
+		return nil, fmt.Errorf("failed to get CA certificate from secret %s: %w", secret.Name, err)
 	}
-	key, err := x509.ParsePKCS1PrivateKey(decodeDataElement(secret.Data["tls.key"], "private key"))
+
+	key, err := DecodePrivateKey(secret.Data["tls.key"])
 	if err != nil {
-		log.Fatal("failed to get CA private key from secret", err)
+		return nil, fmt.Errorf("failed to get CA private key from secret %s: %w", secret.Name, err)
+	}
+
+	if !publicKeysEqual(cert.PublicKey, key.Public()) {
+		return nil, fmt.Errorf("CA private key of secret %s does not match its certificate", secret.Name)
+	}
+
+	if !cert.BasicConstraintsValid || !cert.IsCA {
+		return nil, fmt.Errorf("certificate of secret %s is not a CA", secret.Name)
 	}
+
+	// Certificates without key usage are not restricted
+	if cert.KeyUsage != 0 && cert.KeyUsage&x509.KeyUsageCertSign == 0 {
+		return nil, fmt.Errorf("certificate of secret %s is not allowed to sign certificates", secret.Name)
+	}
+
 	return &CertificateAuthority{
 		Certificate: cert,
 		Key:         key,
 		CrtData:     secret.Data["tls.crt"],
+	}, nil
+}
+
+// CABundle returns the certificates a CA Secret trusts, its ca.crt or its tls.crt when it has none
+func CABundle(ca *corev1.Secret) []byte {
+	if len(ca.Data["ca.crt"]) > 0 {
+		return ca.Data["ca.crt"]
 	}
+
+	return ca.Data["tls.crt"]
 }
 
-// GenerateSecret generates a kubernetes secret.
+// Issue creates a kubernetes.io/tls Secret with a new key and certificate.
 // name is the corev1.Secret's name.
-// subject is the x509 certificate's common name.
-// hosts are the host names in the x509 certificate. Comma separated if more than one hostname
-// expiration is when the secret expires, if zero is passed in, the expiration is set to 5 years from now
-// ca is the certificate authority, if nil a ca cert will be created.
-func GenerateSecret(name string, subject string, hosts string, expiration time.Duration, ca *corev1.Secret) corev1.Secret {
-	caCert := getCAFromSecret(ca)
-
-	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+// ca signs the certificate, if nil the certificate is self signed.
+// ca.crt is the certificate of the CA, or the certificate itself when it is self signed.
+func Issue(name string, opts Options, ca *corev1.Secret) (corev1.Secret, error) {
+	var caCert *CertificateAuthority
+
+	if ca != nil {
+		var err error
+		if caCert, err = LoadCA(ca); err != nil {
+			return corev1.Secret{}, err
+		}
+	}
+
+	priv, err := generateKey(opts)
 	if err != nil {
-		log.Fatalf("failed to generate private key: %s", err)
+		return corev1.Secret{}, fmt.Errorf("failed to generate private key: %w", err)
 	}
 
 	notBefore := time.Now()
-	if expiration == 0 {
-		expiration = 5 * 365 * 24 * time.Hour
+	validity := opts.Validity
+	if validity == 0 {
+		validity = DefaultValidity
 	}
-	notAfter := notBefore.Add(expiration)
 
 	serialNumberLimit := new(big.Int).Lsh(big.NewInt(1), 128)
 	serialNumber, err := rand.Int(rand.Reader, serialNumberLimit)
 	if err != nil {
-		log.Fatalf("failed to generate serial number: %s", err)
+		return corev1.Secret{}, fmt.Errorf("failed to generate serial number: %w", err)
 	}
 
 	template := x509.Certificate{
 		SerialNumber: serialNumber,
 		Subject: pkix.Name{
-			CommonName: subject,
+			CommonName: opts.CommonName,
 		},
 		NotBefore:             notBefore,
-		NotAfter:              notAfter,
-		KeyUsage:              x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
-		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		NotAfter:              notBefore.Add(validity),
+		KeyUsage:              opts.KeyUsage,
+		ExtKeyUsage:           opts.ExtKeyUsage,
+		DNSNames:              opts.DNSNames,
+		IPAddresses:           opts.IPAddresses,
 		BasicConstraintsValid: true,
+		IsCA:                  opts.IsCA,
 	}
 
-	hosts_list := strings.Split(hosts, ",")
-	for _, h := range hosts_list {
-		// Remove leading and trailing whitespaces from the string
-		h = strings.TrimSpace(h)
-		if ip := net.ParseIP(h); ip != nil {
-			template.IPAddresses = append(template.IPAddresses, ip)
+	if template.KeyUsage == 0 {
+		template.KeyUsage = x509.KeyUsageDigitalSignature
+		if _, isRSA := priv.(*rsa.PrivateKey); isRSA {
+			template.KeyUsage |= x509.KeyUsageKeyEncipherment
 		}
-		template.DNSNames = append(template.DNSNames, h)
 	}
 
-	var parent *x509.Certificate
-	var cakey interface{}
-	if caCert == nil {
-		// self signed
-		template.IsCA = true
+	if opts.IsCA {
 		template.KeyUsage |= x509.KeyUsageCertSign
-		parent = &template
-		cakey = priv
-	} else {
-		parent = caCert.Certificate
-		cakey = caCert.Key
+	} else if template.ExtKeyUsage == nil {
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth}
+	}
+
+	// self signed
+	parent, signer := &template, crypto.Signer(priv)
+	if caCert != nil {
+		parent, signer = caCert.Certificate, caCert.Key
+	}
+
+	derBytes, err := x509.CreateCertificate(rand.Reader, &template, parent, priv.Public(), signer)
+	if err != nil {
+		return corev1.Secret{}, fmt.Errorf("failed to create certificate: %w", err)
 	}
 
-	derBytes, err := x509.CreateCertificate(rand.Reader, &template, parent, publicKey(priv), cakey)
+	keyBlock, err := pemBlockForKey(priv, opts.PKCS8)
 	if err != nil {
-		log.Fatalf("Failed to create certificate: %s", err)
+		return corev1.Secret{}, err
 	}
 
 	secret := corev1.Secret{
@@ -144,22 +217,29 @@ func GenerateSecret(name string, subject string, hosts string, expiration time.D
 		ObjectMeta: metav1.ObjectMeta{
 			Name: name,
 		},
-		Type: "kubernetes.io/tls",
+		Type: corev1.SecretTypeTLS,
 		Data: map[string][]byte{},
 	}
 
-	certString := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: derBytes})
-	keyString := pem.EncodeToMemory(pemBlockForKey(priv))
-
-	secret.Data["tls.crt"] = []byte(certString)
-	secret.Data["tls.key"] = []byte(keyString)
-	if ca != nil {
+	secret.Data["tls.crt"] = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: derBytes})
+	secret.Data["tls.key"] = pem.EncodeToMemory(keyBlock)
+	if caCert != nil {
 		secret.Data["ca.crt"] = caCert.CrtData
 	} else {
-		secret.Data["ca.crt"] = secret.Data["tls.crt"] //self.signed
+		secret.Data["ca.crt"] = secret.Data["tls.crt"] // self signed
+	}
+
+	return secret, nil
+}
+
+// IssueCA creates a self signed CA Secret named after its subject
+func IssueCA(name string, opts Options) (corev1.Secret, error) {
+	if opts.CommonName == "" {
+		opts.CommonName = name
 	}
+	opts.IsCA = true
 
-	return secret
+	return Issue(name, opts, nil)
 }
 
 func DecodeCertificate(data []byte) (*x509.Certificate, error) {
@@ -169,3 +249,80 @@ func DecodeCertificate(data []byte) (*x509.Certificate, error) {
 	}
 	return x509.ParseCertificate(b.Bytes)
 }
+
+// DecodePrivateKey parses a PKCS#1, SEC 1 or PKCS#8 encoded private key
+func DecodePrivateKey(data []byte) (crypto.Signer, error) {
+	b, _ := pem.Decode(data)
+	if b == nil {
+		return nil, errors.New("could not decode PEM block of private key")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(b.Bytes); err == nil {
+		return key, nil
+	}
+
+	if key, err := x509.ParseECPrivateKey(b.Bytes); err == nil {
+		return key, nil
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(b.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported private key format %s: %w", b.Type, err)
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+
+	return signer, nil
+}
+
+func generateKey(opts Options) (crypto.Signer, error) {
+	switch opts.KeyAlgorithm {
+	case "", KeyAlgorithmRSA:
+		bits := opts.RSABits
+		if bits == 0 {
+			bits = DefaultRSABits
+		}
+
+		return rsa.GenerateKey(rand.Reader, bits)
+	case KeyAlgorithmECDSAP256:
+		return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case KeyAlgorithmEd25519:
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+
+		return key, err
+	default:
+		return nil, fmt.Errorf("unsupported key algorithm %s", opts.KeyAlgorithm)
+	}
+}
+
+func pemBlockForKey(priv crypto.Signer, pkcs8 bool) (*pem.Block, error) {
+	if !pkcs8 {
+		switch k := priv.(type) {
+		case *rsa.PrivateKey:
+			return &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(k)}, nil
+		case *ecdsa.PrivateKey:
+			der, err := x509.MarshalECPrivateKey(k)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal private key: %w", err)
+			}
+
+			return &pem.Block{Type: "EC PRIVATE KEY", Bytes: der}, nil
+		}
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal private key: %w", err)
+	}
+
+	return &pem.Block{Type: "PRIVATE KEY", Bytes: der}, nil
+}
+
+func publicKeysEqual(a, b crypto.PublicKey) bool {
+	key, ok := a.(interface{ Equal(crypto.PublicKey) bool })
+
+	return ok && key.Equal(b)
+}
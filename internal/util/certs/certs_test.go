@@ -0,0 +1,407 @@
+package util
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// keyEncodings are the supported private key algorithms and encodings with their PEM block type
+var keyEncodings = map[string]struct {
+	opts      Options
+	blockType string
+}{
+	"RSA PKCS#1":     {opts: Options{KeyAlgorithm: KeyAlgorithmRSA}, blockType: "RSA PRIVATE KEY"},
+	"RSA PKCS#8":     {opts: Options{KeyAlgorithm: KeyAlgorithmRSA, PKCS8: true}, blockType: "PRIVATE KEY"},
+	"ECDSA SEC 1":    {opts: Options{KeyAlgorithm: KeyAlgorithmECDSAP256}, blockType: "EC PRIVATE KEY"},
+	"ECDSA PKCS#8":   {opts: Options{KeyAlgorithm: KeyAlgorithmECDSAP256, PKCS8: true}, blockType: "PRIVATE KEY"},
+	"Ed25519 PKCS#8": {opts: Options{KeyAlgorithm: KeyAlgorithmEd25519}, blockType: "PRIVATE KEY"},
+}
+
+func mustIssueCA(t *testing.T, name string, opts Options) *corev1.Secret {
+	t.Helper()
+
+	ca, err := IssueCA(name, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &ca
+}
+
+func mustDecodeCertificate(t *testing.T, data []byte) *x509.Certificate {
+	t.Helper()
+
+	cert, err := DecodeCertificate(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return cert
+}
+
+func TestIssue(t *testing.T) {
+	for name, enc := range keyEncodings {
+		for caName, caOpts := range map[string]*Options{
+			"self signed":    nil,
+			"RSA CA":         {},
+			"ECDSA CA":       {KeyAlgorithm: KeyAlgorithmECDSAP256, PKCS8: true},
+			"Ed25519 CA":     {KeyAlgorithm: KeyAlgorithmEd25519},
+			"ECDSA SEC 1 CA": {KeyAlgorithm: KeyAlgorithmECDSAP256},
+		} {
+			t.Run(name+"/"+caName, func(t *testing.T) {
+				var ca *corev1.Secret
+				if caOpts != nil {
+					ca = mustIssueCA(t, "ca", *caOpts)
+				}
+
+				opts := enc.opts
+				opts.CommonName = "server"
+				opts.DNSNames = []string{"server.iofog.svc"}
+				opts.IPAddresses = []net.IP{net.ParseIP("10.0.0.1")}
+
+				secret, err := Issue("server", opts, ca)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if secret.Name != "server" || secret.Type != corev1.SecretTypeTLS {
+					t.Errorf("unexpected Secret %s of type %s", secret.Name, secret.Type)
+				}
+
+				block, _ := pem.Decode(secret.Data["tls.key"])
+				if block == nil || block.Type != enc.blockType {
+					t.Fatalf("expected a %s PEM block", enc.blockType)
+				}
+
+				key, err := DecodePrivateKey(secret.Data["tls.key"])
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				cert := mustDecodeCertificate(t, secret.Data["tls.crt"])
+				if !publicKeysEqual(cert.PublicKey, key.Public()) {
+					t.Error("expected the key to belong to the certificate")
+				}
+
+				if cert.IsCA || cert.Subject.CommonName != "server" {
+					t.Errorf("unexpected certificate %s, CA %t", cert.Subject.CommonName, cert.IsCA)
+				}
+
+				if err := cert.VerifyHostname("server.iofog.svc"); err != nil {
+					t.Error(err)
+				}
+
+				if err := cert.VerifyHostname("10.0.0.1"); err != nil {
+					t.Error(err)
+				}
+
+				if got := cert.NotAfter.Sub(cert.NotBefore); got != DefaultValidity {
+					t.Errorf("expected a validity of %s, got %s", DefaultValidity, got)
+				}
+
+				wantCA := secret.Data["tls.crt"]
+				if ca != nil {
+					wantCA = ca.Data["tls.crt"]
+				}
+
+				if !bytes.Equal(secret.Data["ca.crt"], wantCA) {
+					t.Error("expected ca.crt to be the certificate of the CA")
+				}
+
+				roots := x509.NewCertPool()
+				roots.AddCert(mustDecodeCertificate(t, wantCA))
+
+				if _, err := cert.Verify(x509.VerifyOptions{
+					Roots:     roots,
+					KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+				}); err != nil {
+					t.Error(err)
+				}
+			})
+		}
+	}
+}
+
+func TestIssueOptions(t *testing.T) {
+	for name, tc := range map[string]struct {
+		opts        Options
+		keyUsage    x509.KeyUsage
+		extKeyUsage []x509.ExtKeyUsage
+		validity    time.Duration
+		rsaBits     int
+	}{
+		"RSA defaults": {
+			keyUsage:    x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+			extKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+			validity:    DefaultValidity,
+			rsaBits:     DefaultRSABits,
+		},
+		"ECDSA defaults": {
+			opts:        Options{KeyAlgorithm: KeyAlgorithmECDSAP256},
+			keyUsage:    x509.KeyUsageDigitalSignature,
+			extKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+			validity:    DefaultValidity,
+		},
+		"explicit": {
+			opts: Options{
+				Validity:    time.Hour,
+				RSABits:     3072,
+				KeyUsage:    x509.KeyUsageDigitalSignature,
+				ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+			},
+			keyUsage:    x509.KeyUsageDigitalSignature,
+			extKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+			validity:    time.Hour,
+			rsaBits:     3072,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			secret, err := Issue("server", tc.opts, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			cert := mustDecodeCertificate(t, secret.Data["tls.crt"])
+
+			if cert.KeyUsage != tc.keyUsage {
+				t.Errorf("expected key usage %v, got %v", tc.keyUsage, cert.KeyUsage)
+			}
+
+			if len(cert.ExtKeyUsage) != len(tc.extKeyUsage) {
+				t.Errorf("expected extended key usage %v, got %v", tc.extKeyUsage, cert.ExtKeyUsage)
+			}
+
+			if got := cert.NotAfter.Sub(cert.NotBefore); got != tc.validity {
+				t.Errorf("expected a validity of %s, got %s", tc.validity, got)
+			}
+
+			if key, ok := cert.PublicKey.(*rsa.PublicKey); ok && key.N.BitLen() != tc.rsaBits {
+				t.Errorf("expected a %d bit RSA key, got %d", tc.rsaBits, key.N.BitLen())
+			}
+		})
+	}
+}
+
+func TestIssueErrors(t *testing.T) {
+	for name, tc := range map[string]struct {
+		opts Options
+		ca   *corev1.Secret
+	}{
+		"unsupported key algorithm": {opts: Options{KeyAlgorithm: "DSA"}},
+		"CA without data":           {ca: &corev1.Secret{}},
+		"CA without key":            {ca: &corev1.Secret{Data: map[string][]byte{"tls.crt": mustIssueCA(t, "ca", Options{}).Data["tls.crt"]}}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := Issue("server", tc.opts, tc.ca); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestIssueCA(t *testing.T) {
+	for name, enc := range keyEncodings {
+		t.Run(name, func(t *testing.T) {
+			secret := mustIssueCA(t, "router-site-ca", enc.opts)
+
+			cert := mustDecodeCertificate(t, secret.Data["tls.crt"])
+
+			if !cert.IsCA || !cert.BasicConstraintsValid || cert.KeyUsage&x509.KeyUsageCertSign == 0 {
+				t.Error("expected a CA allowed to sign certificates")
+			}
+
+			if len(cert.ExtKeyUsage) != 0 {
+				t.Errorf("expected no extended key usage, got %v", cert.ExtKeyUsage)
+			}
+
+			if cert.Subject.CommonName != "router-site-ca" {
+				t.Errorf("expected the CA to be named after the Secret, got %s", cert.Subject.CommonName)
+			}
+
+			if !bytes.Equal(secret.Data["ca.crt"], secret.Data["tls.crt"]) {
+				t.Error("expected ca.crt to be the CA itself")
+			}
+
+			if err := cert.CheckSignatureFrom(cert); err != nil {
+				t.Errorf("expected a self signed CA: %v", err)
+			}
+
+			if _, err := LoadCA(secret); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+
+	t.Run("common name", func(t *testing.T) {
+		cert := mustDecodeCertificate(t, mustIssueCA(t, "router-site-ca", Options{CommonName: "Router CA"}).Data["tls.crt"])
+		if cert.Subject.CommonName != "Router CA" {
+			t.Errorf("expected the common name of the options, got %s", cert.Subject.CommonName)
+		}
+	})
+}
+
+func TestLoadCA(t *testing.T) {
+	for name, enc := range keyEncodings {
+		t.Run(name, func(t *testing.T) {
+			secret := mustIssueCA(t, "ca", enc.opts)
+
+			ca, err := LoadCA(secret)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !publicKeysEqual(ca.Certificate.PublicKey, ca.Key.Public()) {
+				t.Error("expected the key of the CA")
+			}
+
+			if !bytes.Equal(ca.CrtData, secret.Data["tls.crt"]) {
+				t.Error("expected the certificate data of the Secret")
+			}
+		})
+	}
+
+	t.Run("chain", func(t *testing.T) {
+		root := mustIssueCA(t, "root", Options{})
+		intermediate := mustIssueCA(t, "intermediate", Options{})
+		intermediate.Data["tls.crt"] = append(intermediate.Data["tls.crt"], root.Data["tls.crt"]...)
+
+		ca, err := LoadCA(intermediate)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if ca.Certificate.Subject.CommonName != "intermediate" {
+			t.Errorf("expected the first certificate of the chain, got %s", ca.Certificate.Subject.CommonName)
+		}
+	})
+
+	server, err := Issue("server", Options{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ca := mustIssueCA(t, "ca", Options{})
+	other := mustIssueCA(t, "other", Options{})
+
+	for name, secret := range map[string]*corev1.Secret{
+		"nil":             nil,
+		"without data":    {},
+		"invalid crt":     {Data: map[string][]byte{"tls.crt": []byte("crt"), "tls.key": ca.Data["tls.key"]}},
+		"invalid key":     {Data: map[string][]byte{"tls.crt": ca.Data["tls.crt"], "tls.key": []byte("key")}},
+		"mismatched key":  {Data: map[string][]byte{"tls.crt": ca.Data["tls.crt"], "tls.key": other.Data["tls.key"]}},
+		"not a CA":        &server,
+		"without signing": newCAWithoutCertSign(t),
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := LoadCA(secret); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+// newCAWithoutCertSign returns a CA Secret whose key usage does not allow signing certificates
+func newCAWithoutCertSign(t *testing.T) *corev1.Secret {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "ca"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &corev1.Secret{Data: map[string][]byte{
+		"tls.crt": pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		"tls.key": encodeKey(t, key, true),
+	}}
+}
+
+func encodeKey(t *testing.T, key crypto.Signer, pkcs8 bool) []byte {
+	t.Helper()
+
+	block, err := pemBlockForKey(key, pkcs8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return pem.EncodeToMemory(block)
+}
+
+func TestDecodePrivateKey(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, DefaultRSABits)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, ed25519Key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, tc := range map[string]struct {
+		key   crypto.Signer
+		pkcs8 bool
+	}{
+		"RSA PKCS#1":     {key: rsaKey},
+		"RSA PKCS#8":     {key: rsaKey, pkcs8: true},
+		"ECDSA SEC 1":    {key: ecdsaKey},
+		"ECDSA PKCS#8":   {key: ecdsaKey, pkcs8: true},
+		"Ed25519 PKCS#8": {key: ed25519Key, pkcs8: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			key, err := DecodePrivateKey(encodeKey(t, tc.key, tc.pkcs8))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !publicKeysEqual(key.Public(), tc.key.Public()) {
+				t.Error("expected the encoded key")
+			}
+		})
+	}
+
+	for name, data := range map[string][]byte{
+		"empty":       nil,
+		"not PEM":     []byte("key"),
+		"invalid DER": pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte("key")}),
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := DecodePrivateKey(data); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
@@ -27,6 +27,10 @@ const (
 	controllerImage = "controller"
 	routerImage     = "router"
 	natsImage       = "nats"
+
+	// Database clients of the Job dropping an external database on teardown
+	postgresClientImage = "postgres:16-alpine"
+	mysqlClientImage    = "mysql:8.4"
 )
 
 func GetControllerImage() string {
@@ -34,3 +38,6 @@ func GetControllerImage() string {
 }
 func GetRouterImage() string { return fmt.Sprintf("%s/%s:%s", repo, routerImage, routerTag) }
 func GetNatsImage() string   { return fmt.Sprintf("%s/%s:%s", repo, natsImage, natsTag) }
+
+func GetPostgresClientImage() string { return postgresClientImage }
+func GetMysqlClientImage() string    { return mysqlClientImage }
@@ -30,6 +30,44 @@ import (
 // UpdateECNViewerClientRootURL updates the root URL for the ecnviewerclient
 // using the controller client secret to obtain an admin token via OAuth2
 func UpdateECNViewerClientRootURL(auth cpv3.Auth, newRootURL string) error {
+	if newRootURL == "" {
+		return fmt.Errorf("new root URL is required")
+	}
+
+	return setECNViewerClientRootURL(auth, newRootURL)
+}
+
+// ResetECNViewerClientRootURL clears the root URL of the ecnviewerclient when its ECN Viewer is removed
+func ResetECNViewerClientRootURL(auth cpv3.Auth) error {
+	return setECNViewerClientRootURL(auth, "")
+}
+
+// CheckAuthReachable requests the OpenID configuration of the realm to verify Keycloak serves it
+func CheckAuthReachable(auth cpv3.Auth) error {
+	if auth.URL == "" {
+		return fmt.Errorf("auth URL is required")
+	}
+	if auth.Realm == "" {
+		return fmt.Errorf("auth realm is required")
+	}
+
+	configURL := fmt.Sprintf("%s/realms/%s/.well-known/openid-configuration", auth.URL, auth.Realm)
+
+	httpClient := &http.Client{Timeout: 10 * time.Second}
+	resp, err := httpClient.Get(configURL)
+	if err != nil {
+		return fmt.Errorf("failed to get OpenID configuration: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("OpenID configuration request failed with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+func setECNViewerClientRootURL(auth cpv3.Auth, newRootURL string) error {
 	// Validate input parameters
 	if auth.URL == "" {
 		return fmt.Errorf("auth URL is required")
@@ -46,9 +84,6 @@ func UpdateECNViewerClientRootURL(auth cpv3.Auth, newRootURL string) error {
 	if auth.ViewerClient == "" {
 		return fmt.Errorf("viewer client ID is required")
 	}
-	if newRootURL == "" {
-		return fmt.Errorf("new root URL is required")
-	}
 
 	// Configure OAuth2 client credentials
 	tokenURL := fmt.Sprintf("%s/realms/%s/protocol/openid-connect/token", auth.URL, auth.Realm)
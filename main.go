@@ -22,6 +22,7 @@ import (
 
 	appsv3 "github.com/datasance/iofog-operator/v3/apis/apps/v3"
 	cpv3 "github.com/datasance/iofog-operator/v3/apis/controlplanes/v3"
+	cpv4 "github.com/datasance/iofog-operator/v3/apis/controlplanes/v4"
 	appscontroller "github.com/datasance/iofog-operator/v3/controllers/apps"
 	controlplanescontroller "github.com/datasance/iofog-operator/v3/controllers/controlplanes"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -33,6 +34,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -43,6 +45,7 @@ func init() { //nolint:gochecknoinits
 
 	utilruntime.Must(appsv3.AddToScheme(scheme))
 	utilruntime.Must(cpv3.AddToScheme(scheme))
+	utilruntime.Must(cpv4.AddToScheme(scheme))
 	// +kubebuilder:scaffold:scheme
 } //nolint:wsl
 
@@ -81,7 +84,7 @@ func main() {
 				getWatchNamespace(): {},
 			},
 		},
-		// WebhookServer:    wb.NewServer(webhook.Options{Port: 9443}),
+		WebhookServer: webhook.NewServer(webhook.Options{Port: 9443}), //nolint:gomnd
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
@@ -89,22 +92,32 @@ func main() {
 	}
 
 	if err = (&appscontroller.ApplicationReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("Application"),
-		Scheme: mgr.GetScheme(),
+		Client:   mgr.GetClient(),
+		Log:      ctrl.Log.WithName("controllers").WithName("Application"),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("application-controller"),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Application")
 		os.Exit(1)
 	}
 
 	if err = (&controlplanescontroller.ControlPlaneReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("ControlPlane"),
-		Scheme: mgr.GetScheme(),
+		Client:    mgr.GetClient(),
+		APIReader: mgr.GetAPIReader(),
+		Log:       ctrl.Log.WithName("controllers").WithName("ControlPlane"),
+		Scheme:    mgr.GetScheme(),
+		Recorder:  mgr.GetEventRecorderFor("controlplane-controller"),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "ControlPlane")
 		os.Exit(1)
 	}
+	// Webhooks need serving certificates, disable them when running the operator outside the cluster
+	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		if err = (&cpv3.ControlPlane{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "ControlPlane")
+			os.Exit(1)
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
 	setupLog.Info("starting manager")